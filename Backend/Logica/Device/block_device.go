@@ -0,0 +1,99 @@
+package Device
+
+import (
+	"errors"
+	"io"
+)
+
+// BlockDevice es el almacenamiento de un disco virtual. Todos los managers leen y
+// escriben los discos a través de esta interfaz, sin importar si viven en un
+// archivo .mia o en memoria.
+type BlockDevice interface {
+	ReadAt(p []byte, off int64) (int, error)
+	WriteAt(p []byte, off int64) (int, error)
+	Sync() error
+	Size() int64
+}
+
+// ErrReadOnly se retorna al escribir sobre un Handle abierto solo para lectura
+var ErrReadOnly = errors.New("dispositivo abierto en modo solo lectura")
+
+// Handle es un dispositivo abierto con posición actual, para que el código que usa
+// Seek + binary.Read/Write funcione igual que con un *os.File
+type Handle struct {
+	device   BlockDevice
+	offset   int64
+	writable bool
+	closer   io.Closer
+}
+
+// newHandle envuelve el dispositivo; closer puede ser nil si no hay nada que liberar
+func newHandle(device BlockDevice, writable bool, closer io.Closer) *Handle {
+	return &Handle{device: device, writable: writable, closer: closer}
+}
+
+// Device retorna el dispositivo subyacente
+func (h *Handle) Device() BlockDevice {
+	return h.device
+}
+
+func (h *Handle) Read(p []byte) (int, error) {
+	if h.offset >= h.device.Size() {
+		return 0, io.EOF
+	}
+	n, err := h.device.ReadAt(p, h.offset)
+	h.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (h *Handle) Write(p []byte) (int, error) {
+	n, err := h.WriteAt(p, h.offset)
+	h.offset += int64(n)
+	return n, err
+}
+
+func (h *Handle) ReadAt(p []byte, off int64) (int, error) {
+	return h.device.ReadAt(p, off)
+}
+
+func (h *Handle) WriteAt(p []byte, off int64) (int, error) {
+	if !h.writable {
+		return 0, ErrReadOnly
+	}
+	return h.device.WriteAt(p, off)
+}
+
+func (h *Handle) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += h.offset
+	case io.SeekEnd:
+		offset += h.device.Size()
+	default:
+		return 0, errors.New("whence invalido")
+	}
+	if offset < 0 {
+		return 0, errors.New("posición negativa")
+	}
+	h.offset = offset
+	return offset, nil
+}
+
+func (h *Handle) Sync() error {
+	return h.device.Sync()
+}
+
+func (h *Handle) Size() int64 {
+	return h.device.Size()
+}
+
+func (h *Handle) Close() error {
+	if h.closer == nil {
+		return nil
+	}
+	return h.closer.Close()
+}
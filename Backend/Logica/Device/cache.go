@@ -0,0 +1,447 @@
+package Device
+
+import (
+	"container/list"
+	"io"
+	"sync"
+)
+
+// Parámetros de la caché de cada partición montada
+const (
+	CachePageSize          = 1024 // Bytes por página
+	CachePagesPerPartition = 4096 // Páginas máximas antes de desalojar (LRU)
+)
+
+// CacheStats resume el uso de la caché de una partición
+type CacheStats struct {
+	MountID    string `json:"mountId"`
+	Pages      int    `json:"pages"`
+	DirtyPages int    `json:"dirtyPages"`
+	Hits       int64  `json:"hits"`
+	Misses     int64  `json:"misses"`
+	Written    int64  `json:"written"`
+}
+
+// cachePage es una página de la partición en memoria
+type cachePage struct {
+	index int64
+	data  []byte
+	dirty bool
+}
+
+// partitionCache guarda las páginas de una partición montada. Cubre superbloque,
+// bitmaps, inodos y bloques porque todos viven dentro del rango de la partición.
+type partitionCache struct {
+	mountID string
+	start   int64
+	size    int64
+	pages   map[int64]*list.Element
+	lru     *list.List
+	stats   CacheStats
+}
+
+// CachedDevice pone una caché write-back por partición sobre el dispositivo de un
+// disco. Lo que queda fuera de las particiones montadas (MBR, EBR) pasa directo.
+type CachedDevice struct {
+	mu      sync.Mutex
+	base    BlockDevice
+	closer  io.Closer
+	size    int64
+	regions []*partitionCache
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   = make(map[string]*CachedDevice)
+)
+
+// EnableCache activa la caché para la partición montada en [start, start+size).
+// Si el rango se solapa con otra partición en caché (extendida y sus lógicas) no se cachea.
+func EnableCache(path string, mountID string, start int64, size int64) error {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	cached, ok := caches[path]
+	if !ok {
+		base, closer, err := openBase(path)
+		if err != nil {
+			return err
+		}
+		cached = &CachedDevice{base: base, closer: closer, size: base.Size()}
+		caches[path] = cached
+	}
+
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+	for _, region := range cached.regions {
+		if start < region.start+region.size && region.start < start+size {
+			return nil
+		}
+	}
+	cached.regions = append(cached.regions, &partitionCache{
+		mountID: mountID,
+		start:   start,
+		size:    size,
+		pages:   make(map[int64]*list.Element),
+		lru:     list.New(),
+		stats:   CacheStats{MountID: mountID},
+	})
+	return nil
+}
+
+// FlushCache escribe al disco las páginas modificadas de la partición y retorna cuántas escribió
+func FlushCache(mountID string) (int, CacheStats, error) {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	for _, cached := range caches {
+		cached.mu.Lock()
+		region := cached.region(mountID)
+		if region == nil {
+			cached.mu.Unlock()
+			continue
+		}
+		dirty := region.currentStats().DirtyPages
+		err := cached.flush(region)
+		stats := region.currentStats()
+		cached.mu.Unlock()
+		return dirty - stats.DirtyPages, stats, err
+	}
+	return 0, CacheStats{MountID: mountID}, nil
+}
+
+// DisableCache escribe las páginas modificadas y libera la caché de la partición.
+// Al quitar la última partición del disco se cierra el dispositivo.
+func DisableCache(mountID string) error {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	for path, cached := range caches {
+		cached.mu.Lock()
+		region := cached.region(mountID)
+		if region == nil {
+			cached.mu.Unlock()
+			continue
+		}
+		err := cached.flush(region)
+		for i, r := range cached.regions {
+			if r == region {
+				cached.regions = append(cached.regions[:i], cached.regions[i+1:]...)
+				break
+			}
+		}
+		empty := len(cached.regions) == 0
+		cached.mu.Unlock()
+
+		if empty {
+			delete(caches, path)
+			cached.close()
+		}
+		return err
+	}
+	return nil
+}
+
+// FlushAll escribe las páginas modificadas de todas las particiones (al salir del programa)
+func FlushAll() error {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	var firstErr error
+	for _, cached := range caches {
+		cached.mu.Lock()
+		for _, region := range cached.regions {
+			if err := cached.flush(region); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		cached.mu.Unlock()
+	}
+	return firstErr
+}
+
+// WriteThrough escribe p directo en el dispositivo real y lo sincroniza, sin pasar por
+// transacciones. Las páginas en caché que cubren el rango se actualizan sin marcarse
+// modificadas. Lo usa el registro de intenciones de EXT3, que debe estar en el disco
+// antes de que se escriba lo que describe.
+func WriteThrough(path string, p []byte, off int64) error {
+	if cached, ok := cachedDevice(path); ok {
+		return cached.writeThrough(p, off)
+	}
+
+	handle, err := openDevice(path, true)
+	if err != nil {
+		return err
+	}
+	defer handle.Close()
+	if _, err := handle.WriteAt(p, off); err != nil {
+		return err
+	}
+	return handle.Sync()
+}
+
+// cachedDevice retorna la caché activa del disco
+func cachedDevice(path string) (*CachedDevice, bool) {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	cached, ok := caches[path]
+	return cached, ok
+}
+
+// dropCache descarta la caché del disco sin escribirla (rmdisk o mkdisk sobre la misma ruta)
+func dropCache(path string) {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	if cached, ok := caches[path]; ok {
+		delete(caches, path)
+		cached.close()
+	}
+}
+
+// openBase abre el dispositivo real del disco que quedará debajo de la caché
+func openBase(path string) (BlockDevice, io.Closer, error) {
+	handle, err := openDevice(path, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return handle.device, handle.closer, nil
+}
+
+func (c *CachedDevice) ReadAt(p []byte, off int64) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= c.size {
+			return n, io.EOF
+		}
+
+		region := c.regionAt(pos)
+		if region == nil {
+			end := c.passthroughEnd(pos, off+int64(len(p)))
+			read, err := c.base.ReadAt(p[n:n+int(end-pos)], pos)
+			n += read
+			if err != nil {
+				return n, err
+			}
+			continue
+		}
+
+		page, pageStart, err := c.page(region, pos)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], page.data[pos-pageStart:])
+		if copied == 0 {
+			return n, io.EOF
+		}
+		n += copied
+	}
+	return n, nil
+}
+
+func (c *CachedDevice) WriteAt(p []byte, off int64) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+
+		region := c.regionAt(pos)
+		if region == nil || pos >= c.size {
+			end := c.passthroughEnd(pos, off+int64(len(p)))
+			written, err := c.base.WriteAt(p[n:n+int(end-pos)], pos)
+			n += written
+			if pos+int64(written) > c.size {
+				c.size = pos + int64(written)
+			}
+			if err != nil {
+				return n, err
+			}
+			continue
+		}
+
+		page, pageStart, err := c.page(region, pos)
+		if err != nil {
+			return n, err
+		}
+		copied := copy(page.data[pos-pageStart:], p[n:])
+		if copied == 0 {
+			return n, io.ErrShortWrite
+		}
+		page.dirty = true
+		n += copied
+	}
+	return n, nil
+}
+
+// writeThrough escribe en el dispositivo real y copia los datos a las páginas en caché del rango
+func (c *CachedDevice) writeThrough(p []byte, off int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.base.WriteAt(p, off); err != nil {
+		return err
+	}
+	if off+int64(len(p)) > c.size {
+		c.size = off + int64(len(p))
+	}
+
+	end := off + int64(len(p))
+	for _, region := range c.regions {
+		if off >= region.start+region.size || end <= region.start {
+			continue
+		}
+		first := (max(off, region.start) - region.start) / CachePageSize
+		last := (min(end, region.start+region.size) - 1 - region.start) / CachePageSize
+		for index := first; index <= last; index++ {
+			element, ok := region.pages[index]
+			if !ok {
+				continue
+			}
+			page := element.Value.(*cachePage)
+			pageStart := region.start + index*CachePageSize
+			lo := max(off, pageStart)
+			hi := min(end, pageStart+int64(len(page.data)))
+			if lo < hi {
+				copy(page.data[lo-pageStart:hi-pageStart], p[lo-off:hi-off])
+			}
+		}
+	}
+	return c.base.Sync()
+}
+
+// Sync escribe todas las páginas modificadas del disco
+func (c *CachedDevice) Sync() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, region := range c.regions {
+		if err := c.flush(region); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *CachedDevice) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *CachedDevice) close() {
+	if c.closer != nil {
+		c.closer.Close()
+	}
+}
+
+// region busca la caché de una partición por ID de montaje
+func (c *CachedDevice) region(mountID string) *partitionCache {
+	for _, region := range c.regions {
+		if region.mountID == mountID {
+			return region
+		}
+	}
+	return nil
+}
+
+// regionAt retorna la partición en caché que contiene la posición
+func (c *CachedDevice) regionAt(pos int64) *partitionCache {
+	for _, region := range c.regions {
+		if pos >= region.start && pos < region.start+region.size {
+			return region
+		}
+	}
+	return nil
+}
+
+// passthroughEnd calcula hasta dónde se puede leer o escribir directo sin entrar a una partición en caché
+func (c *CachedDevice) passthroughEnd(pos int64, end int64) int64 {
+	for _, region := range c.regions {
+		if region.start > pos && region.start < end {
+			end = region.start
+		}
+	}
+	return end
+}
+
+// page retorna la página que contiene pos, leyéndola del disco si no está en caché.
+// Al superar la capacidad se desaloja la menos usada, escribiéndola si estaba modificada.
+func (c *CachedDevice) page(region *partitionCache, pos int64) (*cachePage, int64, error) {
+	index := (pos - region.start) / CachePageSize
+	pageStart := region.start + index*CachePageSize
+
+	if element, ok := region.pages[index]; ok {
+		region.lru.MoveToFront(element)
+		region.stats.Hits++
+		return element.Value.(*cachePage), pageStart, nil
+	}
+	region.stats.Misses++
+
+	length := int64(CachePageSize)
+	if pageStart+length > region.start+region.size {
+		length = region.start + region.size - pageStart
+	}
+	if pageStart+length > c.size {
+		length = c.size - pageStart
+	}
+
+	page := &cachePage{index: index, data: make([]byte, length)}
+	if _, err := c.base.ReadAt(page.data, pageStart); err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+	region.pages[index] = region.lru.PushFront(page)
+
+	if region.lru.Len() > CachePagesPerPartition {
+		oldest := region.lru.Back()
+		evicted := oldest.Value.(*cachePage)
+		if evicted.dirty {
+			if err := c.writePage(region, evicted); err != nil {
+				return nil, 0, err
+			}
+		}
+		region.lru.Remove(oldest)
+		delete(region.pages, evicted.index)
+	}
+
+	return page, pageStart, nil
+}
+
+// writePage escribe una página modificada en el dispositivo real
+func (c *CachedDevice) writePage(region *partitionCache, page *cachePage) error {
+	if _, err := c.base.WriteAt(page.data, region.start+page.index*CachePageSize); err != nil {
+		return err
+	}
+	page.dirty = false
+	region.stats.Written++
+	return nil
+}
+
+// flush escribe las páginas modificadas de la partición y sincroniza el dispositivo
+func (c *CachedDevice) flush(region *partitionCache) error {
+	for element := region.lru.Front(); element != nil; element = element.Next() {
+		page := element.Value.(*cachePage)
+		if page.dirty {
+			if err := c.writePage(region, page); err != nil {
+				return err
+			}
+		}
+	}
+	return c.base.Sync()
+}
+
+// currentStats retorna las estadísticas con el número actual de páginas
+func (r *partitionCache) currentStats() CacheStats {
+	stats := r.stats
+	stats.Pages = r.lru.Len()
+	for element := r.lru.Front(); element != nil; element = element.Next() {
+		if element.Value.(*cachePage).dirty {
+			stats.DirtyPages++
+		}
+	}
+	return stats
+}
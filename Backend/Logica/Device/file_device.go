@@ -0,0 +1,39 @@
+package Device
+
+import (
+	"os"
+)
+
+// FileDevice guarda el disco en un archivo del sistema operativo (los .mia)
+type FileDevice struct {
+	file *os.File
+}
+
+// NewFileDevice envuelve un archivo ya abierto
+func NewFileDevice(file *os.File) *FileDevice {
+	return &FileDevice{file: file}
+}
+
+func (d *FileDevice) ReadAt(p []byte, off int64) (int, error) {
+	return d.file.ReadAt(p, off)
+}
+
+func (d *FileDevice) WriteAt(p []byte, off int64) (int, error) {
+	return d.file.WriteAt(p, off)
+}
+
+func (d *FileDevice) Sync() error {
+	return d.file.Sync()
+}
+
+func (d *FileDevice) Size() int64 {
+	info, err := d.file.Stat()
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func (d *FileDevice) Close() error {
+	return d.file.Close()
+}
@@ -0,0 +1,63 @@
+package Device
+
+import (
+	"io"
+	"sync"
+)
+
+// MemoryDevice guarda el disco completo en memoria. Sirve para pruebas y para
+// usar el motor desde otros servicios sin archivos temporales.
+type MemoryDevice struct {
+	mu   sync.RWMutex
+	data []byte
+}
+
+// NewMemoryDevice crea un disco en memoria de size bytes en cero
+func NewMemoryDevice(size int64) *MemoryDevice {
+	return &MemoryDevice{data: make([]byte, size)}
+}
+
+func (d *MemoryDevice) ReadAt(p []byte, off int64) (int, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if off >= int64(len(d.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, d.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt crece el disco si se escribe más allá del final, igual que un archivo
+func (d *MemoryDevice) WriteAt(p []byte, off int64) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	end := off + int64(len(p))
+	if end > int64(len(d.data)) {
+		grown := make([]byte, end)
+		copy(grown, d.data)
+		d.data = grown
+	}
+	return copy(d.data[off:], p), nil
+}
+
+func (d *MemoryDevice) Sync() error {
+	return nil
+}
+
+func (d *MemoryDevice) Size() int64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return int64(len(d.data))
+}
+
+// Bytes retorna una copia del contenido del disco
+func (d *MemoryDevice) Bytes() []byte {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]byte(nil), d.data...)
+}
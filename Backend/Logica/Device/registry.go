@@ -0,0 +1,138 @@
+package Device
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// MemoryPrefix identifica las rutas de discos en memoria (ej: mem://pruebas/d1.mia).
+// mkdisk con una de estas rutas crea un MemoryDevice en lugar de un archivo.
+const MemoryPrefix = "mem://"
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]BlockDevice)
+)
+
+// Register asocia un dispositivo a una ruta de disco. Mientras esté registrado,
+// todos los comandos que reciban esa ruta usan el dispositivo y no el archivo.
+func Register(path string, device BlockDevice) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[path] = device
+}
+
+// Unregister quita el dispositivo asociado a la ruta
+func Unregister(path string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, path)
+}
+
+// Lookup retorna el dispositivo registrado para la ruta
+func Lookup(path string) (BlockDevice, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	device, ok := registry[path]
+	return device, ok
+}
+
+// IsMemoryPath indica si la ruta corresponde a un disco en memoria
+func IsMemoryPath(path string) bool {
+	return strings.HasPrefix(path, MemoryPrefix)
+}
+
+// Open abre el disco solo para lectura
+func Open(path string) (*Handle, error) {
+	return open(path, false)
+}
+
+// OpenWrite abre el disco para lectura y escritura
+func OpenWrite(path string) (*Handle, error) {
+	return open(path, true)
+}
+
+// open usa la caché si la ruta tiene particiones montadas; si no, el dispositivo real.
+// Con transacciones activas en el disco el Handle pasa además por ellas.
+func open(path string, writable bool) (*Handle, error) {
+	var handle *Handle
+	if cached, ok := cachedDevice(path); ok {
+		handle = newHandle(cached, writable, nil)
+	} else {
+		var err error
+		handle, err = openDevice(path, writable)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(activeTransactions(path)) > 0 {
+		handle.device = &transactionDevice{base: handle.device, path: path}
+	}
+	return handle, nil
+}
+
+// openDevice abre el dispositivo registrado o el archivo, sin pasar por la caché
+func openDevice(path string, writable bool) (*Handle, error) {
+	if device, ok := Lookup(path); ok {
+		return newHandle(device, writable, nil), nil
+	}
+	if IsMemoryPath(path) {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+
+	flag := os.O_RDONLY
+	if writable {
+		flag = os.O_RDWR
+	}
+	file, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	device := NewFileDevice(file)
+	return newHandle(device, writable, device), nil
+}
+
+// Create crea un disco vacío y lo abre para escritura. Las rutas mem:// y las ya
+// registradas se (re)crean como MemoryDevice; las demás como archivo.
+func Create(path string) (*Handle, error) {
+	dropCache(path)
+	if _, ok := Lookup(path); ok || IsMemoryPath(path) {
+		device := NewMemoryDevice(0)
+		Register(path, device)
+		return newHandle(device, true, nil), nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	device := NewFileDevice(file)
+	return newHandle(device, true, device), nil
+}
+
+// Exists indica si el disco existe, ya sea registrado o como archivo
+func Exists(path string) bool {
+	if _, ok := Lookup(path); ok {
+		return true
+	}
+	if IsMemoryPath(path) {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Remove elimina el disco: quita el registro o borra el archivo
+func Remove(path string) error {
+	dropCache(path)
+	if _, ok := Lookup(path); ok {
+		Unregister(path)
+		return nil
+	}
+	if IsMemoryPath(path) {
+		return &os.PathError{Op: "remove", Path: path, Err: os.ErrNotExist}
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,243 @@
+package Device
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// TransactionSectorSize es la unidad en la que una transacción guarda lo modificado
+const TransactionSectorSize = 64
+
+// Transaction retiene en memoria las escrituras a un rango del disco (una partición)
+// hasta que quien la inició decide qué hacer con ellas. Mientras está activa, todos los
+// Handle del disco escriben ese rango en la transacción y leen lo que ya escribió; el
+// resto del disco pasa directo. End la desactiva sin escribir nada: Images retorna lo
+// modificado para que el llamador lo registre y lo aplique, o lo descarte.
+type Transaction struct {
+	mu      sync.Mutex
+	path    string
+	start   int64
+	size    int64
+	sectors map[int64][]byte // Sector relativo al inicio del rango -> contenido
+}
+
+// Image es un rango contiguo modificado por una transacción; Offset es absoluto en el disco
+type Image struct {
+	Offset int64
+	Data   []byte
+}
+
+var (
+	transactionsMu sync.RWMutex
+	transactions   = make(map[string][]*Transaction)
+)
+
+// BeginTransaction activa una transacción sobre [start, start+size) del disco.
+// Falla si ya hay otra activa que se solape con el rango.
+func BeginTransaction(path string, start int64, size int64) (*Transaction, error) {
+	transactionsMu.Lock()
+	defer transactionsMu.Unlock()
+
+	for _, t := range transactions[path] {
+		if start < t.start+t.size && t.start < start+size {
+			return nil, fmt.Errorf("ya hay una transacción activa en la partición")
+		}
+	}
+
+	t := &Transaction{path: path, start: start, size: size, sectors: make(map[int64][]byte)}
+	transactions[path] = append(transactions[path], t)
+	return t, nil
+}
+
+// End desactiva la transacción; lo escrito queda disponible en Images
+func (t *Transaction) End() {
+	transactionsMu.Lock()
+	defer transactionsMu.Unlock()
+
+	active := transactions[t.path]
+	for i, other := range active {
+		if other == t {
+			active = append(active[:i], active[i+1:]...)
+			break
+		}
+	}
+	if len(active) == 0 {
+		delete(transactions, t.path)
+	} else {
+		transactions[t.path] = active
+	}
+}
+
+// Images retorna los sectores modificados, unidos en rangos contiguos y ordenados
+func (t *Transaction) Images() []Image {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	indexes := make([]int64, 0, len(t.sectors))
+	for index := range t.sectors {
+		indexes = append(indexes, index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+
+	var images []Image
+	for i, index := range indexes {
+		data := t.sectors[index]
+		if i > 0 && indexes[i-1] == index-1 {
+			last := &images[len(images)-1]
+			last.Data = append(last.Data, data...)
+			continue
+		}
+		images = append(images, Image{
+			Offset: t.start + index*TransactionSectorSize,
+			Data:   append([]byte(nil), data...),
+		})
+	}
+	return images
+}
+
+// sectorBounds retorna la posición absoluta y el largo del sector (el último puede ser más corto)
+func (t *Transaction) sectorBounds(index int64) (int64, int64) {
+	sectorStart := t.start + index*TransactionSectorSize
+	length := int64(TransactionSectorSize)
+	if sectorStart+length > t.start+t.size {
+		length = t.start + t.size - sectorStart
+	}
+	return sectorStart, length
+}
+
+// write guarda p (que cae completo dentro del rango) en los sectores de la transacción.
+// Un sector que no cambia respecto al disco no se guarda.
+func (t *Transaction) write(base BlockDevice, p []byte, off int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		index := (pos - t.start) / TransactionSectorSize
+		sectorStart, length := t.sectorBounds(index)
+
+		sector, ok := t.sectors[index]
+		var original []byte
+		if !ok {
+			original = make([]byte, length)
+			if _, err := base.ReadAt(original, sectorStart); err != nil && err != io.EOF {
+				return err
+			}
+			sector = append([]byte(nil), original...)
+		}
+
+		copied := copy(sector[pos-sectorStart:], p[n:])
+		n += copied
+		if ok || !bytes.Equal(sector, original) {
+			t.sectors[index] = sector
+		}
+	}
+	return nil
+}
+
+// overlay copia sobre p (leído del disco desde off) los sectores modificados que lo cubren
+func (t *Transaction) overlay(p []byte, off int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	from := off
+	if from < t.start {
+		from = t.start
+	}
+	to := off + int64(len(p))
+	if to > t.start+t.size {
+		to = t.start + t.size
+	}
+	if from >= to || len(t.sectors) == 0 {
+		return
+	}
+
+	for index := (from - t.start) / TransactionSectorSize; t.start+index*TransactionSectorSize < to; index++ {
+		sector, ok := t.sectors[index]
+		if !ok {
+			continue
+		}
+		sectorStart, _ := t.sectorBounds(index)
+		lo, hi := sectorStart, sectorStart+int64(len(sector))
+		if lo < off {
+			lo = off
+		}
+		if hi > off+int64(len(p)) {
+			hi = off + int64(len(p))
+		}
+		copy(p[lo-off:hi-off], sector[lo-sectorStart:hi-sectorStart])
+	}
+}
+
+// activeTransactions retorna las transacciones activas del disco
+func activeTransactions(path string) []*Transaction {
+	transactionsMu.RLock()
+	defer transactionsMu.RUnlock()
+	return append([]*Transaction(nil), transactions[path]...)
+}
+
+// transactionDevice es el dispositivo que ven los Handle de un disco con transacciones activas
+type transactionDevice struct {
+	base BlockDevice
+	path string
+}
+
+func (d *transactionDevice) ReadAt(p []byte, off int64) (int, error) {
+	n, err := d.base.ReadAt(p, off)
+	for _, t := range activeTransactions(d.path) {
+		t.overlay(p[:n], off)
+	}
+	return n, err
+}
+
+func (d *transactionDevice) WriteAt(p []byte, off int64) (int, error) {
+	active := activeTransactions(d.path)
+
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		end := off + int64(len(p))
+
+		var target *Transaction
+		for _, t := range active {
+			if pos >= t.start && pos < t.start+t.size {
+				target = t
+				break
+			}
+			// Lo que queda antes de la siguiente transacción se escribe directo
+			if t.start > pos && t.start < end {
+				end = t.start
+			}
+		}
+
+		if target == nil {
+			written, err := d.base.WriteAt(p[n:n+int(end-pos)], pos)
+			n += written
+			if err != nil {
+				return n, err
+			}
+			continue
+		}
+
+		if target.start+target.size < end {
+			end = target.start + target.size
+		}
+		if err := target.write(d.base, p[n:n+int(end-pos)], pos); err != nil {
+			return n, err
+		}
+		n += int(end - pos)
+	}
+	return n, nil
+}
+
+func (d *transactionDevice) Sync() error {
+	return d.base.Sync()
+}
+
+func (d *transactionDevice) Size() int64 {
+	return d.base.Size()
+}
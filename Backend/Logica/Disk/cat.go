@@ -1,6 +1,7 @@
 package Disk
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"fmt"
 	"sort"
@@ -150,8 +151,9 @@ func readAndDisplayFile(filePath string) error {
 	if err != nil {
 		return err
 	}
+	TouchAccessTime(mountInfo.MountID, filePath)
 
-	fmt.Print(content)
+	Output.Print(content)
 	return nil
 }
 
@@ -188,8 +190,9 @@ func readAndDisplayFileWithMountID(filePath string, mountID string) error {
 	if err != nil {
 		return err
 	}
+	TouchAccessTime(mountInfo.MountID, filePath)
 
-	fmt.Print(content)
+	Output.Print(content)
 	return nil
 }
 
@@ -1,10 +1,10 @@
 package Disk
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
 	"fmt"
-	"os"
 	"path/filepath"
 )
 
@@ -26,9 +26,11 @@ type PartitionInfo struct {
 	Size      int64  `json:"size"`
 	Fit       string `json:"fit"`
 	IsMounted bool   `json:"isMounted"`
+	Options   string `json:"options,omitempty"` // Opciones de montaje (rw, ro, noatime)
 }
 
-// GetAllDisksInfo obtiene información de todos los discos creados
+// GetAllDisksInfo obtiene información de todos los discos creados.
+// Toma el bloqueo de lectura de cada disco, por lo que no debe llamarse con bloqueos tomados.
 func GetAllDisksInfo() ([]DiskInfo, error) {
 	var disksInfo []DiskInfo
 	processedPaths := make(map[string]bool)
@@ -44,14 +46,17 @@ func GetAllDisksInfo() ([]DiskInfo, error) {
 		}
 		processedPaths[mount.DiskPath] = true
 
-		// Leer información del disco
+		// Leer información del disco; cada disco se bloquea solo mientras se lee
+		unlock := LockDisk(mount.DiskPath, false)
 		diskInfo, err := readDiskInfo(mount.DiskPath)
 		if err != nil {
+			unlock()
 			continue // Ignorar discos con errores
 		}
 
 		// Obtener todas las particiones del disco (montadas y no montadas)
 		allPartitions := getAllPartitionsInfo(mount.DiskPath, mountedPartitions)
+		unlock()
 
 		// Contar particiones montadas
 		mountedCount := 0
@@ -71,7 +76,7 @@ func GetAllDisksInfo() ([]DiskInfo, error) {
 
 // readDiskInfo lee información básica del MBR del disco
 func readDiskInfo(diskPath string) (DiskInfo, error) {
-	file, err := os.Open(diskPath)
+	file, err := Device.Open(diskPath)
 	if err != nil {
 		return DiskInfo{}, err
 	}
@@ -83,12 +88,6 @@ func readDiskInfo(diskPath string) (DiskInfo, error) {
 		return DiskInfo{}, err
 	}
 
-	// Obtener información del archivo
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return DiskInfo{}, err
-	}
-
 	// Convertir fit a string legible
 	fitStr := "WF" // Default
 	switch mbr.DiskFit {
@@ -103,7 +102,7 @@ func readDiskInfo(diskPath string) (DiskInfo, error) {
 	return DiskInfo{
 		Name:              filepath.Base(diskPath),
 		Path:              diskPath,
-		Size:              fileInfo.Size(),
+		Size:              file.Size(),
 		Fit:               fitStr,
 		MountedPartitions: 0, // Se actualizará después
 		Partitions:        []PartitionInfo{},
@@ -112,7 +111,7 @@ func readDiskInfo(diskPath string) (DiskInfo, error) {
 
 // getPartitionSize obtiene el tamaño de una partición específica
 func getPartitionSize(diskPath string, partitionName string) int64 {
-	file, err := os.Open(diskPath)
+	file, err := Device.Open(diskPath)
 	if err != nil {
 		return 0
 	}
@@ -141,20 +140,14 @@ func GetDiskInfoByPath(diskPath string) (DiskInfo, error) {
 		return DiskInfo{}, fmt.Errorf("error leyendo disco: %v", err)
 	}
 
+	// Obtener todas las particiones del disco (montadas y no montadas)
+	partitions := getAllPartitionsInfo(diskPath, GetMountedPartitions())
+
 	// Contar particiones montadas
-	mountedPartitions := GetMountedPartitions()
 	mountedCount := 0
-	var partitions []PartitionInfo
-
-	for _, mount := range mountedPartitions {
-		if mount.DiskPath == diskPath {
+	for _, p := range partitions {
+		if p.IsMounted {
 			mountedCount++
-			partitions = append(partitions, PartitionInfo{
-				Name: mount.PartitionName,
-				ID:   mount.MountID,
-				Type: "Primary/Logical",
-				Size: getPartitionSize(diskPath, mount.PartitionName),
-			})
 		}
 	}
 
@@ -166,7 +159,7 @@ func GetDiskInfoByPath(diskPath string) (DiskInfo, error) {
 
 // getAllPartitionsInfo obtiene información de todas las particiones de un disco (montadas y no montadas)
 func getAllPartitionsInfo(diskPath string, mountedPartitions []MountInfo) []PartitionInfo {
-	file, err := os.Open(diskPath)
+	file, err := Device.Open(diskPath)
 	if err != nil {
 		return []PartitionInfo{}
 	}
@@ -221,8 +214,10 @@ func getAllPartitionsInfo(diskPath string, mountedPartitions []MountInfo) []Part
 		// Verificar si está montada
 		mountInfo, isMounted := mountedMap[partName]
 		mountID := ""
+		options := ""
 		if isMounted {
 			mountID = mountInfo.MountID
+			options = mountInfo.Options.String()
 		}
 
 		partitions = append(partitions, PartitionInfo{
@@ -232,6 +227,7 @@ func getAllPartitionsInfo(diskPath string, mountedPartitions []MountInfo) []Part
 			Size:      partition.PartSize,
 			Fit:       fitStr,
 			IsMounted: isMounted,
+			Options:   options,
 		})
 
 		// Si es extendida, procesar particiones lógicas (EBR)
@@ -245,7 +241,7 @@ func getAllPartitionsInfo(diskPath string, mountedPartitions []MountInfo) []Part
 }
 
 // getLogicalPartitionsInfo obtiene información de particiones lógicas desde EBRs
-func getLogicalPartitionsInfo(file *os.File, extendedStart int64, diskPath string, mountedMap map[string]MountInfo) []PartitionInfo {
+func getLogicalPartitionsInfo(file *Device.Handle, extendedStart int64, diskPath string, mountedMap map[string]MountInfo) []PartitionInfo {
 	var logicalPartitions []PartitionInfo
 	currentEBRPos := extendedStart
 
@@ -274,8 +270,10 @@ func getLogicalPartitionsInfo(file *os.File, extendedStart int64, diskPath strin
 			// Verificar si está montada
 			mountInfo, isMounted := mountedMap[partName]
 			mountID := ""
+			options := ""
 			if isMounted {
 				mountID = mountInfo.MountID
+				options = mountInfo.Options.String()
 			}
 
 			logicalPartitions = append(logicalPartitions, PartitionInfo{
@@ -285,6 +283,7 @@ func getLogicalPartitionsInfo(file *os.File, extendedStart int64, diskPath strin
 				Size:      ebr.PartS,
 				Fit:       fitStr,
 				IsMounted: isMounted,
+				Options:   options,
 			})
 		}
 
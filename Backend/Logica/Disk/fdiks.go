@@ -1,12 +1,13 @@
 package Disk
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Partition"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 )
 
@@ -70,11 +71,11 @@ func Fdisk(size int64, unit string, fit string, path string, ptype string, name
 		return fmt.Errorf("tipo inválido")
 	}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	if !Device.Exists(path) {
 		return fmt.Errorf("archivo no existe")
 	}
 
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(path)
 	if err != nil {
 		return fmt.Errorf("error abriendo disco")
 	}
@@ -213,7 +214,7 @@ func deleteFdisk(path string, name string, deleteMode string) error {
 		return fmt.Errorf("modo de eliminación inválido: use FAST o FULL")
 	}
 
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(path)
 	if err != nil {
 		return err
 	}
@@ -270,13 +271,13 @@ func deleteFdisk(path string, name string, deleteMode string) error {
 	binary.Write(file, binary.LittleEndian, &mbr)
 
 	// Mensaje de éxito
-	fmt.Printf("Partición '%s' eliminada exitosamente\n", name)
+	Output.Printf("Partición '%s' eliminada exitosamente\n", name)
 
 	return nil
 }
 
 // deleteLogicalPartitions elimina todas las particiones lógicas dentro de una extendida
-func deleteLogicalPartitions(file *os.File, extended *Models.Partition, deleteMode string) {
+func deleteLogicalPartitions(file *Device.Handle, extended *Models.Partition, deleteMode string) {
 	if deleteMode == "FULL" {
 		zeros := make([]byte, extended.PartSize)
 		file.Seek(extended.PartStart, 0)
@@ -301,7 +302,7 @@ func resizeFdisk(path string, name string, add int64, unit string) error {
 		add *= 1024 * 1024
 	}
 
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(path)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,105 @@
+package Disk
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"fmt"
+	"sync"
+)
+
+// Bloqueos de lectura/escritura por disco y por partición.
+//
+// Los comandos que cambian la estructura del disco (mkdisk, rmdisk, fdisk, mount,
+// unmount) toman el bloqueo de escritura del disco. Las operaciones sobre un sistema
+// de archivos toman el bloqueo de lectura del disco y después el de la partición, de
+// lectura para consultas y de escritura para cambios. El orden es siempre disco y luego
+// partición, y cada operación toma un solo bloqueo de cada tipo: sync.RWMutex no es
+// reentrante, por eso los bloqueos se toman en los puntos de entrada (consola y
+// servidor HTTP) y no dentro de los comandos.
+
+// partitionLockKey identifica una partición por disco y nombre, que no cambian al remontar
+type partitionLockKey struct {
+	diskPath string
+	name     string
+}
+
+var (
+	locksMutex     sync.Mutex
+	diskLocks      = make(map[string]*sync.RWMutex)
+	partitionLocks = make(map[partitionLockKey]*sync.RWMutex)
+)
+
+// LockDisk toma el bloqueo del disco (de escritura si write) y retorna la función que lo libera
+func LockDisk(path string, write bool) func() {
+	lock := diskLock(path)
+	if write {
+		lock.Lock()
+		return lock.Unlock
+	}
+	lock.RLock()
+	return lock.RUnlock
+}
+
+// LockPartition toma el bloqueo de lectura del disco y el de la partición montada con el ID
+// indicado (de escritura si write). Retorna la función que libera ambos. Con write marca
+// además la partición como sucia, porque quien lo toma va a escribir en ella; si la
+// partición se montó con -ro retorna ErrReadOnly sin bloquear nada. Todo lo que modifica
+// una partición toma este bloqueo, así que -ro se aplica aquí y no en cada comando.
+func LockPartition(mountID string, write bool) (func(), error) {
+	mountInfo, err := GetMountInfoByID(mountID)
+	if err != nil {
+		return nil, err
+	}
+	if write && mountInfo.Options.ReadOnly {
+		return nil, fmt.Errorf("%w: %s", ErrReadOnly, mountID)
+	}
+
+	unlockDisk := LockDisk(mountInfo.DiskPath, false)
+	lock := partitionLock(mountInfo.DiskPath, mountInfo.PartitionName)
+	if write {
+		lock.Lock()
+		System.MarkSuperBlockDirty(systemMountInfo(mountInfo))
+		return func() {
+			lock.Unlock()
+			unlockDisk()
+		}, nil
+	}
+	lock.RLock()
+	return func() {
+		lock.RUnlock()
+		unlockDisk()
+	}, nil
+}
+
+// LockMountedDisk toma el bloqueo de escritura del disco que contiene la partición montada
+func LockMountedDisk(mountID string) (func(), error) {
+	mountInfo, err := GetMountInfoByID(mountID)
+	if err != nil {
+		return nil, fmt.Errorf("ID no encontrado")
+	}
+	return LockDisk(mountInfo.DiskPath, true), nil
+}
+
+func diskLock(path string) *sync.RWMutex {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+
+	lock, ok := diskLocks[path]
+	if !ok {
+		lock = &sync.RWMutex{}
+		diskLocks[path] = lock
+	}
+	return lock
+}
+
+func partitionLock(path string, name string) *sync.RWMutex {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+
+	key := partitionLockKey{diskPath: path, name: name}
+	lock, ok := partitionLocks[key]
+	if !ok {
+		lock = &sync.RWMutex{}
+		partitionLocks[key] = lock
+	}
+	return lock
+}
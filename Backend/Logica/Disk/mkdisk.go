@@ -1,6 +1,8 @@
 package Disk
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
 	"errors"
@@ -44,17 +46,20 @@ func MkDisk(size int64, unit string, fit string, path string) error {
 		return errors.New("algoritmo de ajuste debe ser BF, FF o WF")
 	}
 
-	// Crear directorios padre si no existen
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("error creando directorios")
+	// Crear directorios padre si no existen (los discos mem:// no tienen carpeta)
+	if !Device.IsMemoryPath(path) {
+		dir := filepath.Dir(path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("error creando directorios")
+		}
 	}
 
-	file, err := os.Create(path)
+	file, err := Device.Create(path)
 	if err != nil {
 		return fmt.Errorf("error creando archivo")
 	}
 	defer file.Close()
+	System.DropPathCache(path)
 
 	// Llenar archivo con ceros usando buffer de 1KB
 	buffer := make([]byte, 1024)
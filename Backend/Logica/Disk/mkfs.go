@@ -5,8 +5,10 @@ import (
 	"fmt"
 )
 
-// Mkfs formatea una partición montada con sistema de archivos EXT2 o EXT3
-func Mkfs(mountID string, formatType string, fs string) error {
+// Mkfs formatea una partición montada con sistema de archivos EXT2 o EXT3.
+// groups reparte inodos y bloques en grupos (1 = sin grupos) y dirIndex crea las
+// carpetas con índice de hash.
+func Mkfs(mountID string, formatType string, fs string, groups int32, dirIndex bool) error {
 	// Validar que el ID de montaje esté presente
 	if mountID == "" {
 		return fmt.Errorf("parametro -id requerido")
@@ -35,6 +37,8 @@ func Mkfs(mountID string, formatType string, fs string) error {
 			return fmt.Errorf("error inicializando EXT3")
 		}
 
+		ext3Manager.SetBlockGroups(groups)
+		ext3Manager.SetDirectoryIndex(dirIndex)
 		err = ext3Manager.FormatPartition()
 		if err != nil {
 			return err
@@ -52,13 +56,16 @@ func Mkfs(mountID string, formatType string, fs string) error {
 			return fmt.Errorf("error inicializando EXT2")
 		}
 
+		ext2Manager.SetBlockGroups(groups)
+		ext2Manager.SetDirectoryIndex(dirIndex)
 		err = ext2Manager.FormatPartition()
 		if err != nil {
 			return err
 		}
 	}
 
-	return nil
+	// El superbloque nuevo queda limpio, pero la partición sigue montada con el formato en caché
+	return System.MarkSuperBlockDirty(systemMountInfo)
 }
 
 // findMountedPartitionByID busca una partición montada por su ID único
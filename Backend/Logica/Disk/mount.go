@@ -1,10 +1,14 @@
 package Disk
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Events"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
 	"fmt"
-	"os"
+	"sync"
 )
 
 // MountInfo almacena información de particiones montadas
@@ -14,6 +18,7 @@ type MountInfo struct {
 	MountID       string
 	DiskLetter    rune
 	PartNumber    int
+	Options       MountOptions
 }
 
 // Variables globales para el sistema de montaje
@@ -24,7 +29,11 @@ var (
 	nextAvailableLetter rune            = 'A' // Siguiente letra disponible
 )
 
-// initMountSystem inicializa los mapas del sistema de montaje
+// mountMutex protege las variables de montaje: las peticiones HTTP las consultan
+// desde varias goroutines mientras mount/unmount las modifican
+var mountMutex sync.RWMutex
+
+// initMountSystem inicializa los mapas del sistema de montaje (con mountMutex tomado)
 func initMountSystem() {
 	if diskLetterMap == nil {
 		diskLetterMap = make(map[string]rune)
@@ -34,8 +43,11 @@ func initMountSystem() {
 	}
 }
 
-// Mount monta una partición y le asigna un ID único del formato {carnet}{num}{letra}
-func Mount(path string, name string) error {
+// Mount monta una partición con las opciones indicadas y le asigna un ID único del
+// formato {carnet}{num}{letra}
+func Mount(path string, name string, options MountOptions) error {
+	mountMutex.Lock()
+	defer mountMutex.Unlock()
 	initMountSystem()
 
 	// Validaciones de entrada
@@ -46,7 +58,7 @@ func Mount(path string, name string) error {
 		return fmt.Errorf("nombre requerido")
 	}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	if !Device.Exists(path) {
 		return fmt.Errorf("archivo no existe")
 	}
 
@@ -55,7 +67,7 @@ func Mount(path string, name string) error {
 		return fmt.Errorf("partición ya montada")
 	}
 
-	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(path)
 	if err != nil {
 		return fmt.Errorf("error abriendo disco")
 	}
@@ -141,12 +153,76 @@ func Mount(path string, name string) error {
 		MountID:       mountID,
 		DiskLetter:    diskLetter,
 		PartNumber:    partitionNumber,
+		Options:       options,
+	}
+
+	// La caché cubre la partición completa; las extendidas solo se montan para reportes EBR.
+	// Antes de activarla se aplican las transacciones EXT3 que quedaron registradas (también
+	// con -ro, como en Linux) y se actualizan los datos de montaje del superbloque.
+	wasDirty := false
+	if targetPartition.PartType != 'E' {
+		replayed, err := System.ReplayIntentLog(path, targetPartition)
+		if err != nil {
+			return fmt.Errorf("error aplicando el journal: %v", err)
+		}
+		if replayed > 0 {
+			Output.Printf("Journal: %d transacción(es) pendiente(s) aplicada(s) en %s\n", replayed, mountID)
+		}
+		if options.ReadOnly {
+			wasDirty = System.IsSuperBlockDirty(systemMountInfo(&mountInfo))
+		} else if wasDirty, err = System.RecordMount(systemMountInfo(&mountInfo)); err != nil {
+			return fmt.Errorf("error actualizando superbloque: %v", err)
+		}
+		if err := Device.EnableCache(path, mountID, targetPartition.PartStart, targetPartition.PartSize); err != nil {
+			return fmt.Errorf("error activando caché: %v", err)
+		}
 	}
+
 	mountedPartitions = append(mountedPartitions, mountInfo)
+	Events.EmitMount(mountID, path, name)
+
+	// La revisión termina antes de que mount retorne, es decir, antes de cualquier login
+	if wasDirty {
+		checkUncleanPartition(&mountInfo)
+	}
 
 	return nil
 }
 
+// checkUncleanPartition revisa una partición que quedó sucia del montaje anterior y
+// muestra lo que encontró. La revisión corrige contadores, por lo que no se hace con -ro.
+func checkUncleanPartition(mountInfo *MountInfo) {
+	if mountInfo.Options.ReadOnly {
+		Output.Printf("ADVERTENCIA: la partición %s no se desmontó correctamente; montada en solo lectura, no se revisa\n", mountInfo.PartitionName)
+		return
+	}
+	Output.Printf("ADVERTENCIA: la partición %s no se desmontó correctamente, revisando el sistema de archivos...\n", mountInfo.PartitionName)
+	findings, err := System.CheckFileSystem(systemMountInfo(mountInfo))
+	if err != nil {
+		Output.Printf("ADVERTENCIA: no se pudo revisar la partición %s: %v\n", mountInfo.MountID, err)
+		return
+	}
+	for _, finding := range findings {
+		Output.Printf("  - %s\n", finding)
+	}
+	if len(findings) == 0 {
+		Output.Println("Revisión completada: sin inconsistencias")
+	} else {
+		Output.Printf("Revisión completada: %d problema(s)\n", len(findings))
+	}
+}
+
+// systemMountInfo convierte la información de montaje al tipo del paquete System
+func systemMountInfo(mountInfo *MountInfo) *System.MountInfo {
+	return &System.MountInfo{
+		DiskPath:      mountInfo.DiskPath,
+		PartitionName: mountInfo.PartitionName,
+		MountID:       mountInfo.MountID,
+		DiskLetter:    mountInfo.DiskLetter,
+		PartNumber:    mountInfo.PartNumber,
+	}
+}
+
 // isAlreadyMounted verifica si una partición ya está montada
 func isAlreadyMounted(path string, name string) bool {
 	for _, mount := range mountedPartitions {
@@ -159,11 +235,15 @@ func isAlreadyMounted(path string, name string) bool {
 
 // GetMountedPartitions retorna la lista de particiones montadas
 func GetMountedPartitions() []MountInfo {
-	return mountedPartitions
+	mountMutex.RLock()
+	defer mountMutex.RUnlock()
+	return append([]MountInfo(nil), mountedPartitions...)
 }
 
 // UnmountPartition desmonta una partición por su ID de montaje
 func UnmountPartition(mountID string) error {
+	mountMutex.Lock()
+	defer mountMutex.Unlock()
 	initMountSystem()
 
 	// Buscar la partición montada
@@ -181,8 +261,27 @@ func UnmountPartition(mountID string) error {
 		return fmt.Errorf("ID no encontrado")
 	}
 
+	// Escribir lo pendiente en caché y vaciar el journal antes de soltar la partición;
+	// con todo en el disco queda marcada como limpia y el superbloque se copia a sus
+	// respaldos. Con -ro no se escribió nada y el estado se deja como estaba.
+	if err := System.CheckpointIntentLog(systemMountInfo(&mount)); err != nil {
+		return fmt.Errorf("error escribiendo journal: %v", err)
+	}
+	if err := Device.DisableCache(mountID); err != nil {
+		return fmt.Errorf("error escribiendo caché: %v", err)
+	}
+	if !mount.Options.ReadOnly {
+		if err := System.MarkSuperBlockClean(systemMountInfo(&mount)); err != nil {
+			return fmt.Errorf("error actualizando superbloque: %v", err)
+		}
+		if err := System.SyncSuperBlockBackups(systemMountInfo(&mount)); err != nil {
+			return fmt.Errorf("error actualizando las copias del superbloque: %v", err)
+		}
+	}
+	System.DropPathCache(mount.DiskPath)
+
 	// Abrir el disco para actualizar el correlativo
-	file, err := os.OpenFile(mount.DiskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(mount.DiskPath)
 	if err != nil {
 		return err
 	}
@@ -229,12 +328,14 @@ func UnmountPartition(mountID string) error {
 		delete(diskPartitionCount, mount.DiskPath)
 	}
 
+	Events.EmitUnmount(mountID)
 	return nil
 }
 
 // ShowMountedPartitions muestra las particiones montadas (implementación pendiente)
 func ShowMountedPartitions() {
-	initMountSystem()
+	mountMutex.RLock()
+	defer mountMutex.RUnlock()
 
 	if len(mountedPartitions) == 0 {
 		return
@@ -247,7 +348,8 @@ func ShowMountedPartitions() {
 
 // GetMountInfoByID obtiene información de montaje por ID
 func GetMountInfoByID(mountID string) (*MountInfo, error) {
-	initMountSystem()
+	mountMutex.RLock()
+	defer mountMutex.RUnlock()
 
 	for _, mount := range mountedPartitions {
 		if mount.MountID == mountID {
@@ -268,7 +370,7 @@ func GetMountedPartitionByID(mountID string) *MountInfo {
 }
 
 // findLogicalPartition busca una partición lógica por nombre en las particiones extendidas
-func findLogicalPartition(file *os.File, mbr *Models.MBR, name string) (*Models.Partition, bool) {
+func findLogicalPartition(file *Device.Handle, mbr *Models.MBR, name string) (*Models.Partition, bool) {
 	// Buscar en cada partición extendida
 	for _, partition := range mbr.Partitions {
 		if partition.PartType == 'E' && partition.PartStatus != 0 {
@@ -312,7 +414,7 @@ func findLogicalPartition(file *os.File, mbr *Models.MBR, name string) (*Models.
 }
 
 // updateLogicalPartitionEBR actualiza el EBR de una partición lógica con información de montaje
-func updateLogicalPartitionEBR(file *os.File, mbr *Models.MBR, name string, mountID string, partitionNumber int) error {
+func updateLogicalPartitionEBR(file *Device.Handle, mbr *Models.MBR, name string, mountID string, partitionNumber int) error {
 	// Buscar en cada partición extendida
 	for _, partition := range mbr.Partitions {
 		if partition.PartType == 'E' && partition.PartStatus != 0 {
@@ -353,7 +455,7 @@ func updateLogicalPartitionEBR(file *os.File, mbr *Models.MBR, name string, moun
 }
 
 // updateLogicalPartitionCorrelative actualiza el correlativo de una partición lógica en su EBR
-func updateLogicalPartitionCorrelative(file *os.File, mbr *Models.MBR, name string, correlative int64) error {
+func updateLogicalPartitionCorrelative(file *Device.Handle, mbr *Models.MBR, name string, correlative int64) error {
 	for _, partition := range mbr.Partitions {
 		if partition.PartType == 'E' && partition.PartStatus != 0 {
 			currentEBRPos := partition.PartStart
@@ -0,0 +1,90 @@
+package Disk
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"errors"
+	"sync"
+)
+
+// MountOptions son las opciones con las que se montó una partición
+type MountOptions struct {
+	ReadOnly bool // -ro: se rechaza todo lo que escriba en la partición
+	NoAtime  bool // -noatime: las lecturas no actualizan I_atime
+}
+
+// ErrReadOnly es el error de LockPartition al pedir escritura sobre una partición -ro
+var ErrReadOnly = errors.New("partición montada en solo lectura")
+
+// String retorna las opciones con el formato de mount en Linux (rw, ro, rw,noatime...)
+func (o MountOptions) String() string {
+	options := "rw"
+	if o.ReadOnly {
+		options = "ro"
+	}
+	if o.NoAtime {
+		options += ",noatime"
+	}
+	return options
+}
+
+var (
+	accessTimesMu      sync.Mutex
+	pendingAccessTimes = make(map[string][]string) // ID de montaje -> rutas leídas
+)
+
+// TouchAccessTime anota las rutas leídas en la partición montada para actualizar su
+// I_atime, salvo que se haya montado con -noatime o -ro. La llaman cat, ls y los endpoints
+// que leen archivos y carpetas con el bloqueo de lectura tomado, por eso no escribe: la
+// escritura la hace FlushAccessTimes cuando quien leyó libera ese bloqueo.
+func TouchAccessTime(mountID string, paths ...string) {
+	mountInfo, err := GetMountInfoByID(mountID)
+	if err != nil || mountInfo.Options.ReadOnly || mountInfo.Options.NoAtime {
+		return
+	}
+
+	accessTimesMu.Lock()
+	defer accessTimesMu.Unlock()
+	pendingAccessTimes[mountID] = append(pendingAccessTimes[mountID], paths...)
+}
+
+// PendingAccessTimeMounts retorna los IDs de montaje con horas de acceso por escribir
+func PendingAccessTimeMounts() []string {
+	accessTimesMu.Lock()
+	defer accessTimesMu.Unlock()
+
+	mountIDs := make([]string, 0, len(pendingAccessTimes))
+	for mountID := range pendingAccessTimes {
+		mountIDs = append(mountIDs, mountID)
+	}
+	return mountIDs
+}
+
+// FlushAccessTimes escribe I_atime de las rutas anotadas en la partición. Debe llamarse
+// con el bloqueo de escritura de la partición (que la marca sucia) y, en EXT3, dentro de
+// una transacción, como cualquier comando que escribe. Las rutas que ya no existen se
+// ignoran: la lectura ya se hizo y no debe fallar por la hora de acceso.
+func FlushAccessTimes(mountID string) error {
+	accessTimesMu.Lock()
+	paths := pendingAccessTimes[mountID]
+	delete(pendingAccessTimes, mountID)
+	accessTimesMu.Unlock()
+
+	mountInfo, err := GetMountInfoByID(mountID)
+	if err != nil || len(paths) == 0 {
+		return nil
+	}
+	manager := System.NewEXT2Manager(systemMountInfo(mountInfo))
+	if manager == nil {
+		return nil
+	}
+
+	fileManager := System.NewEXT2FileManager(manager)
+	touched := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if !touched[path] {
+			touched[path] = true
+			fileManager.TouchAccessTime(path)
+		}
+	}
+	return nil
+}
@@ -1,20 +1,25 @@
 package Disk
 
-import "fmt"
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+)
 
 // Mounted muestra en consola todas las particiones actualmente montadas
 func Mounted() {
+	mountedPartitions := GetMountedPartitions()
+
 	// Verificar si hay particiones montadas
 	if len(mountedPartitions) == 0 {
-		fmt.Println("No hay particiones montadas")
+		Output.Println("No hay particiones montadas")
 		return
 	}
 
-	// Listar todas las particiones montadas con formato ID | Nombre -> Ruta
+	// Listar todas las particiones montadas con formato ID | Nombre -> Ruta (opciones)
 	for _, mount := range mountedPartitions {
-		fmt.Printf("ID: %s | %s -> %s\n",
+		Output.Printf("ID: %s | %s -> %s (%s)\n",
 			mount.MountID,
 			mount.PartitionName,
-			mount.DiskPath)
+			mount.DiskPath,
+			mount.Options)
 	}
 }
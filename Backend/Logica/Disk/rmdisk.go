@@ -1,22 +1,24 @@
 package Disk
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/System"
 	"errors"
-	"os"
 )
 
 // RmDisk elimina un disco virtual del sistema de archivos
 func RmDisk(path string) error {
 	// Verificar que el archivo existe antes de intentar eliminarlo
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+	if !Device.Exists(path) {
 		return errors.New("el archivo no existe")
 	}
 
-	// Eliminar el archivo del disco
-	err := os.Remove(path)
+	// Eliminar el archivo del disco (o el disco en memoria)
+	err := Device.Remove(path)
 	if err != nil {
 		return err
 	}
+	System.DropPathCache(path)
 
 	return nil
 }
@@ -0,0 +1,59 @@
+package Disk
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"fmt"
+)
+
+// Sync escribe al disco las páginas modificadas en la caché de las particiones montadas,
+// vacía el registro de intenciones de las EXT3 y actualiza las copias del superbloque.
+// Con -id solo sincroniza esa partición.
+func Sync(params map[string]string) error {
+	targets := GetMountedPartitions()
+	if id, ok := params["id"]; ok {
+		mountInfo, err := GetMountInfoByID(id)
+		if err != nil {
+			return err
+		}
+		targets = []MountInfo{*mountInfo}
+	}
+
+	if len(targets) == 0 {
+		Output.Println("No hay particiones montadas")
+		return nil
+	}
+
+	for _, mountInfo := range targets {
+		written, stats, err := Device.FlushCache(mountInfo.MountID)
+		if err != nil {
+			return fmt.Errorf("error sincronizando %s: %v", mountInfo.MountID, err)
+		}
+		Output.Printf("%s: %d página(s) escrita(s) | en caché: %d | aciertos: %d | fallos: %d\n",
+			mountInfo.MountID, written, stats.Pages, stats.Hits, stats.Misses)
+
+		if err := System.CheckpointIntentLog(systemMountInfo(&mountInfo)); err != nil {
+			return fmt.Errorf("error vaciando el journal de %s: %v", mountInfo.MountID, err)
+		}
+
+		if !mountInfo.Options.ReadOnly {
+			if err := syncSuperBlockBackups(&mountInfo); err != nil {
+				return fmt.Errorf("error actualizando las copias del superbloque de %s: %v", mountInfo.MountID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// syncSuperBlockBackups actualiza las copias del superbloque con el bloqueo de lectura de
+// la partición (sync no lo toma en processCommand), para no copiar un superbloque que
+// otro comando está modificando
+func syncSuperBlockBackups(mountInfo *MountInfo) error {
+	unlock, err := LockPartition(mountInfo.MountID, false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return System.SyncSuperBlockBackups(systemMountInfo(mountInfo))
+}
@@ -0,0 +1,111 @@
+package Events
+
+import (
+	"sync"
+	"time"
+)
+
+// Tipos de evento publicados por el sistema
+const (
+	TypeCommand = "command" // Inicio y fin de un comando
+	TypeOutput  = "output"  // Línea de salida de un comando
+	TypeMount   = "mount"   // Partición montada
+	TypeUnmount = "unmount" // Partición desmontada
+	TypeFS      = "fs"      // Cambio en el sistema de archivos
+)
+
+// Acciones de los eventos de sistema de archivos
+const (
+	ActionCreated  = "created"
+	ActionModified = "modified"
+	ActionRemoved  = "removed"
+	ActionRenamed  = "renamed"
+	ActionMoved    = "moved"
+)
+
+// subscriberBuffer define cuántos eventos puede acumular un suscriptor lento antes de descartarlos
+const subscriberBuffer = 256
+
+// Event representa una notificación enviada a los suscriptores
+type Event struct {
+	Type      string `json:"type"`
+	Action    string `json:"action,omitempty"`
+	MountID   string `json:"mountId,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Line      string `json:"line,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+var (
+	subscribers      = make(map[chan Event]bool)
+	subscribersMutex sync.Mutex
+)
+
+// Subscribe registra un nuevo suscriptor y retorna su canal junto con la función para cancelarlo
+func Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	subscribersMutex.Lock()
+	subscribers[ch] = true
+	subscribersMutex.Unlock()
+
+	unsubscribe := func() {
+		subscribersMutex.Lock()
+		defer subscribersMutex.Unlock()
+		if subscribers[ch] {
+			delete(subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish envía el evento a todos los suscriptores sin bloquear al emisor
+func Publish(event Event) {
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().UnixMilli()
+	}
+
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+
+	for ch := range subscribers {
+		select {
+		case ch <- event:
+		default:
+			// El suscriptor no está consumiendo; se descarta el evento
+		}
+	}
+}
+
+// EmitCommand notifica el inicio ("start") o fin ("end") de un comando
+func EmitCommand(action string, command string) {
+	Publish(Event{Type: TypeCommand, Action: action, Line: command})
+}
+
+// EmitOutput publica una línea de salida de un comando
+func EmitOutput(line string) {
+	Publish(Event{Type: TypeOutput, Line: line})
+}
+
+// EmitMount notifica que se montó una partición
+func EmitMount(mountID string, diskPath string, partitionName string) {
+	Publish(Event{Type: TypeMount, MountID: mountID, Path: diskPath, Target: partitionName})
+}
+
+// EmitUnmount notifica que se desmontó una partición
+func EmitUnmount(mountID string) {
+	Publish(Event{Type: TypeUnmount, MountID: mountID})
+}
+
+// EmitFSChange notifica un cambio sobre una ruta del sistema de archivos
+func EmitFSChange(mountID string, action string, path string) {
+	Publish(Event{Type: TypeFS, Action: action, MountID: mountID, Path: path})
+}
+
+// EmitFSMove notifica que una ruta cambió de nombre o de ubicación
+func EmitFSMove(mountID string, action string, path string, target string) {
+	Publish(Event{Type: TypeFS, Action: action, MountID: mountID, Path: path, Target: target})
+}
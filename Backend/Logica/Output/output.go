@@ -0,0 +1,70 @@
+package Output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// Salida de los comandos.
+//
+// Los comandos imprimen con Printf, Println y Print de este paquete en lugar de fmt.
+// Capture asocia un writer a la goroutine que ejecuta el comando, así dos peticiones
+// simultáneas no mezclan su salida y ninguna necesita redirigir os.Stdout. Fuera de
+// Capture (la consola) la salida va a os.Stdout. Los comandos no lanzan goroutines
+// propias: lo que imprimiera una goroutine nueva iría a os.Stdout.
+
+// writers relaciona el id de cada goroutine que está capturando con su writer
+var writers sync.Map
+
+// Capture ejecuta fn en la goroutine actual enviando a w todo lo que imprima
+func Capture(w io.Writer, fn func() error) error {
+	id := goroutineID()
+	if previous, ok := writers.Load(id); ok {
+		defer writers.Store(id, previous)
+	} else {
+		defer writers.Delete(id)
+	}
+	writers.Store(id, w)
+	return fn()
+}
+
+// Writer retorna el destino de la salida de la goroutine actual
+func Writer() io.Writer {
+	if w, ok := writers.Load(goroutineID()); ok {
+		return w.(io.Writer)
+	}
+	return os.Stdout
+}
+
+// Printf imprime con formato, como fmt.Printf
+func Printf(format string, a ...any) {
+	fmt.Fprintf(Writer(), format, a...)
+}
+
+// Println imprime los valores y un salto de línea, como fmt.Println
+func Println(a ...any) {
+	fmt.Fprintln(Writer(), a...)
+}
+
+// Print imprime los valores, como fmt.Print
+func Print(a ...any) {
+	fmt.Fprint(Writer(), a...)
+}
+
+// goroutineID lee el id de la goroutine actual de la primera línea de su traza
+// ("goroutine 18 [running]:")
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	fields := bytes.Fields(buf[:n])
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(fields[1]), 10, 64)
+	return id
+}
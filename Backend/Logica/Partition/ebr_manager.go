@@ -1,12 +1,12 @@
 package Partition
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Models"
 	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"os"
 )
 
 // EBRManager maneja particiones lógicas usando Extended Boot Records
@@ -67,7 +67,7 @@ func (e *EBRManager) CreateFirstEBR() error {
 }
 
 func (e *EBRManager) WriteEBR(ebr *Models.EBR, position int64) error {
-	file, err := os.OpenFile(e.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(e.diskPath)
 	if err != nil {
 		return fmt.Errorf("error abriendo disco")
 	}
@@ -99,11 +99,11 @@ func (e *EBRManager) WriteEBR(ebr *Models.EBR, position int64) error {
 }
 
 func (e *EBRManager) ReadEBR(position int64) (*Models.EBR, error) {
-	if _, err := os.Stat(e.diskPath); os.IsNotExist(err) {
+	if !Device.Exists(e.diskPath) {
 		return nil, fmt.Errorf("el archivo de disco no existe: %s", e.diskPath)
 	}
 
-	file, err := os.Open(e.diskPath)
+	file, err := Device.Open(e.diskPath)
 	if err != nil {
 		return nil, fmt.Errorf("error abriendo disco")
 	}
@@ -1,13 +1,13 @@
 package Partition
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Models"
 	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/rand"
-	"os"
 	"time"
 )
 
@@ -62,7 +62,7 @@ func (m *MBRManager) CreateMBR(diskSize int64, fitType byte) (*Models.MBR, error
 }
 
 func (m *MBRManager) WriteMBR(mbr *Models.MBR) error {
-	file, err := os.OpenFile(m.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(m.diskPath)
 	if err != nil {
 		return fmt.Errorf("error abriendo disco")
 	}
@@ -88,11 +88,11 @@ func (m *MBRManager) WriteMBR(mbr *Models.MBR) error {
 }
 
 func (m *MBRManager) ReadMBR() (*Models.MBR, error) {
-	if _, err := os.Stat(m.diskPath); os.IsNotExist(err) {
+	if !Device.Exists(m.diskPath) {
 		return nil, fmt.Errorf("el archivo de disco no existe: %s", m.diskPath)
 	}
 
-	file, err := os.Open(m.diskPath)
+	file, err := Device.Open(m.diskPath)
 	if err != nil {
 		return nil, fmt.Errorf("error abriendo disco")
 	}
@@ -0,0 +1,153 @@
+package Graphviz
+
+import (
+	"MIA_2S2025_P1_202105668/Models"
+	"MIA_2S2025_P1_202105668/Utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// bitmapBitsPerRow define cuántos bits se muestran por fila en el reporte
+const bitmapBitsPerRow = 20
+
+// GenerateBitmapGraph genera el reporte de un bitmap (inodos o bloques)
+func GenerateBitmapGraph(title string, bitmap []byte, count int32, diskName string, outputPath string, renderer string) error {
+	rows := bitmapRows(bitmap, int(count))
+
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(renderer, outputPath) {
+		return renderSVGReport(buildBitmapSVGReport(title, rows, count, diskName), outputPath)
+	}
+
+	// Generar contenido DOT
+	dotContent := generateBitmapDotContent(title, rows, count, diskName)
+
+	// Crear archivo temporal DOT
+	tempDir := os.TempDir()
+	dotFile := filepath.Join(tempDir, "bitmap_report.dot")
+
+	err := os.WriteFile(dotFile, []byte(dotContent), 0644)
+	if err != nil {
+		return fmt.Errorf("error creando archivo DOT: %v", err)
+	}
+	defer os.Remove(dotFile)
+
+	// Generar imagen JPG usando Graphviz
+	return Utils.GenerateImageFromDot(dotFile, outputPath)
+}
+
+// bitmapRows agrupa los bits del bitmap en filas de bitmapBitsPerRow valores
+func bitmapRows(bitmap []byte, count int) [][]string {
+	var rows [][]string
+	var current []string
+
+	for i := 0; i < count; i++ {
+		bit := "0"
+		if Models.IsBitmapBitSet(bitmap, i) {
+			bit = "1"
+		}
+		current = append(current, bit)
+
+		if len(current) == bitmapBitsPerRow {
+			rows = append(rows, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		rows = append(rows, current)
+	}
+
+	return rows
+}
+
+// countUsedBits cuenta los bits ocupados de las filas del bitmap
+func countUsedBits(rows [][]string) int {
+	used := 0
+	for _, row := range rows {
+		for _, bit := range row {
+			if bit == "1" {
+				used++
+			}
+		}
+	}
+	return used
+}
+
+// buildBitmapSVGReport construye las tablas del reporte de bitmap para el renderizador SVG
+func buildBitmapSVGReport(title string, rows [][]string, count int32, diskName string) *SVGReport {
+	used := countUsedBits(rows)
+	report := &SVGReport{Title: title}
+	report.Sections = append(report.Sections, NewKeyValueSection("", "", "", [][2]string{
+		{"Disco", diskName},
+		{"Total", fmt.Sprintf("%d", count)},
+		{"Ocupados", fmt.Sprintf("%d", used)},
+		{"Libres", fmt.Sprintf("%d", int(count)-used)},
+	}))
+
+	grid := SVGSection{Monospace: true}
+	for _, row := range rows {
+		grid.Rows = append(grid.Rows, []string{strings.Join(row, " ")})
+	}
+	report.Sections = append(report.Sections, grid)
+
+	return report
+}
+
+// generateBitmapDotContent genera el contenido DOT específico para un bitmap
+func generateBitmapDotContent(title string, rows [][]string, count int32, diskName string) string {
+	var dot strings.Builder
+
+	used := countUsedBits(rows)
+	totalHeight := 8.0 + float64(len(rows))*0.25
+	if totalHeight > 25.0 {
+		totalHeight = 25.0
+	}
+	dot.WriteString(Utils.GetBaseGraphConfig(totalHeight))
+
+	dot.WriteString("    bitmap_table [label=<\n")
+	dot.WriteString("        <TABLE BORDER=\"0\" CELLBORDER=\"0\" CELLSPACING=\"4\" BGCOLOR=\"#2a2a2a\">\n")
+
+	// Header principal
+	dot.WriteString("            <TR>\n")
+	dot.WriteString("                <TD COLSPAN=\"2\" BGCOLOR=\"#5b21b6\" ALIGN=\"center\">\n")
+	dot.WriteString(fmt.Sprintf("                    <FONT COLOR=\"#f0f0f0\" POINT-SIZE=\"24\"><B>%s</B></FONT>\n", title))
+	dot.WriteString("                </TD>\n")
+	dot.WriteString("            </TR>\n")
+
+	// Espacio separador
+	dot.WriteString(Utils.GetSeparatorRow("10"))
+
+	// Resumen del bitmap
+	dot.WriteString("            <TR>\n")
+	dot.WriteString("                <TD COLSPAN=\"2\" BGCOLOR=\"#2a2a2a\" ALIGN=\"center\">\n")
+	dot.WriteString(Utils.GetTableWrapperStart())
+	dot.WriteString(Utils.GetTableRowStyle("Disco", diskName))
+	dot.WriteString(Utils.GetTableRowStyle("Total", fmt.Sprintf("%d", count)))
+	dot.WriteString(Utils.GetTableRowStyle("Ocupados", fmt.Sprintf("%d", used)))
+	dot.WriteString(Utils.GetTableRowStyle("Libres", fmt.Sprintf("%d", int(count)-used)))
+	dot.WriteString(Utils.GetTableWrapperEnd())
+	dot.WriteString("                </TD>\n")
+	dot.WriteString("            </TR>\n")
+
+	// Espacio separador
+	dot.WriteString(Utils.GetSeparatorRow("15"))
+
+	// Bits en filas de bitmapBitsPerRow
+	dot.WriteString("            <TR>\n")
+	dot.WriteString("                <TD COLSPAN=\"2\" BGCOLOR=\"#2a2a2a\" ALIGN=\"left\">\n")
+	var lines []string
+	for _, row := range rows {
+		lines = append(lines, strings.Join(row, " "))
+	}
+	dot.WriteString(fmt.Sprintf("                    <FONT COLOR=\"#f0f0f0\" FACE=\"monospace\" POINT-SIZE=\"10\">%s</FONT>\n", strings.Join(lines, "<BR/>")))
+	dot.WriteString("                </TD>\n")
+	dot.WriteString("            </TR>\n")
+
+	dot.WriteString("        </TABLE>\n")
+	dot.WriteString("    >];\n")
+	dot.WriteString("}\n")
+
+	return dot.String()
+}
@@ -1,6 +1,7 @@
 package Graphviz
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Models"
 	"MIA_2S2025_P1_202105668/Utils"
 	"encoding/binary"
@@ -13,28 +14,34 @@ import (
 
 // DiskSegment representa un segmento del disco con su información
 type DiskSegment struct {
-	Type       string  // "MBR", "Primaria", "Extendida", "Libre"
-	Name       string  // Nombre de la partición
-	Start      int64   // Posición de inicio
-	Size       int64   // Tamaño del segmento
-	Percentage float64 // Porcentaje del disco
-	IsExtended bool    // Si es partición extendida
-	LogicalPartitions []LogicalSegment // Particiones lógicas internas
+	Type       string  `json:"type"`       // "MBR", "Primaria", "Extendida", "Libre"
+	Name       string  `json:"name"`       // Nombre de la partición
+	Start      int64   `json:"start"`      // Posición de inicio
+	Size       int64   `json:"size"`       // Tamaño del segmento
+	Percentage float64 `json:"percentage"` // Porcentaje del disco
+	IsExtended bool    `json:"isExtended"` // Si es partición extendida
+	LogicalPartitions []LogicalSegment `json:"logicalPartitions,omitempty"` // Particiones lógicas internas
 }
 
 // LogicalSegment representa una partición lógica dentro de una extendida
 type LogicalSegment struct {
-	Type       string  // "EBR", "Lógica", "Libre"
-	Name       string  // Nombre de la partición
-	Start      int64   // Posición de inicio
-	Size       int64   // Tamaño del segmento
-	Percentage float64 // Porcentaje dentro de la partición extendida
+	Type       string  `json:"type"`       // "EBR", "Lógica", "Libre"
+	Name       string  `json:"name"`       // Nombre de la partición
+	Start      int64   `json:"start"`      // Posición de inicio
+	Size       int64   `json:"size"`       // Tamaño del segmento
+	Percentage float64 `json:"percentage"` // Porcentaje dentro de la partición extendida
 }
 
 // GenerateDiskGraph genera el gráfico DOT para el reporte de disco
-func GenerateDiskGraph(diskPath string, outputPath string) error {
+func GenerateDiskGraph(diskPath string, outputPath string, renderer string) error {
 	mbr, _ := ReadMBRFromDisk(diskPath)
 	diskLayout, _ := calculateDiskLayout(diskPath, mbr)
+
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(renderer, outputPath) {
+		return renderDiskSVG(diskLayout, diskPath, outputPath)
+	}
+
 	dotContent := generateDiskDotContent(diskLayout, diskPath)
 
 	tempDir := os.TempDir()
@@ -45,9 +52,18 @@ func GenerateDiskGraph(diskPath string, outputPath string) error {
 	return Utils.GenerateImageFromDot(dotFile, outputPath)
 }
 
+// GetDiskLayout retorna la distribución calculada del disco (usada por la exportación JSON)
+func GetDiskLayout(diskPath string) ([]DiskSegment, error) {
+	mbr, err := ReadMBRFromDisk(diskPath)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo MBR: %v", err)
+	}
+	return calculateDiskLayout(diskPath, mbr)
+}
+
 // ReadMBRFromDisk lee el MBR desde el disco (función específica para disk_graph)
 func ReadMBRFromDisk(diskPath string) (*Models.MBR, error) {
-	file, err := os.Open(diskPath)
+	file, err := Device.Open(diskPath)
 	if err != nil {
 		return nil, err
 	}
@@ -10,13 +10,20 @@ import (
 )
 
 // GenerateEBRGraph genera el gráfico DOT para el reporte EBR
-func GenerateEBRGraph(diskPath string, ebrName string, outputPath string) error {
+func GenerateEBRGraph(diskPath string, ebrName string, outputPath string, renderer string) error {
 	// Buscar el EBR específico
 	ebr, err := findEBRByName(diskPath, ebrName)
 	if err != nil {
 		return fmt.Errorf("error buscando EBR '%s': %v", ebrName, err)
 	}
 
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(renderer, outputPath) {
+		report := &SVGReport{Title: "REPORTE DE EBR", TitleColor: "#991b1b"}
+		report.Sections = append(report.Sections, buildEBRSVGSection(fmt.Sprintf("PARTICIÓN LÓGICA: %s", ebrName), ebr))
+		return renderSVGReport(report, outputPath)
+	}
+
 	// Generar contenido DOT
 	dotContent := generateEBRDotContent(ebr, ebrName)
 
@@ -131,7 +138,7 @@ func generateEBRDotContent(ebr *Models.EBR, ebrName string) string {
 }
 
 // GenerateEBRCompleteGraph genera el gráfico DOT para un reporte completo de todos los EBRs
-func GenerateEBRCompleteGraph(diskPath string, outputPath string) error {
+func GenerateEBRCompleteGraph(diskPath string, outputPath string, renderer string) error {
 	// Leer el MBR para encontrar particiones extendidas
 	mbr, err := ReadMBRFromDisk(diskPath)
 	if err != nil {
@@ -156,6 +163,20 @@ func GenerateEBRCompleteGraph(diskPath string, outputPath string) error {
 		return fmt.Errorf("no se encontraron particiones lógicas en el disco")
 	}
 
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(renderer, outputPath) {
+		report := &SVGReport{Title: "REPORTE COMPLETO DE EBRs", TitleColor: "#991b1b"}
+		report.Sections = append(report.Sections, NewKeyValueSection("", "", "", [][2]string{
+			{"Total Particiones Lógicas", fmt.Sprintf("%d", len(allLogicalPartitions))},
+			{"Particiones Extendidas", fmt.Sprintf("%d", len(extendedPartitionNames))},
+		}))
+		for i := range allLogicalPartitions {
+			title := fmt.Sprintf("PARTICIÓN LÓGICA %d: %s", i+1, allLogicalPartitions[i].GetLogicalPartitionName())
+			report.Sections = append(report.Sections, buildEBRSVGSection(title, &allLogicalPartitions[i]))
+		}
+		return renderSVGReport(report, outputPath)
+	}
+
 	// Generar contenido DOT
 	dotContent := generateEBRCompleteDotContent(allLogicalPartitions, extendedPartitionNames)
 
@@ -255,3 +276,20 @@ func generateEBRCompleteDotContent(logicalPartitions []Models.EBR, extendedNames
 
 	return dot.String()
 }
+
+// buildEBRSVGSection construye la tabla de un EBR para el renderizador SVG
+func buildEBRSVGSection(title string, ebr *Models.EBR) SVGSection {
+	nextValue := "-1"
+	if ebr.HasNext() {
+		nextValue = fmt.Sprintf("%d", ebr.PartNext)
+	}
+
+	return NewKeyValueSection(title, "#7f1d1d", "#fecaca", [][2]string{
+		{"part_mount", fmt.Sprintf("%d", ebr.PartMount)},
+		{"part_fit", fmt.Sprintf("%c", ebr.PartFit)},
+		{"part_start", fmt.Sprintf("%d", ebr.PartStart)},
+		{"part_size", fmt.Sprintf("%d", ebr.PartS)},
+		{"part_next", nextValue},
+		{"part_name", ebr.GetLogicalPartitionName()},
+	})
+}
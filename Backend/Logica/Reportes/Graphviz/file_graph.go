@@ -9,7 +9,12 @@ import (
 )
 
 // GenerateFileGraph genera el gráfico DOT para el reporte de archivo
-func GenerateFileGraph(fileName string, filePath string, content string, diskName string, outputPath string) error {
+func GenerateFileGraph(fileName string, filePath string, content string, diskName string, outputPath string, renderer string) error {
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(renderer, outputPath) {
+		return renderSVGReport(buildFileSVGReport(fileName, filePath, content, diskName), outputPath)
+	}
+
 	// Generar contenido DOT
 	dotContent := generateFileGraphDotContent(fileName, filePath, content, diskName)
 
@@ -27,6 +32,29 @@ func GenerateFileGraph(fileName string, filePath string, content string, diskNam
 	return Utils.GenerateImageFromDot(dotFile, outputPath)
 }
 
+// buildFileSVGReport construye las tablas del reporte de archivo para el renderizador SVG
+func buildFileSVGReport(fileName string, filePath string, content string, diskName string) *SVGReport {
+	report := &SVGReport{Title: "REPORTE DE ARCHIVO"}
+	report.Sections = append(report.Sections, NewKeyValueSection("", "", "", [][2]string{
+		{"Disco", diskName},
+		{"Nombre del archivo", fileName},
+		{"Ruta completa", filePath},
+		{"Tamaño", fmt.Sprintf("%d bytes", len(content))},
+	}))
+
+	contentSection := SVGSection{Title: "CONTENIDO", TitleColor: "#4a4a4a", Monospace: true}
+	if content == "" {
+		contentSection.Rows = append(contentSection.Rows, []string{"(El archivo está vacío)"})
+	} else {
+		for _, line := range strings.Split(tabulateContent(content, 60), "\n") {
+			contentSection.Rows = append(contentSection.Rows, []string{line})
+		}
+	}
+	report.Sections = append(report.Sections, contentSection)
+
+	return report
+}
+
 // generateFileGraphDotContent genera el contenido DOT específico para el reporte de archivo
 func generateFileGraphDotContent(fileName string, filePath string, content string, diskName string) string {
 	var dot strings.Builder
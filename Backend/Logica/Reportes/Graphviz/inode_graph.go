@@ -1,9 +1,9 @@
 package Graphviz
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"encoding/binary"
 	"fmt"
-	"os"
 	"time"
 	"MIA_2S2025_P1_202105668/Logica/Disk"
 	"MIA_2S2025_P1_202105668/Logica/Users"
@@ -18,6 +18,7 @@ type InodeGraphGenerator struct {
 	mountInfo   *Disk.MountInfo
 	superBlock  *Models.SuperBloque
 	viewType    InodeViewType
+	renderer    string
 }
 
 // InodeViewType define los tipos de vista disponibles
@@ -31,12 +32,13 @@ const (
 )
 
 // NewInodeGraphGenerator crea un nuevo generador de reportes de inodos
-func NewInodeGraphGenerator(partitionID, outputPath, format string, viewType InodeViewType) *InodeGraphGenerator {
+func NewInodeGraphGenerator(partitionID, outputPath, format string, viewType InodeViewType, renderer string) *InodeGraphGenerator {
 	base := NewGraphvizBase("inodos", outputPath, format)
 	return &InodeGraphGenerator{
 		GraphvizBase: base,
 		partitionID:  partitionID,
 		viewType:     viewType,
+		renderer:     renderer,
 	}
 }
 
@@ -60,6 +62,11 @@ func (ig *InodeGraphGenerator) Generate(partitionID string, outputPath string) e
 		return fmt.Errorf("error cargando datos del sistema: %v", err)
 	}
 
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(ig.renderer, outputPath) {
+		return renderSVGReport(ig.buildInodeSVGReport(), outputPath)
+	}
+
 	// 2. Generar vista de inodos con formato de tabla
 	ig.generateInodeTableView()
 
@@ -189,6 +196,38 @@ func (ig *InodeGraphGenerator) addInodeTableNode(nodeID int, inodo *Models.Inodo
 	ig.AddNodeWithHTML(fmt.Sprintf("inodo%d", nodeID), htmlTable, "plaintext", "none", "transparent")
 }
 
+// buildInodeSVGReport construye una tabla por cada inodo activo para el renderizador SVG
+func (ig *InodeGraphGenerator) buildInodeSVGReport() *SVGReport {
+	report := &SVGReport{Title: "REPORTE DE INODOS"}
+	inodeBitmap := ig.readInodeBitmap()
+
+	for i := 0; i < int(ig.superBlock.S_inodes_count); i++ {
+		if !Models.IsBitmapBitSet(inodeBitmap, i) {
+			continue
+		}
+		inodo := ig.readInodeFromDisk(i)
+		if inodo == nil {
+			continue
+		}
+
+		report.Sections = append(report.Sections, NewKeyValueSection(fmt.Sprintf("INODO - %d", i), "#2a2a2a", "#cba6f7", [][2]string{
+			{"i_uid", fmt.Sprintf("%d", inodo.I_uid)},
+			{"i_gid", fmt.Sprintf("%d", inodo.I_gid)},
+			{"i_size", fmt.Sprintf("%d", inodo.I_s)},
+			{"i_atime", ig.formatTimestamp(inodo.I_atime)},
+			{"i_ctime", ig.formatTimestamp(inodo.I_ctime)},
+			{"i_mtime", ig.formatTimestamp(inodo.I_mtime)},
+			{"i_block_1", fmt.Sprintf("%d", ig.formatBlockNumber(inodo.I_block[0]))},
+			{"i_block_2", fmt.Sprintf("%d", ig.formatBlockNumber(inodo.I_block[1]))},
+			{"i_block_3", fmt.Sprintf("%d", ig.formatBlockNumber(inodo.I_block[2]))},
+			{"i_perm", ig.formatPermissions(inodo.I_perm)},
+			{"i_type", ig.formatInodeType(inodo.I_type)},
+		}))
+	}
+
+	return report
+}
+
 // generateBlockNodes genera los nodos de bloques
 func (ig *InodeGraphGenerator) generateBlockNodes() {
 	// Leer bitmap de bloques para determinar cuáles están ocupados
@@ -363,7 +402,7 @@ func (ig *InodeGraphGenerator) getUserGroups() map[int32][]int {
 
 // readInodeBitmap lee el bitmap de inodos desde el disco
 func (ig *InodeGraphGenerator) readInodeBitmap() []byte {
-	file, err := os.Open(ig.mountInfo.DiskPath)
+	file, err := Device.Open(ig.mountInfo.DiskPath)
 	if err != nil {
 		return make([]byte, ig.superBlock.S_inodes_count)
 	}
@@ -379,7 +418,7 @@ func (ig *InodeGraphGenerator) readInodeBitmap() []byte {
 
 // readBlockBitmap lee el bitmap de bloques desde el disco
 func (ig *InodeGraphGenerator) readBlockBitmap() []byte {
-	file, err := os.Open(ig.mountInfo.DiskPath)
+	file, err := Device.Open(ig.mountInfo.DiskPath)
 	if err != nil {
 		return make([]byte, ig.superBlock.S_blocks_count)
 	}
@@ -395,7 +434,7 @@ func (ig *InodeGraphGenerator) readBlockBitmap() []byte {
 
 // readInodeFromDisk lee un inodo específico desde el disco
 func (ig *InodeGraphGenerator) readInodeFromDisk(inodeID int) *Models.Inodo {
-	file, err := os.Open(ig.mountInfo.DiskPath)
+	file, err := Device.Open(ig.mountInfo.DiskPath)
 	if err != nil {
 		return nil
 	}
@@ -448,7 +487,7 @@ func (ig *InodeGraphGenerator) formatInodeType(inodeType byte) string {
 func (ig *InodeGraphGenerator) getPartitionStart() int64 {
 	// Esto debería obtener la posición real de la partición desde el MBR
 	// Por simplicidad, asumimos que está en el mountInfo
-	file, err := os.Open(ig.mountInfo.DiskPath)
+	file, err := Device.Open(ig.mountInfo.DiskPath)
 	if err != nil {
 		return 0
 	}
@@ -21,7 +21,7 @@ type LsEntry struct {
 }
 
 // GenerateLsGraph genera el gráfico DOT para el reporte ls
-func GenerateLsGraph(permissions []string, owners []string, groups []string, sizes []int32, dates []string, times []string, types []string, names []string, diskName string, dirPath string, outputPath string) error {
+func GenerateLsGraph(permissions []string, owners []string, groups []string, sizes []int32, dates []string, times []string, types []string, names []string, diskName string, dirPath string, outputPath string, renderer string) error {
 	// Crear entries a partir de los slices
 	var entries []LsEntry
 
@@ -45,6 +45,11 @@ func GenerateLsGraph(permissions []string, owners []string, groups []string, siz
 		}
 		entries = append(entries, entry)
 	}
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(renderer, outputPath) {
+		return renderSVGReport(buildLsSVGReport(entries, diskName, dirPath), outputPath)
+	}
+
 	// Generar contenido DOT
 	dotContent := generateLsDotContent(entries, diskName, dirPath)
 
@@ -62,6 +67,33 @@ func GenerateLsGraph(permissions []string, owners []string, groups []string, siz
 	return Utils.GenerateImageFromDot(dotFile, outputPath)
 }
 
+// buildLsSVGReport construye las tablas del reporte ls para el renderizador SVG
+func buildLsSVGReport(entries []LsEntry, diskName string, dirPath string) *SVGReport {
+	report := &SVGReport{Title: "REPORTE LS"}
+	report.Sections = append(report.Sections, SVGSection{
+		Rows: [][]string{{"Disco", diskName, "Directorio", dirPath}},
+	})
+
+	listing := SVGSection{
+		Columns: []string{"Permisos", "Owner", "Grupo", "Size(Bytes)", "Fecha", "Hora", "Tipo", "Name"},
+	}
+	if len(entries) == 0 {
+		listing.Rows = append(listing.Rows, []string{"(Directorio vacío)"})
+	}
+	for _, entry := range entries {
+		bgColor := "#2a2a2a"
+		if entry.Type == "Carpeta" {
+			bgColor = "#1e3a8a" // Azul más oscuro para carpetas
+		}
+		listing.Rows = append(listing.Rows, []string{entry.Permissions, entry.Owner, entry.Group,
+			fmt.Sprintf("%d", entry.Size), entry.Date, entry.Time, entry.Type, entry.Name})
+		listing.RowColors = append(listing.RowColors, bgColor)
+	}
+	report.Sections = append(report.Sections, listing)
+
+	return report
+}
+
 // generateLsDotContent genera el contenido DOT específico para el reporte ls
 func generateLsDotContent(entries []LsEntry, diskName string, dirPath string) string {
 	var dot strings.Builder
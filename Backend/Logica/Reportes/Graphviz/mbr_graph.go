@@ -11,13 +11,18 @@ import (
 )
 
 // GenerateMBRGraph genera el gráfico DOT para el reporte MBR
-func GenerateMBRGraph(diskPath string, outputPath string) error {
+func GenerateMBRGraph(diskPath string, outputPath string, renderer string) error {
 	// Leer datos del MBR
 	mbr, err := ReadMBRFromDisk(diskPath)
 	if err != nil {
 		return fmt.Errorf("error al leer MBR: %v", err)
 	}
 
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(renderer, outputPath) {
+		return renderSVGReport(buildMBRSVGReport(mbr, diskPath), outputPath)
+	}
+
 	// Generar contenido DOT
 	dotContent := generateMBRDotContent(mbr, diskPath)
 
@@ -35,6 +40,59 @@ func GenerateMBRGraph(diskPath string, outputPath string) error {
 	return Utils.GenerateImageFromDot(dotFile, outputPath)
 }
 
+// buildMBRSVGReport construye las tablas del reporte MBR para el renderizador SVG
+func buildMBRSVGReport(mbr *Models.MBR, diskPath string) *SVGReport {
+	report := &SVGReport{Title: "REPORTE DE MBR"}
+	report.Sections = append(report.Sections, NewKeyValueSection("", "", "", [][2]string{
+		{"mbr_tamano", fmt.Sprintf("%d", mbr.MbrSize)},
+		{"mbr_fecha_creacion", time.Unix(mbr.MbrCreationDate, 0).Format("2006-01-02 15:04")},
+		{"mbr_disk_signature", fmt.Sprintf("%d", mbr.MbrSignature)},
+	}))
+
+	for _, partition := range mbr.Partitions {
+		if partition.IsEmptyPartition() {
+			continue
+		}
+
+		title, headerBg, headerText := "PARTICIÓN", "#4c1d95", "#e9d5ff"
+		if partition.IsPrimary() {
+			title = "PARTICIÓN PRIMARIA"
+		} else if partition.IsExtended() {
+			title, headerBg, headerText = "PARTICIÓN EXTENDIDA", "#1e293b", "#bae6fd"
+		}
+
+		report.Sections = append(report.Sections, NewKeyValueSection(title, headerBg, headerText, [][2]string{
+			{"part_status", fmt.Sprintf("%d", partition.PartStatus)},
+			{"part_type", fmt.Sprintf("%c", partition.PartType)},
+			{"part_fit", fmt.Sprintf("%c", partition.PartFit)},
+			{"part_start", fmt.Sprintf("%d", partition.PartStart)},
+			{"part_size", fmt.Sprintf("%d", partition.PartSize)},
+			{"part_name", partition.GetPartitionName()},
+		}))
+
+		if !partition.IsExtended() {
+			continue
+		}
+
+		logicalPartitions, err := Utils.ReadLogicalPartitions(diskPath, partition.PartStart)
+		if err != nil {
+			continue
+		}
+		for _, logical := range logicalPartitions {
+			report.Sections = append(report.Sections, NewKeyValueSection("PARTICIÓN LÓGICA", "#7f1d1d", "#fecaca", [][2]string{
+				{"part_status", fmt.Sprintf("%d", logical.PartMount)},
+				{"part_next", fmt.Sprintf("%d", logical.PartNext)},
+				{"part_fit", fmt.Sprintf("%c", logical.PartFit)},
+				{"part_start", fmt.Sprintf("%d", logical.PartStart)},
+				{"part_size", fmt.Sprintf("%d", logical.PartS)},
+				{"part_name", logical.GetLogicalPartitionName()},
+			}))
+		}
+	}
+
+	return report
+}
+
 
 func generateMBRDotContent(mbr *Models.MBR, diskPath string) string {
 	var dot strings.Builder
@@ -0,0 +1,118 @@
+package Graphviz
+
+import (
+	"MIA_2S2025_P1_202105668/Utils"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// orphanColumns son los encabezados del reporte de archivos huérfanos
+var orphanColumns = []string{"Ruta", "Tipo", "Propietario", "Grupo", "Motivo"}
+
+// GenerateOrphanGraph genera el reporte de archivos cuyo propietario o grupo ya no existe
+func GenerateOrphanGraph(rows [][]string, diskName string, outputPath string, renderer string) error {
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(renderer, outputPath) {
+		return renderSVGReport(buildOrphanSVGReport(rows, diskName), outputPath)
+	}
+
+	// Generar contenido DOT
+	dotContent := generateOrphanDotContent(rows, diskName)
+
+	// Crear archivo temporal DOT
+	tempDir := os.TempDir()
+	dotFile := filepath.Join(tempDir, "orphan_report.dot")
+
+	err := os.WriteFile(dotFile, []byte(dotContent), 0644)
+	if err != nil {
+		return fmt.Errorf("error creando archivo DOT: %v", err)
+	}
+	defer os.Remove(dotFile)
+
+	// Generar imagen usando Graphviz
+	return Utils.GenerateImageFromDot(dotFile, outputPath)
+}
+
+// buildOrphanSVGReport construye las tablas del reporte de huérfanos para el renderizador SVG
+func buildOrphanSVGReport(rows [][]string, diskName string) *SVGReport {
+	report := &SVGReport{Title: "ARCHIVOS HUÉRFANOS"}
+	report.Sections = append(report.Sections, NewKeyValueSection("", "", "", [][2]string{
+		{"Disco", diskName},
+		{"Entradas", fmt.Sprintf("%d", len(rows))},
+	}))
+
+	listing := SVGSection{Columns: orphanColumns}
+	if len(rows) == 0 {
+		listing.Rows = append(listing.Rows, []string{"(Sin archivos huérfanos)"})
+	}
+	listing.Rows = append(listing.Rows, rows...)
+	report.Sections = append(report.Sections, listing)
+
+	return report
+}
+
+// generateOrphanDotContent genera el contenido DOT específico para el reporte de huérfanos
+func generateOrphanDotContent(rows [][]string, diskName string) string {
+	var dot strings.Builder
+
+	totalHeight := 6.0 + float64(len(rows))*0.4
+	if totalHeight > 25.0 {
+		totalHeight = 25.0
+	}
+	dot.WriteString(Utils.GetBaseGraphConfig(totalHeight))
+
+	dot.WriteString("    orphan_table [label=<\n")
+	dot.WriteString("        <TABLE BORDER=\"0\" CELLBORDER=\"0\" CELLSPACING=\"4\" BGCOLOR=\"#2a2a2a\">\n")
+
+	// Header principal
+	dot.WriteString("            <TR>\n")
+	dot.WriteString(fmt.Sprintf("                <TD COLSPAN=\"%d\" BGCOLOR=\"#5b21b6\" ALIGN=\"center\">\n", len(orphanColumns)))
+	dot.WriteString("                    <FONT COLOR=\"#f0f0f0\" POINT-SIZE=\"24\"><B>ARCHIVOS HUÉRFANOS</B></FONT>\n")
+	dot.WriteString("                </TD>\n")
+	dot.WriteString("            </TR>\n")
+
+	// Espacio separador
+	dot.WriteString(Utils.GetSeparatorRow("10"))
+
+	// Resumen
+	dot.WriteString("            <TR>\n")
+	dot.WriteString(fmt.Sprintf("                <TD COLSPAN=\"%d\" BGCOLOR=\"#2a2a2a\" ALIGN=\"center\">\n", len(orphanColumns)))
+	dot.WriteString(Utils.GetTableWrapperStart())
+	dot.WriteString(Utils.GetTableRowStyle("Disco", diskName))
+	dot.WriteString(Utils.GetTableRowStyle("Entradas", fmt.Sprintf("%d", len(rows))))
+	dot.WriteString(Utils.GetTableWrapperEnd())
+	dot.WriteString("                </TD>\n")
+	dot.WriteString("            </TR>\n")
+
+	// Espacio separador
+	dot.WriteString(Utils.GetSeparatorRow("15"))
+
+	// Encabezados
+	dot.WriteString("            <TR>\n")
+	for _, column := range orphanColumns {
+		dot.WriteString(fmt.Sprintf("                <TD BGCOLOR=\"#4a4a4a\" BORDER=\"1\"><FONT COLOR=\"#f0f0f0\"><B>%s</B></FONT></TD>\n", column))
+	}
+	dot.WriteString("            </TR>\n")
+
+	if len(rows) == 0 {
+		dot.WriteString("            <TR>\n")
+		dot.WriteString(fmt.Sprintf("                <TD COLSPAN=\"%d\" BGCOLOR=\"#2a2a2a\"><FONT COLOR=\"#f0f0f0\">(Sin archivos huérfanos)</FONT></TD>\n", len(orphanColumns)))
+		dot.WriteString("            </TR>\n")
+	}
+	for _, row := range rows {
+		dot.WriteString("            <TR>\n")
+		for _, cell := range row {
+			dot.WriteString(fmt.Sprintf("                <TD BGCOLOR=\"#2a2a2a\" BORDER=\"1\" ALIGN=\"left\"><FONT COLOR=\"#f0f0f0\">%s</FONT></TD>\n", html.EscapeString(cell)))
+		}
+		dot.WriteString("            </TR>\n")
+	}
+
+	dot.WriteString("        </TABLE>\n")
+	dot.WriteString("    >];\n")
+	dot.WriteString("}\n")
+
+	return dot.String()
+}
@@ -11,7 +11,16 @@ import (
 )
 
 // GenerateSuperBlockGraph genera el gráfico DOT para el reporte del superbloque
-func GenerateSuperBlockGraph(superblock *Models.SuperBloque, diskName string, outputPath string) error {
+func GenerateSuperBlockGraph(superblock *Models.SuperBloque, diskName string, outputPath string, renderer string) error {
+	// Renderizar directamente en SVG si Graphviz no está disponible o se forzó
+	if Utils.ShouldUseSVGRenderer(renderer, outputPath) {
+		report := &SVGReport{Title: "REPORTE DE SUPERBLOQUE"}
+		report.Sections = append(report.Sections,
+			NewKeyValueSection("", "", "", [][2]string{{"Disco", diskName}}),
+			NewKeyValueSection("", "", "", superBlockRows(superblock, diskName)))
+		return renderSVGReport(report, outputPath)
+	}
+
 	// Generar contenido DOT
 	dotContent := generateSuperBlockDotContent(superblock, diskName)
 
@@ -71,115 +80,70 @@ func generateSuperBlockDotContent(sb *Models.SuperBloque, diskName string) strin
 	dot.WriteString("                <TD COLSPAN=\"2\" BGCOLOR=\"#2a2a2a\" ALIGN=\"center\">\n")
 	dot.WriteString(Utils.GetTableWrapperStart())
 
-	// sb_nombre_hd
-	dot.WriteString(Utils.GetTableRowStyle("sb_nombre_hd", diskName))
-
-	// sb_filesystem_type
-	dot.WriteString(Utils.GetTableRowStyle("sb_filesystem_type", fmt.Sprintf("%d", sb.S_filesystem_type)))
-
-	// sb_arbol_virtual_count (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_arbol_virtual_count", "0"))
-
-	// sb_detalle_directorio_count (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_detalle_directorio_count", "0"))
-
-	// sb_inodos_count
-	dot.WriteString(Utils.GetTableRowStyle("sb_inodos_count", fmt.Sprintf("%d", sb.S_inodes_count)))
-
-	// sb_bloques_count
-	dot.WriteString(Utils.GetTableRowStyle("sb_bloques_count", fmt.Sprintf("%d", sb.S_blocks_count)))
-
-	// sb_arbol_virtual_free (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_arbol_virtual_free", "0"))
+	// Filas del superbloque
+	for _, row := range superBlockRows(sb, diskName) {
+		dot.WriteString(Utils.GetTableRowStyle(row[0], row[1]))
+	}
 
-	// sb_detalle_directorio_free (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_detalle_directorio_free", "0"))
+	dot.WriteString(Utils.GetTableWrapperEnd())
+	dot.WriteString("                </TD>\n")
+	dot.WriteString("            </TR>\n")
 
-	// sb_inodos_free
-	dot.WriteString(Utils.GetTableRowStyle("sb_inodos_free", fmt.Sprintf("%d", sb.S_free_inodes_count)))
+	dot.WriteString("        </TABLE>\n")
+	dot.WriteString("    >];\n")
+	dot.WriteString("}\n")
 
-	// sb_bloques_free
-	dot.WriteString(Utils.GetTableRowStyle("sb_bloques_free", fmt.Sprintf("%d", sb.S_free_blocks_count)))
+	return dot.String()
+}
 
-	// sb_date_creacion
+// superBlockRows retorna los campos del reporte de superbloque en orden
+func superBlockRows(sb *Models.SuperBloque, diskName string) [][2]string {
 	mtime := "N/A"
 	if sb.S_mtime > 0 {
 		mtime = time.Unix(int64(sb.S_mtime), 0).Format("2006-01-02 15:04:05")
 	}
-	dot.WriteString(Utils.GetTableRowStyle("sb_date_creacion", mtime))
 
-	// sb_date_ultimo_montaje
 	umtime := "N/A"
 	if sb.S_umtime > 0 {
 		umtime = time.Unix(int64(sb.S_umtime), 0).Format("2006-01-02 15:04:05")
 	}
-	dot.WriteString(Utils.GetTableRowStyle("sb_date_ultimo_montaje", umtime))
-
-	// sb_montajes_count
-	dot.WriteString(Utils.GetTableRowStyle("sb_montajes_count", fmt.Sprintf("%d", sb.S_mnt_count)))
-
-	// sb_ap_bitmap_arbol_directorio (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_ap_bitmap_arbol_directorio", "0"))
-
-	// sb_ap_arbol_directorio (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_ap_arbol_directorio", "0"))
-
-	// sb_ap_bitmap_detalle_directorio (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_ap_bitmap_detalle_directorio", "0"))
-
-	// sb_ap_detalle_directorio (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_ap_detalle_directorio", "0"))
-
-	// sb_ap_bitmap_inodos
-	dot.WriteString(Utils.GetTableRowStyle("sb_ap_bitmap_inodos", fmt.Sprintf("%d", sb.S_bm_inode_start)))
 
-	// sb_ap_inodos
-	dot.WriteString(Utils.GetTableRowStyle("sb_ap_inodos", fmt.Sprintf("%d", sb.S_inode_start)))
-
-	// sb_ap_bitmap_bloques
-	dot.WriteString(Utils.GetTableRowStyle("sb_ap_bitmap_bloques", fmt.Sprintf("%d", sb.S_bm_block_start)))
-
-	// sb_ap_bloques
-	dot.WriteString(Utils.GetTableRowStyle("sb_ap_bloques", fmt.Sprintf("%d", sb.S_block_start)))
-
-	// sb_ap_log (no existe en modelo actual, calcular posición aproximada)
+	// sb_ap_log no existe en el modelo actual, se calcula una posición aproximada
 	logPos := sb.S_block_start + (sb.S_blocks_count * sb.S_block_s)
-	dot.WriteString(Utils.GetTableRowStyle("sb_ap_log", fmt.Sprintf("%d", logPos)))
-
-	// sb_size_struct_arbol_directorio (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_size_struct_arbol_directorio", "0"))
-
-	// sb_size_struct_detalle_directorio (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_size_struct_detalle_directorio", "0"))
-
-	// sb_size_struct_inodo
-	dot.WriteString(Utils.GetTableRowStyle("sb_size_struct_inodo", fmt.Sprintf("%d", sb.S_inode_s)))
-
-	// sb_size_struct_bloque
-	dot.WriteString(Utils.GetTableRowStyle("sb_size_struct_bloque", fmt.Sprintf("%d", sb.S_block_s)))
-
-	// sb_first_free_bit_arbol_directorio (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_first_free_bit_arbol_directorio", "0"))
-
-	// sb_first_free_bit_detalle_directorio (no existe en modelo actual, usar 0)
-	dot.WriteString(Utils.GetTableRowStyle("sb_first_free_bit_detalle_directorio", "0"))
-
-	// sb_first_free_bit_tabla_inodos
-	dot.WriteString(Utils.GetTableRowStyle("sb_first_free_bit_tabla_inodos", fmt.Sprintf("%d", sb.S_firts_ino)))
-
-	// sb_first_free_bit_bloques
-	dot.WriteString(Utils.GetTableRowStyle("sb_first_free_bit_bloques", fmt.Sprintf("%d", sb.S_first_blo)))
-
-	// sb_magic_num
-	dot.WriteString(Utils.GetTableRowStyle("sb_magic_num", fmt.Sprintf("%d", sb.S_magic)))
 
-	dot.WriteString(Utils.GetTableWrapperEnd())
-	dot.WriteString("                </TD>\n")
-	dot.WriteString("            </TR>\n")
-
-	dot.WriteString("        </TABLE>\n")
-	dot.WriteString("    >];\n")
-	dot.WriteString("}\n")
-
-	return dot.String()
-}
\ No newline at end of file
+	// Los campos sb_arbol_*, sb_detalle_* no existen en el modelo actual y se reportan en 0
+	return [][2]string{
+		{"sb_nombre_hd", diskName},
+		{"sb_filesystem_type", fmt.Sprintf("%d", sb.S_filesystem_type)},
+		{"sb_arbol_virtual_count", "0"},
+		{"sb_detalle_directorio_count", "0"},
+		{"sb_inodos_count", fmt.Sprintf("%d", sb.S_inodes_count)},
+		{"sb_bloques_count", fmt.Sprintf("%d", sb.S_blocks_count)},
+		{"sb_arbol_virtual_free", "0"},
+		{"sb_detalle_directorio_free", "0"},
+		{"sb_inodos_free", fmt.Sprintf("%d", sb.S_free_inodes_count)},
+		{"sb_bloques_free", fmt.Sprintf("%d", sb.S_free_blocks_count)},
+		{"sb_date_creacion", mtime},
+		{"sb_date_ultimo_montaje", umtime},
+		{"sb_montajes_count", fmt.Sprintf("%d", sb.S_mnt_count)},
+		{"sb_estado", sb.GetStateName()},
+		{"sb_ap_bitmap_arbol_directorio", "0"},
+		{"sb_ap_arbol_directorio", "0"},
+		{"sb_ap_bitmap_detalle_directorio", "0"},
+		{"sb_ap_detalle_directorio", "0"},
+		{"sb_ap_bitmap_inodos", fmt.Sprintf("%d", sb.S_bm_inode_start)},
+		{"sb_ap_inodos", fmt.Sprintf("%d", sb.S_inode_start)},
+		{"sb_ap_bitmap_bloques", fmt.Sprintf("%d", sb.S_bm_block_start)},
+		{"sb_ap_bloques", fmt.Sprintf("%d", sb.S_block_start)},
+		{"sb_ap_log", fmt.Sprintf("%d", logPos)},
+		{"sb_size_struct_arbol_directorio", "0"},
+		{"sb_size_struct_detalle_directorio", "0"},
+		{"sb_size_struct_inodo", fmt.Sprintf("%d", sb.S_inode_s)},
+		{"sb_size_struct_bloque", fmt.Sprintf("%d", sb.S_block_s)},
+		{"sb_first_free_bit_arbol_directorio", "0"},
+		{"sb_first_free_bit_detalle_directorio", "0"},
+		{"sb_first_free_bit_tabla_inodos", fmt.Sprintf("%d", sb.S_firts_ino)},
+		{"sb_first_free_bit_bloques", fmt.Sprintf("%d", sb.S_first_blo)},
+		{"sb_magic_num", fmt.Sprintf("%d", sb.S_magic)},
+	}
+}
@@ -0,0 +1,420 @@
+package Graphviz
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Medidas base del renderizador SVG (en pixeles)
+const (
+	svgMargin             = 20.0
+	svgTitleHeight        = 50.0
+	svgSectionTitleHeight = 30.0
+	svgRowHeight          = 24.0
+	svgSectionGap         = 15.0
+	svgCellPadding        = 12.0
+	svgCharWidth          = 7.5
+	svgMonoCharWidth      = 7.8
+	svgDiskWidth          = 1000.0
+	svgDiskBarHeight      = 110.0
+)
+
+// Colores compartidos con los reportes de Graphviz
+const (
+	svgBackground  = "#2a2a2a"
+	svgHeaderColor = "#5b21b6"
+	svgBorderColor = "#4a4a4a"
+	svgTextColor   = "#f0f0f0"
+)
+
+// SVGSection representa una tabla dentro de un reporte SVG
+type SVGSection struct {
+	Title      string     // Título de la sección (opcional)
+	TitleColor string     // Color de fondo del título
+	TextColor  string     // Color del texto del título
+	Columns    []string   // Encabezados de columna (opcional)
+	Rows       [][]string // Filas de la tabla
+	RowColors  []string   // Color de fondo por fila (opcional)
+	Monospace  bool       // Usar fuente monoespaciada alineada a la izquierda
+}
+
+// SVGReport representa un reporte tabular renderizable a SVG sin Graphviz
+type SVGReport struct {
+	Title      string
+	TitleColor string
+	Sections   []SVGSection
+}
+
+// NewKeyValueSection crea una sección de dos columnas (campo, valor)
+func NewKeyValueSection(title, titleColor, textColor string, pairs [][2]string) SVGSection {
+	section := SVGSection{Title: title, TitleColor: titleColor, TextColor: textColor}
+	for _, pair := range pairs {
+		section.Rows = append(section.Rows, []string{pair[0], pair[1]})
+	}
+	return section
+}
+
+// renderSVGReport escribe un reporte tabular como archivo SVG
+func renderSVGReport(report *SVGReport, outputPath string) error {
+	widths := make([][]float64, len(report.Sections))
+	contentWidth := textWidth(report.Title, false)*1.7 + 2*svgCellPadding
+
+	for i, section := range report.Sections {
+		widths[i] = sectionColumnWidths(section)
+		total := sumWidths(widths[i])
+		if total > contentWidth {
+			contentWidth = total
+		}
+	}
+
+	canvasWidth := contentWidth + 2*svgMargin
+	canvasHeight := svgMargin + svgTitleHeight + svgMargin
+	for _, section := range report.Sections {
+		canvasHeight += svgSectionGap + sectionHeight(section)
+	}
+
+	var svg strings.Builder
+	writeSVGHeader(&svg, canvasWidth, canvasHeight)
+
+	// Título principal
+	y := svgMargin
+	titleColor := report.TitleColor
+	if titleColor == "" {
+		titleColor = svgHeaderColor
+	}
+	svg.WriteString(fmt.Sprintf("  <rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n",
+		svgMargin, y, contentWidth, svgTitleHeight, titleColor))
+	writeSVGText(&svg, canvasWidth/2, y+svgTitleHeight/2, report.Title, svgTextColor, 22, true, "middle", false)
+	y += svgTitleHeight
+
+	for i, section := range report.Sections {
+		y += svgSectionGap
+		sectionWidth := sumWidths(widths[i])
+		x := svgMargin + (contentWidth-sectionWidth)/2
+		y = writeSVGSection(&svg, section, widths[i], x, y)
+	}
+
+	svg.WriteString("</svg>\n")
+	return saveSVGFile(svg.String(), outputPath)
+}
+
+// sectionColumnWidths calcula el ancho de cada columna de una sección
+func sectionColumnWidths(section SVGSection) []float64 {
+	columns := len(section.Columns)
+	for _, row := range section.Rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+	if columns == 0 {
+		columns = 1
+	}
+
+	widths := make([]float64, columns)
+	for i, header := range section.Columns {
+		widths[i] = textWidth(header, section.Monospace)*1.1 + 2*svgCellPadding
+	}
+	for _, row := range section.Rows {
+		// Las filas de una sola celda abarcan toda la tabla
+		if len(row) == 1 && columns > 1 {
+			continue
+		}
+		for i, cell := range row {
+			w := textWidth(cell, section.Monospace) + 2*svgCellPadding
+			if w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	// Repartir el espacio extra si el título o una fila completa es más ancha
+	required := textWidth(section.Title, false)*1.2 + 2*svgCellPadding
+	for _, row := range section.Rows {
+		if len(row) == 1 && columns > 1 {
+			if w := textWidth(row[0], section.Monospace) + 2*svgCellPadding; w > required {
+				required = w
+			}
+		}
+	}
+	if total := sumWidths(widths); required > total {
+		extra := (required - total) / float64(columns)
+		for i := range widths {
+			widths[i] += extra
+		}
+	}
+
+	return widths
+}
+
+// sectionHeight calcula la altura total de una sección
+func sectionHeight(section SVGSection) float64 {
+	height := float64(len(section.Rows)) * svgRowHeight
+	if section.Title != "" {
+		height += svgSectionTitleHeight
+	}
+	if len(section.Columns) > 0 {
+		height += svgRowHeight
+	}
+	return height
+}
+
+// writeSVGSection dibuja una sección y retorna la posición vertical final
+func writeSVGSection(svg *strings.Builder, section SVGSection, widths []float64, x, y float64) float64 {
+	width := sumWidths(widths)
+
+	if section.Title != "" {
+		titleColor := section.TitleColor
+		if titleColor == "" {
+			titleColor = "#4c1d95"
+		}
+		textColor := section.TextColor
+		if textColor == "" {
+			textColor = svgTextColor
+		}
+		svg.WriteString(fmt.Sprintf("  <rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n",
+			x, y, width, svgSectionTitleHeight, titleColor))
+		writeSVGText(svg, x+width/2, y+svgSectionTitleHeight/2, section.Title, textColor, 15, true, "middle", false)
+		y += svgSectionTitleHeight
+	}
+
+	if len(section.Columns) > 0 {
+		writeSVGRow(svg, section.Columns, widths, x, y, svgBorderColor, true, false)
+		y += svgRowHeight
+	}
+
+	for i, row := range section.Rows {
+		bgColor := svgBackground
+		if i < len(section.RowColors) && section.RowColors[i] != "" {
+			bgColor = section.RowColors[i]
+		}
+		// En tablas campo/valor sin encabezados la primera columna va en negrita
+		boldFirst := len(section.Columns) == 0 && len(row) == 2
+		if len(row) == 1 && len(widths) > 1 {
+			writeSVGRow(svg, row, []float64{width}, x, y, bgColor, false, section.Monospace)
+		} else if boldFirst {
+			writeSVGRow(svg, row[:1], widths[:1], x, y, bgColor, true, section.Monospace)
+			writeSVGRow(svg, row[1:], widths[1:], x+widths[0], y, bgColor, false, section.Monospace)
+		} else {
+			writeSVGRow(svg, row, widths, x, y, bgColor, false, section.Monospace)
+		}
+		y += svgRowHeight
+	}
+
+	return y
+}
+
+// writeSVGRow dibuja una fila de celdas con borde
+func writeSVGRow(svg *strings.Builder, cells []string, widths []float64, x, y float64, bgColor string, bold bool, monospace bool) {
+	cellX := x
+	for i, cell := range cells {
+		if i >= len(widths) {
+			break
+		}
+		svg.WriteString(fmt.Sprintf("  <rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\" stroke=\"%s\"/>\n",
+			cellX, y, widths[i], svgRowHeight, bgColor, svgBorderColor))
+		if monospace {
+			writeSVGText(svg, cellX+svgCellPadding, y+svgRowHeight/2, cell, svgTextColor, 12, bold, "start", true)
+		} else {
+			writeSVGText(svg, cellX+widths[i]/2, y+svgRowHeight/2, cell, svgTextColor, 13, bold, "middle", false)
+		}
+		cellX += widths[i]
+	}
+}
+
+// renderDiskSVG dibuja la barra de distribución del disco a partir del layout calculado
+func renderDiskSVG(diskLayout []DiskSegment, diskPath string, outputPath string) error {
+	totalSize := int64(0)
+	for _, segment := range diskLayout {
+		totalSize += segment.Size
+	}
+
+	// Tabla de detalle con todos los segmentos
+	detail := SVGSection{
+		Title:   "DISTRIBUCIÓN",
+		Columns: []string{"Tipo", "Nombre", "Inicio", "Tamaño", "Porcentaje"},
+	}
+	for _, segment := range diskLayout {
+		detail.Rows = append(detail.Rows, []string{segment.Type, segment.Name,
+			fmt.Sprintf("%d", segment.Start), formatSVGSize(segment.Size), fmt.Sprintf("%.1f%%", segment.Percentage)})
+		detail.RowColors = append(detail.RowColors, svgBackground)
+		for _, logical := range segment.LogicalPartitions {
+			detail.Rows = append(detail.Rows, []string{"  " + logical.Type, logical.Name,
+				fmt.Sprintf("%d", logical.Start), formatSVGSize(logical.Size), fmt.Sprintf("%.1f%% (ext)", logical.Percentage)})
+			detail.RowColors = append(detail.RowColors, "#3a3a3a")
+		}
+	}
+
+	info := NewKeyValueSection("", "", "", [][2]string{
+		{"Archivo", filepath.Base(diskPath)},
+		{"Tamaño Total", fmt.Sprintf("%.1f MB", float64(totalSize)/(1024*1024))},
+	})
+
+	detailWidths := sectionColumnWidths(detail)
+	infoWidths := sectionColumnWidths(info)
+	contentWidth := svgDiskWidth - 2*svgMargin
+	if w := sumWidths(detailWidths); w > contentWidth {
+		contentWidth = w
+	}
+	canvasWidth := contentWidth + 2*svgMargin
+	canvasHeight := svgMargin + svgTitleHeight + svgSectionGap + sectionHeight(info) + svgSectionGap +
+		svgDiskBarHeight + svgSectionGap + sectionHeight(detail) + svgMargin
+
+	var svg strings.Builder
+	writeSVGHeader(&svg, canvasWidth, canvasHeight)
+
+	y := svgMargin
+	svg.WriteString(fmt.Sprintf("  <rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n",
+		svgMargin, y, contentWidth, svgTitleHeight, svgHeaderColor))
+	writeSVGText(&svg, canvasWidth/2, y+svgTitleHeight/2, "REPORTE DE DISCO", svgTextColor, 22, true, "middle", false)
+	y += svgTitleHeight + svgSectionGap
+
+	y = writeSVGSection(&svg, info, infoWidths, svgMargin+(contentWidth-sumWidths(infoWidths))/2, y)
+	y += svgSectionGap
+
+	// Barra proporcional al tamaño de cada segmento
+	x := svgMargin
+	for _, segment := range diskLayout {
+		width := contentWidth * segment.Percentage / 100
+		if width < 2 {
+			width = 2
+		}
+		color := getSegmentColor(segment.Type)
+
+		if segment.IsExtended && len(segment.LogicalPartitions) > 0 {
+			headerHeight := svgDiskBarHeight * 0.3
+			svg.WriteString(fmt.Sprintf("  <rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\" stroke=\"%s\"/>\n",
+				x, y, width, headerHeight, color, svgBorderColor))
+			writeSegmentLabel(&svg, x, y, width, headerHeight, []string{fmt.Sprintf("Extendida %s (%.0f%%)", segment.Name, segment.Percentage)})
+
+			logicalX := x
+			for _, logical := range segment.LogicalPartitions {
+				logicalWidth := width * logical.Percentage / 100
+				if logicalWidth < 1 {
+					logicalWidth = 1
+				}
+				svg.WriteString(fmt.Sprintf("  <rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\" stroke=\"%s\"/>\n",
+					logicalX, y+headerHeight, logicalWidth, svgDiskBarHeight-headerHeight, getSegmentColor(logical.Type), svgBorderColor))
+				writeSegmentLabel(&svg, logicalX, y+headerHeight, logicalWidth, svgDiskBarHeight-headerHeight,
+					[]string{logical.Name, formatSVGSize(logical.Size)})
+				logicalX += logicalWidth
+			}
+		} else {
+			svg.WriteString(fmt.Sprintf("  <rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\" stroke=\"%s\"/>\n",
+				x, y, width, svgDiskBarHeight, color, svgBorderColor))
+			writeSegmentLabel(&svg, x, y, width, svgDiskBarHeight,
+				[]string{segment.Name, formatSVGSize(segment.Size), fmt.Sprintf("%.1f%%", segment.Percentage)})
+		}
+		x += width
+	}
+	y += svgDiskBarHeight + svgSectionGap
+
+	writeSVGSection(&svg, detail, detailWidths, svgMargin+(contentWidth-sumWidths(detailWidths))/2, y)
+
+	svg.WriteString("</svg>\n")
+	return saveSVGFile(svg.String(), outputPath)
+}
+
+// writeSegmentLabel escribe las líneas de texto de un segmento si caben en su ancho
+func writeSegmentLabel(svg *strings.Builder, x, y, width, height float64, lines []string) {
+	fits := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if textWidth(line, false)+4 <= width {
+			fits = append(fits, line)
+		}
+	}
+	if len(fits) == 0 {
+		return
+	}
+
+	lineHeight := 16.0
+	startY := y + height/2 - lineHeight*float64(len(fits)-1)/2
+	for i, line := range fits {
+		writeSVGText(svg, x+width/2, startY+float64(i)*lineHeight, line, svgTextColor, 12, i == 0, "middle", false)
+	}
+}
+
+// writeSVGHeader escribe la cabecera del documento SVG con el fondo del reporte
+func writeSVGHeader(svg *strings.Builder, width, height float64) {
+	svg.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	svg.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\">\n",
+		width, height, width, height))
+	svg.WriteString(fmt.Sprintf("  <rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", svgBackground))
+}
+
+// writeSVGText escribe un elemento de texto centrado verticalmente
+func writeSVGText(svg *strings.Builder, x, y float64, text, color string, size int, bold bool, anchor string, monospace bool) {
+	family := "Arial, Helvetica, sans-serif"
+	if monospace {
+		family = "monospace"
+	}
+	weight := "normal"
+	if bold {
+		weight = "bold"
+	}
+	svg.WriteString(fmt.Sprintf("  <text x=\"%.1f\" y=\"%.1f\" fill=\"%s\" font-family=\"%s\" font-size=\"%d\" font-weight=\"%s\" text-anchor=\"%s\" dominant-baseline=\"central\" xml:space=\"preserve\">%s</text>\n",
+		x, y, color, family, size, weight, anchor, escapeSVGText(text)))
+}
+
+// saveSVGFile guarda el SVG, ajustando la extensión de la ruta de salida
+func saveSVGFile(content string, outputPath string) error {
+	svgPath := Utils.GetSVGOutputPath(outputPath)
+	if err := os.MkdirAll(filepath.Dir(svgPath), 0755); err != nil {
+		return fmt.Errorf("error creando directorio de salida: %v", err)
+	}
+
+	if err := os.WriteFile(svgPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("error guardando archivo SVG: %v", err)
+	}
+
+	// El archivo pedido no se crea: avisar dónde quedó el reporte
+	if svgPath != outputPath {
+		Output.Printf("ADVERTENCIA: el reporte se renderizó en SVG y se guardó en %s en lugar de %s\n", svgPath, outputPath)
+	}
+	return nil
+}
+
+// escapeSVGText escapa los caracteres especiales de XML y descarta los de control
+func escapeSVGText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(text, "?"))
+	replacer := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;")
+	return replacer.Replace(text)
+}
+
+// textWidth estima el ancho en pixeles de un texto
+func textWidth(text string, monospace bool) float64 {
+	if monospace {
+		return float64(utf8.RuneCountInString(text)) * svgMonoCharWidth
+	}
+	return float64(utf8.RuneCountInString(text)) * svgCharWidth
+}
+
+// sumWidths suma los anchos de columna
+func sumWidths(widths []float64) float64 {
+	total := 0.0
+	for _, w := range widths {
+		total += w
+	}
+	return total
+}
+
+// formatSVGSize formatea un tamaño en bytes en la unidad más legible
+func formatSVGSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
@@ -0,0 +1,71 @@
+package Reportes
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/Reportes/Graphviz"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// GenerateBitmapReport genera el reporte del bitmap de inodos (bm_inode) o de bloques (bm_block)
+func GenerateBitmapReport(partitionID string, outputPath string, bitmapType string, renderer string) error {
+	// Buscar la partición montada por ID
+	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
+	if mountedPartition == nil {
+		return fmt.Errorf("particion con ID '%s' no encontrada o no montada", partitionID)
+	}
+
+	// Verificar que el disco existe
+	diskPath := mountedPartition.DiskPath
+	if !Device.Exists(diskPath) {
+		return fmt.Errorf("el disco '%s' no existe", diskPath)
+	}
+
+	// Obtener información de la partición y superbloque
+	partition, superblock, err := Users.GetPartitionAndSuperBlock(mountedPartition)
+	if err != nil {
+		return fmt.Errorf("error obteniendo superbloque: %v", err)
+	}
+
+	// Seleccionar el bitmap según el tipo de reporte
+	title := "BITMAP DE INODOS"
+	count := superblock.S_inodes_count
+	bitmapStart := superblock.S_bm_inode_start
+	if bitmapType == "bm_block" {
+		title = "BITMAP DE BLOQUES"
+		count = superblock.S_blocks_count
+		bitmapStart = superblock.S_bm_block_start
+	}
+
+	// Leer el bitmap desde el disco
+	file, err := Device.Open(diskPath)
+	if err != nil {
+		return fmt.Errorf("error abriendo disco: %v", err)
+	}
+	defer file.Close()
+
+	bitmap := make([]byte, count/8+1)
+	if _, err := file.ReadAt(bitmap, partition.PartStart+int64(bitmapStart)); err != nil {
+		return fmt.Errorf("error leyendo bitmap: %v", err)
+	}
+
+	// Crear directorio de salida si no existe
+	outputDir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("error creando directorio de salida: %v", err)
+	}
+
+	// Generar el reporte
+	diskName := filepath.Base(diskPath)
+	err = Graphviz.GenerateBitmapGraph(title, bitmap, count, diskName, outputPath, renderer)
+	if err != nil {
+		return fmt.Errorf("error generando reporte de bitmap: %v", err)
+	}
+
+	Output.Println("Reporte generado exitosamente")
+	return nil
+}
@@ -1,7 +1,9 @@
 package Reportes
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Reportes/Graphviz"
 	"fmt"
 	"os"
@@ -9,7 +11,7 @@ import (
 )
 
 // GenerateDiskReport genera un reporte del disco en formato JPG usando Graphviz
-func GenerateDiskReport(partitionID string, outputPath string) error {
+func GenerateDiskReport(partitionID string, outputPath string, renderer string) error {
 	// Buscar la partición montada por ID
 	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
 	if mountedPartition == nil {
@@ -18,7 +20,7 @@ func GenerateDiskReport(partitionID string, outputPath string) error {
 
 	// Verificar que el disco existe
 	diskPath := mountedPartition.DiskPath
-	if _, err := os.Stat(diskPath); os.IsNotExist(err) {
+	if !Device.Exists(diskPath) {
 		return fmt.Errorf("el disco '%s' no existe", diskPath)
 	}
 
@@ -29,11 +31,11 @@ func GenerateDiskReport(partitionID string, outputPath string) error {
 	}
 
 	// Generar el reporte usando Graphviz
-	err := Graphviz.GenerateDiskGraph(diskPath, outputPath)
+	err := Graphviz.GenerateDiskGraph(diskPath, outputPath, renderer)
 	if err != nil {
 		return fmt.Errorf("error generando reporte de disco: %v", err)
 	}
 
-	fmt.Println("Reporte generado exitosamente")
+	Output.Println("Reporte generado exitosamente")
 	return nil
 }
\ No newline at end of file
@@ -1,7 +1,9 @@
 package Reportes
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Reportes/Graphviz"
 	"fmt"
 	"os"
@@ -9,7 +11,7 @@ import (
 )
 
 // GenerateEBRReport genera un reporte del EBR en formato JPG usando Graphviz
-func GenerateEBRReport(partitionID string, ebrName string, outputPath string) error {
+func GenerateEBRReport(partitionID string, ebrName string, outputPath string, renderer string) error {
 	// Buscar la partición montada por ID
 	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
 	if mountedPartition == nil {
@@ -18,7 +20,7 @@ func GenerateEBRReport(partitionID string, ebrName string, outputPath string) er
 
 	// Verificar que el disco existe
 	diskPath := mountedPartition.DiskPath
-	if _, err := os.Stat(diskPath); os.IsNotExist(err) {
+	if !Device.Exists(diskPath) {
 		return fmt.Errorf("el disco '%s' no existe", diskPath)
 	}
 
@@ -34,17 +36,17 @@ func GenerateEBRReport(partitionID string, ebrName string, outputPath string) er
 	}
 
 	// Generar el reporte usando Graphviz
-	err := Graphviz.GenerateEBRGraph(diskPath, ebrName, outputPath)
+	err := Graphviz.GenerateEBRGraph(diskPath, ebrName, outputPath, renderer)
 	if err != nil {
 		return fmt.Errorf("error generando reporte EBR: %v", err)
 	}
 
-	fmt.Println("Reporte generado exitosamente")
+	Output.Println("Reporte generado exitosamente")
 	return nil
 }
 
 // GenerateEBRCompleteReport genera un reporte completo de todos los EBRs en formato JPG
-func GenerateEBRCompleteReport(partitionID string, outputPath string) error {
+func GenerateEBRCompleteReport(partitionID string, outputPath string, renderer string) error {
 	// Buscar la partición montada por ID
 	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
 	if mountedPartition == nil {
@@ -53,7 +55,7 @@ func GenerateEBRCompleteReport(partitionID string, outputPath string) error {
 
 	// Verificar que el disco existe
 	diskPath := mountedPartition.DiskPath
-	if _, err := os.Stat(diskPath); os.IsNotExist(err) {
+	if !Device.Exists(diskPath) {
 		return fmt.Errorf("el disco '%s' no existe", diskPath)
 	}
 
@@ -64,11 +66,11 @@ func GenerateEBRCompleteReport(partitionID string, outputPath string) error {
 	}
 
 	// Generar el reporte completo usando Graphviz
-	err := Graphviz.GenerateEBRCompleteGraph(diskPath, outputPath)
+	err := Graphviz.GenerateEBRCompleteGraph(diskPath, outputPath, renderer)
 	if err != nil {
 		return fmt.Errorf("error generando reporte EBR completo: %v", err)
 	}
 
-	fmt.Println("Reporte generado exitosamente")
+	Output.Println("Reporte generado exitosamente")
 	return nil
 }
\ No newline at end of file
@@ -1,7 +1,9 @@
 package Reportes
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Reportes/Graphviz"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
@@ -11,7 +13,7 @@ import (
 )
 
 // GenerateFileReport genera un reporte del contenido de un archivo específico
-func GenerateFileReport(partitionID string, outputPath string, pathFileLS string) error {
+func GenerateFileReport(partitionID string, outputPath string, pathFileLS string, renderer string) error {
 	// Validar que se proporcione la ruta del archivo
 	if pathFileLS == "" {
 		return fmt.Errorf("debe especificar la ruta del archivo con -path_file_ls")
@@ -25,7 +27,7 @@ func GenerateFileReport(partitionID string, outputPath string, pathFileLS string
 
 	// Verificar que el disco existe
 	diskPath := mountedPartition.DiskPath
-	if _, err := os.Stat(diskPath); os.IsNotExist(err) {
+	if !Device.Exists(diskPath) {
 		return fmt.Errorf("el disco '%s' no existe", diskPath)
 	}
 
@@ -73,11 +75,11 @@ func GenerateFileReport(partitionID string, outputPath string, pathFileLS string
 	fileName := filepath.Base(pathFileLS)
 
 	// Generar el reporte usando Graphviz
-	err = Graphviz.GenerateFileGraph(fileName, pathFileLS, content, diskName, outputPath)
+	err = Graphviz.GenerateFileGraph(fileName, pathFileLS, content, diskName, outputPath, renderer)
 	if err != nil {
 		return fmt.Errorf("error generando reporte de archivo: %v", err)
 	}
 
-	fmt.Println("Reporte de archivo generado exitosamente")
+	Output.Println("Reporte de archivo generado exitosamente")
 	return nil
 }
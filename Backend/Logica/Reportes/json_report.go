@@ -0,0 +1,799 @@
+package Reportes
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/Reportes/Graphviz"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Models"
+	"MIA_2S2025_P1_202105668/Utils"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MBRJSON representa el MBR serializable a JSON
+type MBRJSON struct {
+	Size          int64           `json:"size"`
+	CreationDate  int64           `json:"creationDate"`
+	CreationTime  string          `json:"creationTime"`
+	Signature     int64           `json:"signature"`
+	Fit           string          `json:"fit"`
+	Partitions    []PartitionJSON `json:"partitions"`
+	LogicalChains []EBRJSON       `json:"logicalPartitions"`
+}
+
+// PartitionJSON representa una entrada de la tabla de particiones
+type PartitionJSON struct {
+	Index       int    `json:"index"`
+	Status      int    `json:"status"`
+	Type        string `json:"type"`
+	Fit         string `json:"fit"`
+	Start       int64  `json:"start"`
+	Size        int64  `json:"size"`
+	End         int64  `json:"end"`
+	Name        string `json:"name"`
+	Correlative int64  `json:"correlative"`
+	ID          string `json:"id"`
+	Empty       bool   `json:"empty"`
+}
+
+// EBRJSON representa un EBR de la cadena de particiones lógicas
+type EBRJSON struct {
+	Position int64  `json:"position"`
+	Mount    int    `json:"mount"`
+	Fit      string `json:"fit"`
+	Start    int64  `json:"start"`
+	Size     int64  `json:"size"`
+	Next     int64  `json:"next"`
+	Name     string `json:"name"`
+	Extended string `json:"extended"`
+}
+
+// SuperBlockJSON representa el superbloque con campos calculados
+type SuperBlockJSON struct {
+	FilesystemType   int32  `json:"filesystemType"`
+	FilesystemName   string `json:"filesystemName"`
+	InodesCount      int32  `json:"inodesCount"`
+	BlocksCount      int32  `json:"blocksCount"`
+	FreeBlocksCount  int32  `json:"freeBlocksCount"`
+	FreeInodesCount  int32  `json:"freeInodesCount"`
+	MTime            string `json:"mtime"`
+	UMTime           string `json:"umtime"`
+	MountCount       int32  `json:"mountCount"`
+	State            string `json:"state"`
+	Magic            int32  `json:"magic"`
+	MagicHex         string `json:"magicHex"`
+	InodeSize        int32  `json:"inodeSize"`
+	BlockSize        int32  `json:"blockSize"`
+	FirstInode       int32  `json:"firstInode"`
+	FirstBlock       int32  `json:"firstBlock"`
+	InodeBitmapStart int32  `json:"inodeBitmapStart"`
+	BlockBitmapStart int32  `json:"blockBitmapStart"`
+	InodeTableStart  int32  `json:"inodeTableStart"`
+	BlockStart       int32  `json:"blockStart"`
+	JournalStart     int32  `json:"journalStart"`
+	PartitionStart   int64  `json:"partitionStart"`
+	UsedInodesCount  int    `json:"usedInodesCount"`
+	UsedBlocksCount  int    `json:"usedBlocksCount"`
+}
+
+// InodeJSON representa un inodo con permisos y fechas legibles
+type InodeJSON struct {
+	Number      int     `json:"number"`
+	InUse       bool    `json:"inUse"`
+	UID         int32   `json:"uid"`
+	GID         int32   `json:"gid"`
+	Size        int32   `json:"size"`
+	ATime       string  `json:"atime"`
+	CTime       string  `json:"ctime"`
+	MTime       string  `json:"mtime"`
+	Blocks      []int32 `json:"blocks"`
+	Type        string  `json:"type"`
+	Permissions string  `json:"permissions"`
+	PermString  string  `json:"permString"`
+}
+
+// DirectoryEntryJSON representa una entrada de un bloque de carpeta
+type DirectoryEntryJSON struct {
+	Name  string `json:"name"`
+	Inode int32  `json:"inode"`
+}
+
+// BlockJSON representa un bloque decodificado según el inodo que lo referencia
+type BlockJSON struct {
+	Number   int                  `json:"number"`
+	InUse    bool                 `json:"inUse"`
+	Type     string               `json:"type"`
+	Owner    int                  `json:"ownerInode"`
+	Entries  []DirectoryEntryJSON `json:"entries,omitempty"`
+	Content  string               `json:"content,omitempty"`
+	Pointers []int32              `json:"pointers,omitempty"`
+	Buckets  []int32              `json:"buckets,omitempty"`
+}
+
+// BitmapJSON representa un bitmap de inodos o bloques
+type BitmapJSON struct {
+	Type  string `json:"type"`
+	Total int    `json:"total"`
+	Used  int    `json:"used"`
+	Free  int    `json:"free"`
+	Bits  string `json:"bits"`
+}
+
+// partitionStructures agrupa los datos necesarios para leer estructuras de una partición.
+// El disco se abre una vez por petición; quien la carga debe llamar a close.
+type partitionStructures struct {
+	diskPath   string
+	file       *Device.Handle
+	partition  *Models.Partition
+	superBlock *Models.SuperBloque
+}
+
+// loadPartitionStructures abre el disco de la partición montada y lee su superbloque
+func loadPartitionStructures(partitionID string) (*partitionStructures, error) {
+	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
+	if mountedPartition == nil {
+		return nil, fmt.Errorf("particion con ID '%s' no encontrada o no montada", partitionID)
+	}
+
+	file, err := Device.Open(mountedPartition.DiskPath)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo disco: %v", err)
+	}
+
+	partition, superBlock, err := Users.ReadPartitionAndSuperBlock(file, mountedPartition)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error obteniendo superbloque: %v", err)
+	}
+
+	if superBlock.S_magic != Models.EXT2_MAGIC {
+		file.Close()
+		return nil, fmt.Errorf("la particion '%s' no tiene un sistema de archivos valido", partitionID)
+	}
+
+	return &partitionStructures{
+		diskPath:   mountedPartition.DiskPath,
+		file:       file,
+		partition:  partition,
+		superBlock: superBlock,
+	}, nil
+}
+
+// close cierra el disco abierto por loadPartitionStructures
+func (ps *partitionStructures) close() {
+	ps.file.Close()
+}
+
+// GetMBRStructure retorna el MBR del disco de la partición junto con la cadena de EBRs
+func GetMBRStructure(partitionID string) (*MBRJSON, error) {
+	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
+	if mountedPartition == nil {
+		return nil, fmt.Errorf("particion con ID '%s' no encontrada o no montada", partitionID)
+	}
+
+	mbr, err := Graphviz.ReadMBRFromDisk(mountedPartition.DiskPath)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo MBR: %v", err)
+	}
+
+	result := &MBRJSON{
+		Size:         mbr.MbrSize,
+		CreationDate: mbr.MbrCreationDate,
+		CreationTime: time.Unix(mbr.MbrCreationDate, 0).Format("2006-01-02 15:04:05"),
+		Signature:    mbr.MbrSignature,
+		Fit:          byteToString(mbr.DiskFit),
+		Partitions:   make([]PartitionJSON, 0, len(mbr.Partitions)),
+	}
+
+	for i, partition := range mbr.Partitions {
+		result.Partitions = append(result.Partitions, PartitionJSON{
+			Index:       i,
+			Status:      int(partition.PartStatus),
+			Type:        byteToString(partition.PartType),
+			Fit:         byteToString(partition.PartFit),
+			Start:       partition.PartStart,
+			Size:        partition.PartSize,
+			End:         partition.GetPartitionEnd(),
+			Name:        partition.GetPartitionName(),
+			Correlative: partition.PartCorrelative,
+			ID:          partition.GetPartitionID(),
+			Empty:       partition.IsEmptyPartition(),
+		})
+	}
+
+	result.LogicalChains, err = GetEBRStructures(partitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetEBRStructures retorna la cadena de EBRs de todas las particiones extendidas del disco
+func GetEBRStructures(partitionID string) ([]EBRJSON, error) {
+	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
+	if mountedPartition == nil {
+		return nil, fmt.Errorf("particion con ID '%s' no encontrada o no montada", partitionID)
+	}
+
+	mbr, err := Graphviz.ReadMBRFromDisk(mountedPartition.DiskPath)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo MBR: %v", err)
+	}
+
+	ebrs := make([]EBRJSON, 0)
+	for _, partition := range mbr.Partitions {
+		if !partition.IsExtended() {
+			continue
+		}
+
+		logicalPartitions, err := Utils.ReadLogicalPartitions(mountedPartition.DiskPath, partition.PartStart)
+		if err != nil {
+			continue
+		}
+
+		for _, logical := range logicalPartitions {
+			ebrs = append(ebrs, EBRJSON{
+				Position: logical.PartStart - int64(Models.GetEBRSize()),
+				Mount:    int(logical.PartMount),
+				Fit:      byteToString(logical.PartFit),
+				Start:    logical.PartStart,
+				Size:     logical.PartS,
+				Next:     logical.PartNext,
+				Name:     logical.GetLogicalPartitionName(),
+				Extended: partition.GetPartitionName(),
+			})
+		}
+	}
+
+	return ebrs, nil
+}
+
+// GetSuperBlockStructure retorna el superbloque de la partición con campos calculados
+func GetSuperBlockStructure(partitionID string) (*SuperBlockJSON, error) {
+	ps, err := loadPartitionStructures(partitionID)
+	if err != nil {
+		return nil, err
+	}
+	defer ps.close()
+
+	sb := ps.superBlock
+	fsName := "EXT2"
+	if sb.S_filesystem_type == 3 {
+		fsName = "EXT3"
+	}
+
+	result := &SuperBlockJSON{
+		FilesystemType:   sb.S_filesystem_type,
+		FilesystemName:   fsName,
+		InodesCount:      sb.S_inodes_count,
+		BlocksCount:      sb.S_blocks_count,
+		FreeBlocksCount:  sb.S_free_blocks_count,
+		FreeInodesCount:  sb.S_free_inodes_count,
+		MTime:            formatJSONTime(sb.S_mtime),
+		UMTime:           formatJSONTime(sb.S_umtime),
+		MountCount:       sb.S_mnt_count,
+		State:            sb.GetStateName(),
+		Magic:            sb.S_magic,
+		MagicHex:         fmt.Sprintf("0x%X", sb.S_magic),
+		InodeSize:        sb.S_inode_s,
+		BlockSize:        sb.S_block_s,
+		FirstInode:       sb.S_firts_ino,
+		FirstBlock:       sb.S_first_blo,
+		InodeBitmapStart: sb.S_bm_inode_start,
+		BlockBitmapStart: sb.S_bm_block_start,
+		InodeTableStart:  sb.S_inode_start,
+		BlockStart:       sb.S_block_start,
+		JournalStart:     sb.S_journal_start,
+		PartitionStart:   ps.partition.PartStart,
+	}
+
+	// Contar los elementos ocupados directamente desde los bitmaps
+	if inodeBitmap, err := ps.readBitmap("inode"); err == nil {
+		result.UsedInodesCount = countBitmapBits(inodeBitmap, int(sb.S_inodes_count))
+	}
+	if blockBitmap, err := ps.readBitmap("block"); err == nil {
+		result.UsedBlocksCount = countBitmapBits(blockBitmap, int(sb.S_blocks_count))
+	}
+
+	return result, nil
+}
+
+// GetInodeStructure retorna un inodo específico de la partición
+func GetInodeStructure(partitionID string, inodeNumber int) (*InodeJSON, error) {
+	ps, err := loadPartitionStructures(partitionID)
+	if err != nil {
+		return nil, err
+	}
+	defer ps.close()
+
+	if inodeNumber < 0 || inodeNumber >= int(ps.superBlock.S_inodes_count) {
+		return nil, fmt.Errorf("inodo %d fuera de rango (0-%d)", inodeNumber, ps.superBlock.S_inodes_count-1)
+	}
+
+	inodo, err := ps.readInode(inodeNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap, err := ps.readBitmap("inode")
+	if err != nil {
+		return nil, err
+	}
+
+	result := inodeToJSON(inodeNumber, inodo)
+	result.InUse = Models.IsBitmapBitSet(bitmap, inodeNumber)
+	return &result, nil
+}
+
+// GetUsedInodeStructures retorna todos los inodos marcados como usados en el bitmap
+func GetUsedInodeStructures(partitionID string) ([]InodeJSON, error) {
+	ps, err := loadPartitionStructures(partitionID)
+	if err != nil {
+		return nil, err
+	}
+	defer ps.close()
+
+	bitmap, err := ps.readBitmap("inode")
+	if err != nil {
+		return nil, err
+	}
+
+	inodes := make([]InodeJSON, 0)
+	for i := 0; i < int(ps.superBlock.S_inodes_count); i++ {
+		if !Models.IsBitmapBitSet(bitmap, i) {
+			continue
+		}
+		inodo, err := ps.readInode(i)
+		if err != nil {
+			continue
+		}
+		entry := inodeToJSON(i, inodo)
+		entry.InUse = true
+		inodes = append(inodes, entry)
+	}
+
+	return inodes, nil
+}
+
+// GetBlockStructure retorna un bloque decodificado según el tipo del inodo que lo referencia
+func GetBlockStructure(partitionID string, blockNumber int) (*BlockJSON, error) {
+	ps, err := loadPartitionStructures(partitionID)
+	if err != nil {
+		return nil, err
+	}
+	defer ps.close()
+
+	if blockNumber < 0 || blockNumber >= int(ps.superBlock.S_blocks_count) {
+		return nil, fmt.Errorf("bloque %d fuera de rango (0-%d)", blockNumber, ps.superBlock.S_blocks_count-1)
+	}
+
+	blockBitmap, err := ps.readBitmap("block")
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := ps.readRawBlock(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BlockJSON{
+		Number: blockNumber,
+		InUse:  Models.IsBitmapBitSet(blockBitmap, blockNumber),
+		Owner:  -1,
+		Type:   "desconocido",
+	}
+
+	// Buscar el inodo que referencia el bloque para saber cómo decodificarlo
+	owner, blockType := ps.findBlockOwner(blockNumber)
+	if owner != nil {
+		result.Owner = owner.number
+	} else {
+		blockType = "archivo"
+	}
+
+	switch blockType {
+	case "carpeta":
+		var carpeta Models.BloqueCarpeta
+		binary.Read(strings.NewReader(string(raw)), binary.LittleEndian, &carpeta)
+		result.Entries = make([]DirectoryEntryJSON, 0, len(carpeta.B_content))
+		for _, content := range carpeta.B_content {
+			result.Entries = append(result.Entries, DirectoryEntryJSON{
+				Name:  strings.TrimRight(string(content.B_name[:]), "\x00"),
+				Inode: content.B_inodo,
+			})
+		}
+	case "apuntadores":
+		var apuntadores Models.BloqueApuntadores
+		binary.Read(strings.NewReader(string(raw)), binary.LittleEndian, &apuntadores)
+		result.Pointers = apuntadores.B_pointers[:]
+	case "indice":
+		var indice Models.BloqueIndice
+		binary.Read(strings.NewReader(string(raw)), binary.LittleEndian, &indice)
+		result.Buckets = indice.B_buckets[:]
+	default:
+		result.Content = strings.TrimRight(string(raw), "\x00")
+	}
+
+	if owner != nil {
+		result.Type = blockType
+	}
+
+	return result, nil
+}
+
+// GetBitmapStructure retorna el bitmap de inodos ("inode") o de bloques ("block")
+func GetBitmapStructure(partitionID string, bitmapType string) (*BitmapJSON, error) {
+	if bitmapType != "inode" && bitmapType != "block" {
+		return nil, fmt.Errorf("tipo de bitmap '%s' no valido (inode o block)", bitmapType)
+	}
+
+	ps, err := loadPartitionStructures(partitionID)
+	if err != nil {
+		return nil, err
+	}
+	defer ps.close()
+
+	bitmap, err := ps.readBitmap(bitmapType)
+	if err != nil {
+		return nil, err
+	}
+
+	total := int(ps.superBlock.S_inodes_count)
+	if bitmapType == "block" {
+		total = int(ps.superBlock.S_blocks_count)
+	}
+
+	var bits strings.Builder
+	for i := 0; i < total; i++ {
+		if Models.IsBitmapBitSet(bitmap, i) {
+			bits.WriteByte('1')
+		} else {
+			bits.WriteByte('0')
+		}
+	}
+
+	used := countBitmapBits(bitmap, total)
+	return &BitmapJSON{
+		Type:  bitmapType,
+		Total: total,
+		Used:  used,
+		Free:  total - used,
+		Bits:  bits.String(),
+	}, nil
+}
+
+// GenerateJSONReport escribe en outputPath el reporte indicado serializado como JSON
+func GenerateJSONReport(reportName string, partitionID string, outputPath string, pathFileLS string) error {
+	var data interface{}
+	var err error
+
+	switch reportName {
+	case "mbr":
+		data, err = GetMBRStructure(partitionID)
+	case "ebr":
+		data, err = GetEBRStructures(partitionID)
+	case "sb":
+		data, err = GetSuperBlockStructure(partitionID)
+	case "inode":
+		data, err = GetUsedInodeStructures(partitionID)
+	case "bm_inode":
+		data, err = GetBitmapStructure(partitionID, "inode")
+	case "bm_block":
+		data, err = GetBitmapStructure(partitionID, "block")
+	case "disk":
+		data, err = getDiskLayoutJSON(partitionID)
+	case "file":
+		data, err = getFileJSON(partitionID, pathFileLS)
+	case "ls":
+		data, err = getLsJSON(partitionID, pathFileLS)
+	case "orphans":
+		data, err = GetOrphanedEntries(partitionID)
+	default:
+		return fmt.Errorf("tipo de reporte '%s' no reconocido", reportName)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Ajustar la extensión de salida a .json
+	ext := filepath.Ext(outputPath)
+	if !strings.EqualFold(ext, ".json") {
+		outputPath = strings.TrimSuffix(outputPath, ext) + ".json"
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("error creando directorio de salida: %v", err)
+	}
+
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializando reporte: %v", err)
+	}
+
+	if err := os.WriteFile(outputPath, content, 0644); err != nil {
+		return fmt.Errorf("error guardando reporte JSON: %v", err)
+	}
+
+	Output.Printf("Reporte JSON generado exitosamente: %s\n", outputPath)
+	return nil
+}
+
+// getDiskLayoutJSON retorna la distribución del disco calculada para el reporte disk
+func getDiskLayoutJSON(partitionID string) ([]Graphviz.DiskSegment, error) {
+	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
+	if mountedPartition == nil {
+		return nil, fmt.Errorf("particion con ID '%s' no encontrada o no montada", partitionID)
+	}
+	return Graphviz.GetDiskLayout(mountedPartition.DiskPath)
+}
+
+// getFileJSON retorna el contenido de un archivo para el reporte file
+func getFileJSON(partitionID string, pathFileLS string) (map[string]interface{}, error) {
+	if pathFileLS == "" {
+		return nil, fmt.Errorf("debe especificar la ruta del archivo con -path_file_ls")
+	}
+
+	ext2Manager, err := newReportEXT2Manager(partitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := System.NewEXT2FileManager(ext2Manager).ReadFileContent(pathFileLS)
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo archivo '%s': %v", pathFileLS, err)
+	}
+
+	return map[string]interface{}{
+		"name":    filepath.Base(pathFileLS),
+		"path":    pathFileLS,
+		"size":    len(content),
+		"content": content,
+	}, nil
+}
+
+// getLsJSON retorna el listado de un directorio para el reporte ls
+func getLsJSON(partitionID string, pathFileLS string) ([]LsEntry, error) {
+	if pathFileLS == "" {
+		pathFileLS = "/"
+	}
+
+	ext2Manager, err := newReportEXT2Manager(partitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := listDirectoryEntries(System.NewEXT2DirectoryManager(ext2Manager), pathFileLS)
+	if err != nil {
+		return nil, fmt.Errorf("error listando directorio '%s': %v", pathFileLS, err)
+	}
+	if entries == nil {
+		entries = make([]LsEntry, 0)
+	}
+	return entries, nil
+}
+
+// newReportEXT2Manager crea un EXT2Manager para la partición montada indicada
+func newReportEXT2Manager(partitionID string) (*System.EXT2Manager, error) {
+	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
+	if mountedPartition == nil {
+		return nil, fmt.Errorf("particion con ID '%s' no encontrada o no montada", partitionID)
+	}
+
+	ext2Manager := System.NewEXT2Manager(&System.MountInfo{
+		DiskPath:      mountedPartition.DiskPath,
+		PartitionName: mountedPartition.PartitionName,
+		MountID:       mountedPartition.MountID,
+		DiskLetter:    mountedPartition.DiskLetter,
+		PartNumber:    mountedPartition.PartNumber,
+	})
+	if ext2Manager == nil {
+		return nil, fmt.Errorf("error creando EXT2Manager")
+	}
+	return ext2Manager, nil
+}
+
+// inodeOwner asocia un inodo con su número
+type inodeOwner struct {
+	number int
+	inodo  *Models.Inodo
+}
+
+// readBitmap lee el bitmap de inodos o de bloques de la partición
+func (ps *partitionStructures) readBitmap(bitmapType string) ([]byte, error) {
+	count := ps.superBlock.S_inodes_count
+	start := ps.superBlock.S_bm_inode_start
+	if bitmapType == "block" {
+		count = ps.superBlock.S_blocks_count
+		start = ps.superBlock.S_bm_block_start
+	}
+
+	bitmap := make([]byte, count/8+1)
+	if _, err := ps.file.ReadAt(bitmap, ps.partition.PartStart+int64(start)); err != nil {
+		return nil, fmt.Errorf("error leyendo bitmap: %v", err)
+	}
+	return bitmap, nil
+}
+
+// readInode lee un inodo de la tabla de inodos
+func (ps *partitionStructures) readInode(inodeNumber int) (*Models.Inodo, error) {
+	raw := make([]byte, Models.INODO_SIZE)
+	inodePos := ps.partition.PartStart + int64(ps.superBlock.S_inode_start) + int64(inodeNumber*Models.INODO_SIZE)
+	if _, err := ps.file.ReadAt(raw, inodePos); err != nil {
+		return nil, fmt.Errorf("error leyendo inodo %d: %v", inodeNumber, err)
+	}
+
+	var inodo Models.Inodo
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &inodo); err != nil {
+		return nil, fmt.Errorf("error leyendo inodo %d: %v", inodeNumber, err)
+	}
+	return &inodo, nil
+}
+
+// readRawBlock lee los bytes de un bloque sin decodificar
+func (ps *partitionStructures) readRawBlock(blockNumber int) ([]byte, error) {
+	raw := make([]byte, Models.BLOQUE_SIZE)
+	blockPos := ps.partition.PartStart + int64(ps.superBlock.S_block_start) + int64(blockNumber*Models.BLOQUE_SIZE)
+	if _, err := ps.file.ReadAt(raw, blockPos); err != nil {
+		return nil, fmt.Errorf("error leyendo bloque %d: %v", blockNumber, err)
+	}
+	return raw, nil
+}
+
+// readPointerBlock lee un bloque de apuntadores; retorna nil si el número está fuera de rango
+func (ps *partitionStructures) readPointerBlock(blockNumber int32) *Models.BloqueApuntadores {
+	if blockNumber < 0 || blockNumber >= ps.superBlock.S_blocks_count {
+		return nil
+	}
+	raw, err := ps.readRawBlock(int(blockNumber))
+	if err != nil {
+		return nil
+	}
+	var pointers Models.BloqueApuntadores
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &pointers); err != nil {
+		return nil
+	}
+	return &pointers
+}
+
+// findBlockOwner busca el inodo en uso que referencia el bloque y retorna cómo lo usa:
+// "carpeta" o "archivo" para bloques de datos (directos o alcanzados por los indirectos),
+// "apuntadores" para los bloques de apuntadores e "indice" para el índice de una carpeta
+func (ps *partitionStructures) findBlockOwner(blockNumber int) (*inodeOwner, string) {
+	bitmap, err := ps.readBitmap("inode")
+	if err != nil {
+		return nil, ""
+	}
+
+	target := int32(blockNumber)
+	for i := 0; i < int(ps.superBlock.S_inodes_count); i++ {
+		if !Models.IsBitmapBitSet(bitmap, i) {
+			continue
+		}
+		inodo, err := ps.readInode(i)
+		if err != nil {
+			continue
+		}
+
+		dataType := "archivo"
+		if inodo.I_type == Models.INODO_DIRECTORIO {
+			dataType = "carpeta"
+		}
+
+		blockType := ""
+		for j, ptr := range inodo.I_block {
+			switch {
+			case ptr == Models.FREE_BLOCK:
+				continue
+			case j == Models.DIR_INDEX_BLOCK && System.HasDirectoryIndex(inodo):
+				if ptr == target {
+					blockType = "indice"
+				}
+			case j < 12:
+				if ptr == target {
+					blockType = dataType
+				}
+			default:
+				// I_block[12] es indirecto simple, [13] doble y [14] triple
+				blockType = ps.findInPointerBlock(ptr, j-11, target, dataType)
+			}
+			if blockType != "" {
+				return &inodeOwner{number: i, inodo: inodo}, blockType
+			}
+		}
+	}
+
+	return nil, ""
+}
+
+// findInPointerBlock busca target en el árbol que cuelga del bloque de apuntadores block
+// de nivel level (1 = apunta a datos)
+func (ps *partitionStructures) findInPointerBlock(block int32, level int, target int32, dataType string) string {
+	if block == target {
+		return "apuntadores"
+	}
+	pointers := ps.readPointerBlock(block)
+	if pointers == nil {
+		return ""
+	}
+	for _, ptr := range pointers.B_pointers {
+		if ptr == Models.FREE_BLOCK {
+			continue
+		}
+		if level == 1 {
+			if ptr == target {
+				return dataType
+			}
+			continue
+		}
+		if blockType := ps.findInPointerBlock(ptr, level-1, target, dataType); blockType != "" {
+			return blockType
+		}
+	}
+	return ""
+}
+
+// inodeToJSON convierte un inodo a su representación JSON
+func inodeToJSON(number int, inodo *Models.Inodo) InodeJSON {
+	inodeType := "archivo"
+	if inodo.I_type == Models.INODO_DIRECTORIO {
+		inodeType = "carpeta"
+	}
+
+	perm := Models.GetPermissions(inodo.I_perm)
+	permString := formatPermissions(perm)
+	if inodo.I_type == Models.INODO_DIRECTORIO {
+		permString = "d" + permString[1:]
+	}
+
+	return InodeJSON{
+		Number:      number,
+		UID:         inodo.I_uid,
+		GID:         inodo.I_gid,
+		Size:        inodo.I_s,
+		ATime:       formatJSONTime(inodo.I_atime),
+		CTime:       formatJSONTime(inodo.I_ctime),
+		MTime:       formatJSONTime(inodo.I_mtime),
+		Blocks:      inodo.I_block[:],
+		Type:        inodeType,
+		Permissions: fmt.Sprintf("%03d", perm),
+		PermString:  permString,
+	}
+}
+
+// formatJSONTime convierte un timestamp Unix a formato legible (vacío si no está definido)
+func formatJSONTime(timestamp float64) string {
+	if timestamp <= 0 {
+		return ""
+	}
+	return time.Unix(int64(timestamp), 0).Format("2006-01-02 15:04:05")
+}
+
+// countBitmapBits cuenta los bits ocupados dentro de los primeros total elementos
+func countBitmapBits(bitmap []byte, total int) int {
+	used := 0
+	for i := 0; i < total; i++ {
+		if Models.IsBitmapBitSet(bitmap, i) {
+			used++
+		}
+	}
+	return used
+}
+
+// byteToString convierte un campo de un byte a texto (vacío si no está definido)
+func byteToString(value byte) string {
+	if value == 0 {
+		return ""
+	}
+	return string(value)
+}
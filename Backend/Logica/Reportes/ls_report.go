@@ -1,7 +1,9 @@
 package Reportes
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Reportes/Graphviz"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
@@ -14,18 +16,18 @@ import (
 
 // LsEntry representa una entrada de directorio para el reporte ls
 type LsEntry struct {
-	Permissions string
-	Owner       string
-	Group       string
-	Size        int32
-	Date        string
-	Time        string
-	Type        string
-	Name        string
+	Permissions string `json:"permissions"`
+	Owner       string `json:"owner"`
+	Group       string `json:"group"`
+	Size        int32  `json:"size"`
+	Date        string `json:"date"`
+	Time        string `json:"time"`
+	Type        string `json:"type"`
+	Name        string `json:"name"`
 }
 
 // GenerateLsReport genera un reporte de archivos y carpetas en formato JPG usando Graphviz
-func GenerateLsReport(partitionID string, outputPath string, pathFileLS string) error {
+func GenerateLsReport(partitionID string, outputPath string, pathFileLS string, renderer string) error {
 	// Validar que se proporcione la ruta del directorio
 	if pathFileLS == "" {
 		pathFileLS = "/" // Por defecto, mostrar raíz
@@ -39,7 +41,7 @@ func GenerateLsReport(partitionID string, outputPath string, pathFileLS string)
 
 	// Verificar que el disco existe
 	diskPath := mountedPartition.DiskPath
-	if _, err := os.Stat(diskPath); os.IsNotExist(err) {
+	if !Device.Exists(diskPath) {
 		return fmt.Errorf("el disco '%s' no existe", diskPath)
 	}
 
@@ -107,12 +109,12 @@ func GenerateLsReport(partitionID string, outputPath string, pathFileLS string)
 	}
 
 	// Generar el reporte usando Graphviz
-	err = Graphviz.GenerateLsGraph(permissions, owners, groups, sizes, dates, times, types, names, diskName, pathFileLS, outputPath)
+	err = Graphviz.GenerateLsGraph(permissions, owners, groups, sizes, dates, times, types, names, diskName, pathFileLS, outputPath, renderer)
 	if err != nil {
 		return fmt.Errorf("error generando reporte ls: %v", err)
 	}
 
-	fmt.Println("Reporte ls generado exitosamente")
+	Output.Println("Reporte ls generado exitosamente")
 	return nil
 }
 
@@ -0,0 +1,126 @@
+package Reportes
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/Reportes/Graphviz"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// OrphanEntry representa un archivo o carpeta cuyo propietario o grupo ya no existe
+type OrphanEntry struct {
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	UID    int32  `json:"uid"`
+	GID    int32  `json:"gid"`
+	Owner  string `json:"owner"`
+	Group  string `json:"group"`
+	Reason string `json:"reason"`
+}
+
+// GetOrphanedEntries recorre la partición y retorna las entradas cuyo I_uid o I_gid
+// no corresponde a un usuario o grupo activo de users.txt (inexistente o eliminado)
+func GetOrphanedEntries(partitionID string) ([]OrphanEntry, error) {
+	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
+	if mountedPartition == nil {
+		return nil, fmt.Errorf("particion con ID '%s' no encontrada o no montada", partitionID)
+	}
+
+	partition, superBlock, err := Users.GetPartitionAndSuperBlock(mountedPartition)
+	if err != nil {
+		return nil, fmt.Errorf("error obteniendo superbloque: %v", err)
+	}
+
+	records, err := Users.NewUserManager(mountedPartition.DiskPath, partition, superBlock).ReadUsersFile()
+	if err != nil {
+		return nil, err
+	}
+
+	// Los registros eliminados tienen ID 0, por lo que su ID original ya no aparece
+	users := make(map[int32]string)
+	groups := make(map[int32]string)
+	for _, record := range records {
+		if record.ID == 0 {
+			continue
+		}
+		if record.Type == "G" {
+			groups[int32(record.ID)] = record.Group
+		} else {
+			users[int32(record.ID)] = record.Username
+		}
+	}
+
+	ext2Manager, err := newReportEXT2Manager(partitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]OrphanEntry, 0)
+	err = System.NewEXT2DirectoryManager(ext2Manager).Walk("/", func(path string, entry System.DirectoryEntry) {
+		owner, hasOwner := users[entry.UID]
+		group, hasGroup := groups[entry.GID]
+		if hasOwner && hasGroup {
+			return
+		}
+
+		reason := "usuario y grupo inexistentes"
+		if hasOwner {
+			reason = "grupo inexistente"
+		} else if hasGroup {
+			reason = "usuario inexistente"
+		}
+		if !hasOwner {
+			owner = fmt.Sprintf("uid %d", entry.UID)
+		}
+		if !hasGroup {
+			group = fmt.Sprintf("gid %d", entry.GID)
+		}
+
+		entries = append(entries, OrphanEntry{
+			Path:   path,
+			Type:   getFileTypeString(int32(entry.Type)),
+			UID:    entry.UID,
+			GID:    entry.GID,
+			Owner:  owner,
+			Group:  group,
+			Reason: reason,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error recorriendo el sistema de archivos: %v", err)
+	}
+
+	return entries, nil
+}
+
+// GenerateOrphanReport genera el reporte de archivos con propietario o grupo inexistente
+func GenerateOrphanReport(partitionID string, outputPath string, renderer string) error {
+	entries, err := GetOrphanedEntries(partitionID)
+	if err != nil {
+		return err
+	}
+
+	// Crear directorio de salida si no existe
+	outputDir := filepath.Dir(outputPath)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("error creando directorio de salida: %v", err)
+	}
+
+	rows := make([][]string, len(entries))
+	for i, entry := range entries {
+		rows[i] = []string{entry.Path, entry.Type, entry.Owner, entry.Group, entry.Reason}
+	}
+
+	diskName := filepath.Base(Disk.GetMountedPartitionByID(partitionID).DiskPath)
+	err = Graphviz.GenerateOrphanGraph(rows, diskName, outputPath, renderer)
+	if err != nil {
+		return fmt.Errorf("error generando reporte de huérfanos: %v", err)
+	}
+
+	Output.Println("Reporte de archivos huérfanos generado exitosamente")
+	return nil
+}
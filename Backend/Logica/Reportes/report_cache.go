@@ -0,0 +1,130 @@
+package Reportes
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Utils"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// renderedReport guarda el resultado de un reporte junto con la versión de la partición
+type renderedReport struct {
+	version     string
+	data        []byte
+	contentType string
+}
+
+var (
+	reportCache      = make(map[string]*renderedReport)
+	reportCacheMutex sync.Mutex
+)
+
+// reportContentTypes relaciona los formatos soportados con su tipo de contenido
+var reportContentTypes = map[string]string{
+	"svg": "image/svg+xml",
+	"png": "image/png",
+}
+
+// RenderReport genera un reporte en un directorio temporal y retorna su contenido.
+// El resultado se guarda en caché hasta que cambie S_mtime de la partición.
+func RenderReport(reportType string, partitionID string, pathFileLS string, format string) ([]byte, string, error) {
+	format = strings.ToLower(format)
+	if format == "" {
+		format = "svg"
+	}
+	contentType, ok := reportContentTypes[format]
+	if !ok {
+		return nil, "", fmt.Errorf("formato '%s' no soportado (svg o png)", format)
+	}
+
+	version, err := partitionVersion(reportType, partitionID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := strings.Join([]string{reportType, partitionID, pathFileLS, format}, "|")
+
+	reportCacheMutex.Lock()
+	defer reportCacheMutex.Unlock()
+
+	if cached, exists := reportCache[key]; exists && cached.version == version {
+		return cached.data, cached.contentType, nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "mia_report_*")
+	if err != nil {
+		return nil, "", fmt.Errorf("error creando directorio temporal: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	outputPath := filepath.Join(tempDir, "reporte."+format)
+	options := map[string]string{"path_file_ls": pathFileLS}
+
+	factory := &ReportFactory{}
+	generator, err := factory.CreateReport(ReportType(reportType), format, outputPath, options)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := generator.ValidateParameters(); err != nil {
+		return nil, "", err
+	}
+	if err := generator.Generate(partitionID, outputPath); err != nil {
+		return nil, "", err
+	}
+
+	// Sin Graphviz el reporte se renderiza en SVG aunque se pida PNG
+	data, err := os.ReadFile(outputPath)
+	if os.IsNotExist(err) {
+		data, err = os.ReadFile(Utils.GetSVGOutputPath(outputPath))
+		contentType = reportContentTypes["svg"]
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("error leyendo reporte generado: %v", err)
+	}
+
+	reportCache[key] = &renderedReport{version: version, data: data, contentType: contentType}
+	return data, contentType, nil
+}
+
+// partitionVersion identifica el estado de la partición con la ruta del disco, el inicio
+// de la partición y S_mtime del superbloque en disco, que cambia con cada escritura pero
+// no con I_atime. Los reportes de disco también dependen de la fecha de modificación del
+// archivo .mia.
+func partitionVersion(reportType string, partitionID string) (string, error) {
+	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
+	if mountedPartition == nil {
+		return "", fmt.Errorf("particion con ID '%s' no encontrada o no montada", partitionID)
+	}
+
+	if !Device.Exists(mountedPartition.DiskPath) {
+		return "", fmt.Errorf("el disco '%s' no existe", mountedPartition.DiskPath)
+	}
+
+	// Los discos en memoria no tienen fecha de modificación: sus reportes de disco no se cachean
+	diskVersion := time.Now().UnixNano()
+	if info, err := os.Stat(mountedPartition.DiskPath); err == nil {
+		diskVersion = info.ModTime().UnixNano()
+	}
+
+	// Una partición sin formatear no tiene superbloque; mkfs escribe un S_mtime nuevo
+	var partStart int64
+	var mtime float64
+	if partition, superBloque, err := Users.GetPartitionAndSuperBlock(mountedPartition); err == nil {
+		partStart = partition.PartStart
+		mtime = superBloque.S_mtime
+	}
+	version := fmt.Sprintf("%s|%d|%v", mountedPartition.DiskPath, partStart, mtime)
+
+	switch reportType {
+	case "mbr", "disk", "ebr":
+		return fmt.Sprintf("%s|%d", version, diskVersion), nil
+	default:
+		return version, nil
+	}
+}
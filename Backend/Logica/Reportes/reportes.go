@@ -25,6 +25,9 @@ const (
 	ReportTypeSuperBlock ReportType = "sb"
 	ReportTypeFile       ReportType = "file"
 	ReportTypeLs         ReportType = "ls"
+	ReportTypeBmInode    ReportType = "bm_inode"
+	ReportTypeBmBlock    ReportType = "bm_block"
+	ReportTypeOrphans    ReportType = "orphans"
 )
 
 // ReportFactory crea instancias de generadores de reportes
@@ -40,6 +43,8 @@ func (rf *ReportFactory) CreateReport(reportType ReportType, format string, outp
 			format = "jpg"
 		case ".png":
 			format = "png"
+		case ".svg":
+			format = "svg"
 		default:
 			format = "jpg" // Por defecto
 		}
@@ -60,6 +65,10 @@ func (rf *ReportFactory) CreateReport(reportType ReportType, format string, outp
 		return rf.createFileReport(format, outputPath, options)
 	case ReportTypeLs:
 		return rf.createLsReport(format, outputPath, options)
+	case ReportTypeBmInode, ReportTypeBmBlock:
+		return &ExistingBitmapReportGenerator{outputPath: outputPath, bitmapType: string(reportType), renderer: options["renderer"]}, nil
+	case ReportTypeOrphans:
+		return &ExistingOrphanReportGenerator{outputPath: outputPath, renderer: options["renderer"]}, nil
 	default:
 		return nil, fmt.Errorf("tipo de reporte no soportado: %s", reportType)
 	}
@@ -72,7 +81,7 @@ func (rf *ReportFactory) createInodeReport(format, outputPath string, options ma
 		if viewType == "" {
 			viewType = Graphviz.ViewTypeStructure // Vista por defecto
 		}
-		return Graphviz.NewInodeGraphGenerator("", outputPath, format, viewType), nil
+		return Graphviz.NewInodeGraphGenerator("", outputPath, format, viewType, options["renderer"]), nil
 	}
 
 	// TODO: Implementar reporte de texto plano
@@ -82,42 +91,42 @@ func (rf *ReportFactory) createInodeReport(format, outputPath string, options ma
 // createDiskReport crea un generador de reporte de disco
 func (rf *ReportFactory) createDiskReport(format, outputPath string, options map[string]string) (ReportGenerator, error) {
 	// Usar el generador existente
-	return &ExistingDiskReportGenerator{outputPath: outputPath}, nil
+	return &ExistingDiskReportGenerator{outputPath: outputPath, renderer: options["renderer"]}, nil
 }
 
 // createMBRReport crea un generador de reporte de MBR
 func (rf *ReportFactory) createMBRReport(format, outputPath string, options map[string]string) (ReportGenerator, error) {
 	// Usar el generador existente
-	return &ExistingMBRReportGenerator{outputPath: outputPath}, nil
+	return &ExistingMBRReportGenerator{outputPath: outputPath, renderer: options["renderer"]}, nil
 }
 
 // createEBRReport crea un generador de reporte de EBR
 func (rf *ReportFactory) createEBRReport(format, outputPath string, options map[string]string) (ReportGenerator, error) {
 	// Usar el generador existente
-	return &ExistingEBRReportGenerator{outputPath: outputPath}, nil
+	return &ExistingEBRReportGenerator{outputPath: outputPath, renderer: options["renderer"]}, nil
 }
 
 // createSuperBlockReport crea un generador de reporte de superbloque
 func (rf *ReportFactory) createSuperBlockReport(format, outputPath string, options map[string]string) (ReportGenerator, error) {
 	// Usar el generador existente
-	return &ExistingSuperBlockReportGenerator{outputPath: outputPath}, nil
+	return &ExistingSuperBlockReportGenerator{outputPath: outputPath, renderer: options["renderer"]}, nil
 }
 
 // createFileReport crea un generador de reporte de archivo
 func (rf *ReportFactory) createFileReport(format, outputPath string, options map[string]string) (ReportGenerator, error) {
 	// Usar el generador existente
-	return &ExistingFileReportGenerator{outputPath: outputPath}, nil
+	return &ExistingFileReportGenerator{outputPath: outputPath, pathFileLS: options["path_file_ls"], renderer: options["renderer"]}, nil
 }
 
 // createLsReport crea un generador de reporte de listado de directorios
 func (rf *ReportFactory) createLsReport(format, outputPath string, options map[string]string) (ReportGenerator, error) {
 	// Usar el generador existente
-	return &ExistingLsReportGenerator{outputPath: outputPath}, nil
+	return &ExistingLsReportGenerator{outputPath: outputPath, pathFileLS: options["path_file_ls"], renderer: options["renderer"]}, nil
 }
 
 // isGraphvizFormat determina si el formato requiere Graphviz
 func (rf *ReportFactory) isGraphvizFormat(format string) bool {
-	graphvizFormats := []string{"jpg", "jpeg", "png"}
+	graphvizFormats := []string{"jpg", "jpeg", "png", "svg"}
 	for _, gf := range graphvizFormats {
 		if format == gf {
 			return true
@@ -127,29 +136,33 @@ func (rf *ReportFactory) isGraphvizFormat(format string) bool {
 }
 
 // GenerateReport es la función principal que enruta los reportes (mantener compatibilidad)
-func GenerateReport(reportName string, partitionID string, outputPath string, pathFileLS string) error {
+func GenerateReport(reportName string, partitionID string, outputPath string, pathFileLS string, renderer string) error {
 	switch reportName {
 	case "mbr":
-		return GenerateMBRReport(partitionID, outputPath)
+		return GenerateMBRReport(partitionID, outputPath, renderer)
 	case "disk":
-		return GenerateDiskReport(partitionID, outputPath)
+		return GenerateDiskReport(partitionID, outputPath, renderer)
 	case "ebr":
-		return GenerateEBRCompleteReport(partitionID, outputPath)
+		return GenerateEBRCompleteReport(partitionID, outputPath, renderer)
 	case "sb":
-		return GenerateSuperBlockReport(partitionID, outputPath)
+		return GenerateSuperBlockReport(partitionID, outputPath, renderer)
 	case "inode":
 		// Nueva funcionalidad de reporte de inodos
 		factory := &ReportFactory{}
-		options := make(map[string]string)
+		options := map[string]string{"renderer": renderer}
 		generator, err := factory.CreateReport(ReportTypeInode, "", outputPath, options)
 		if err != nil {
 			return err
 		}
 		return generator.Generate(partitionID, outputPath)
 	case "file":
-		return GenerateFileReport(partitionID, outputPath, pathFileLS)
+		return GenerateFileReport(partitionID, outputPath, pathFileLS, renderer)
 	case "ls":
-		return GenerateLsReport(partitionID, outputPath, pathFileLS)
+		return GenerateLsReport(partitionID, outputPath, pathFileLS, renderer)
+	case "bm_inode", "bm_block":
+		return GenerateBitmapReport(partitionID, outputPath, reportName, renderer)
+	case "orphans":
+		return GenerateOrphanReport(partitionID, outputPath, renderer)
 	default:
 		return fmt.Errorf("tipo de reporte '%s' no reconocido", reportName)
 	}
@@ -158,10 +171,11 @@ func GenerateReport(reportName string, partitionID string, outputPath string, pa
 // Adaptadores para los generadores existentes
 type ExistingDiskReportGenerator struct {
 	outputPath string
+	renderer   string
 }
 
 func (e *ExistingDiskReportGenerator) Generate(partitionID string, outputPath string) error {
-	return GenerateDiskReport(partitionID, outputPath)
+	return GenerateDiskReport(partitionID, outputPath, e.renderer)
 }
 
 func (e *ExistingDiskReportGenerator) ValidateParameters() error {
@@ -174,10 +188,11 @@ func (e *ExistingDiskReportGenerator) GetSupportedFormats() []string {
 
 type ExistingMBRReportGenerator struct {
 	outputPath string
+	renderer   string
 }
 
 func (e *ExistingMBRReportGenerator) Generate(partitionID string, outputPath string) error {
-	return GenerateMBRReport(partitionID, outputPath)
+	return GenerateMBRReport(partitionID, outputPath, e.renderer)
 }
 
 func (e *ExistingMBRReportGenerator) ValidateParameters() error {
@@ -190,10 +205,11 @@ func (e *ExistingMBRReportGenerator) GetSupportedFormats() []string {
 
 type ExistingEBRReportGenerator struct {
 	outputPath string
+	renderer   string
 }
 
 func (e *ExistingEBRReportGenerator) Generate(partitionID string, outputPath string) error {
-	return GenerateEBRCompleteReport(partitionID, outputPath)
+	return GenerateEBRCompleteReport(partitionID, outputPath, e.renderer)
 }
 
 func (e *ExistingEBRReportGenerator) ValidateParameters() error {
@@ -206,10 +222,11 @@ func (e *ExistingEBRReportGenerator) GetSupportedFormats() []string {
 
 type ExistingSuperBlockReportGenerator struct {
 	outputPath string
+	renderer   string
 }
 
 func (e *ExistingSuperBlockReportGenerator) Generate(partitionID string, outputPath string) error {
-	return GenerateSuperBlockReport(partitionID, outputPath)
+	return GenerateSuperBlockReport(partitionID, outputPath, e.renderer)
 }
 
 func (e *ExistingSuperBlockReportGenerator) ValidateParameters() error {
@@ -222,15 +239,18 @@ func (e *ExistingSuperBlockReportGenerator) GetSupportedFormats() []string {
 
 type ExistingFileReportGenerator struct {
 	outputPath string
+	pathFileLS string
+	renderer   string
 }
 
 func (e *ExistingFileReportGenerator) Generate(partitionID string, outputPath string) error {
-	// El reporte file requiere pathFileLS, pero aquí no lo tenemos disponible
-	// Este adaptador no se usa directamente, se maneja en GenerateReport
-	return fmt.Errorf("uso directo del adaptador File no soportado, usar GenerateReport")
+	return GenerateFileReport(partitionID, outputPath, e.pathFileLS, e.renderer)
 }
 
 func (e *ExistingFileReportGenerator) ValidateParameters() error {
+	if e.pathFileLS == "" {
+		return fmt.Errorf("debe especificar la ruta del archivo con -path_file_ls")
+	}
 	return nil
 }
 
@@ -240,12 +260,12 @@ func (e *ExistingFileReportGenerator) GetSupportedFormats() []string {
 
 type ExistingLsReportGenerator struct {
 	outputPath string
+	pathFileLS string
+	renderer   string
 }
 
 func (e *ExistingLsReportGenerator) Generate(partitionID string, outputPath string) error {
-	// El reporte ls requiere pathFileLS, pero aquí no lo tenemos disponible
-	// Este adaptador no se usa directamente, se maneja en GenerateReport
-	return fmt.Errorf("uso directo del adaptador Ls no soportado, usar GenerateReport")
+	return GenerateLsReport(partitionID, outputPath, e.pathFileLS, e.renderer)
 }
 
 func (e *ExistingLsReportGenerator) ValidateParameters() error {
@@ -254,4 +274,39 @@ func (e *ExistingLsReportGenerator) ValidateParameters() error {
 
 func (e *ExistingLsReportGenerator) GetSupportedFormats() []string {
 	return []string{"jpg"}
-}
\ No newline at end of file
+}
+
+type ExistingBitmapReportGenerator struct {
+	outputPath string
+	bitmapType string
+	renderer   string
+}
+
+func (e *ExistingBitmapReportGenerator) Generate(partitionID string, outputPath string) error {
+	return GenerateBitmapReport(partitionID, outputPath, e.bitmapType, e.renderer)
+}
+
+func (e *ExistingBitmapReportGenerator) ValidateParameters() error {
+	return nil
+}
+
+func (e *ExistingBitmapReportGenerator) GetSupportedFormats() []string {
+	return []string{"jpg", "png", "svg"}
+}
+
+type ExistingOrphanReportGenerator struct {
+	outputPath string
+	renderer   string
+}
+
+func (e *ExistingOrphanReportGenerator) Generate(partitionID string, outputPath string) error {
+	return GenerateOrphanReport(partitionID, outputPath, e.renderer)
+}
+
+func (e *ExistingOrphanReportGenerator) ValidateParameters() error {
+	return nil
+}
+
+func (e *ExistingOrphanReportGenerator) GetSupportedFormats() []string {
+	return []string{"jpg", "png", "svg"}
+}
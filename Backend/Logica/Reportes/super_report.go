@@ -1,7 +1,9 @@
 package Reportes
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Reportes/Graphviz"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"fmt"
@@ -10,7 +12,7 @@ import (
 )
 
 // GenerateSuperBlockReport genera un reporte del superbloque en formato JPG usando Graphviz
-func GenerateSuperBlockReport(partitionID string, outputPath string) error {
+func GenerateSuperBlockReport(partitionID string, outputPath string, renderer string) error {
 	// Buscar la partición montada por ID
 	mountedPartition := Disk.GetMountedPartitionByID(partitionID)
 	if mountedPartition == nil {
@@ -19,7 +21,7 @@ func GenerateSuperBlockReport(partitionID string, outputPath string) error {
 
 	// Verificar que el disco existe
 	diskPath := mountedPartition.DiskPath
-	if _, err := os.Stat(diskPath); os.IsNotExist(err) {
+	if !Device.Exists(diskPath) {
 		return fmt.Errorf("el disco '%s' no existe", diskPath)
 	}
 
@@ -39,11 +41,11 @@ func GenerateSuperBlockReport(partitionID string, outputPath string) error {
 	diskName := filepath.Base(diskPath)
 
 	// Generar el reporte usando Graphviz
-	err = Graphviz.GenerateSuperBlockGraph(superblock, diskName, outputPath)
+	err = Graphviz.GenerateSuperBlockGraph(superblock, diskName, outputPath, renderer)
 	if err != nil {
 		return fmt.Errorf("error generando reporte de superbloque: %v", err)
 	}
 
-	fmt.Println("Reporte generado exitosamente")
+	Output.Println("Reporte generado exitosamente")
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,180 @@
+package System
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Models"
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// Asignación de inodos y bloques.
+//
+// S_firts_ino y S_first_blo se mantienen como pistas: todo bit por debajo de ellas
+// está ocupado, así que la búsqueda empieza ahí y no desde el bit 0.
+// Si el superbloque tiene S_groups_count > 1, los inodos y bloques se reparten en
+// grupos (como ext2): un inodo nuevo se busca en el grupo de su carpeta padre y los
+// bloques de un archivo en el grupo de su inodo.
+
+// superBlockAllocHintsOffset es la posición de S_firts_ino (seguido de S_first_blo)
+// dentro del superbloque serializado (lo preceden nueve campos int32 y dos float64)
+const superBlockAllocHintsOffset = 9*4 + 2*8
+
+// Errores de asignación cuando la partición no tiene espacio
+var (
+	ErrNoFreeInodes  = errors.New("no hay inodos libres")
+	ErrNoFreeBlocks  = errors.New("no hay bloques libres")
+	ErrDirectoryFull = errors.New("no hay espacio en el directorio")
+)
+
+// IsOutOfSpace indica si err se debe a que no quedan inodos, bloques o entradas de carpeta
+func IsOutOfSpace(err error) bool {
+	return errors.Is(err, ErrNoFreeInodes) || errors.Is(err, ErrNoFreeBlocks) || errors.Is(err, ErrDirectoryFull)
+}
+
+// FindFreeInode busca un inodo libre en el grupo de nearInode (la carpeta padre)
+func (f *EXT2FileManager) FindFreeInode(nearInode int32) (int32, error) {
+	bitmap, err := f.readInodeBitmap()
+	if err != nil {
+		return -1, err
+	}
+
+	sb := f.manager.superBloque
+	start := f.groupStart(nearInode, sb.S_inodes_count)
+	freeIndex := findFreeBitWrapping(bitmap, int(sb.S_firts_ino), start, int(sb.S_inodes_count))
+	if freeIndex == -1 {
+		return -1, ErrNoFreeInodes
+	}
+
+	return int32(freeIndex), nil
+}
+
+// FindFreeBlock busca un bloque libre en el grupo del inodo que lo usará
+func (f *EXT2FileManager) FindFreeBlock(ownerInode int32) (int32, error) {
+	blocks, err := f.FindFreeBlocks(ownerInode, 1)
+	if err != nil {
+		return -1, err
+	}
+	return blocks[0], nil
+}
+
+// FindFreeBlocks busca count bloques libres para el inodo ownerInode. Primero intenta
+// un tramo contiguo; si no existe, retorna los primeros libres a partir del mismo punto.
+// Los bloques no quedan reservados hasta marcarlos con SetBlockUsed.
+func (f *EXT2FileManager) FindFreeBlocks(ownerInode int32, count int) ([]int32, error) {
+	bitmap, err := f.readBlockBitmap()
+	if err != nil {
+		return nil, err
+	}
+
+	sb := f.manager.superBloque
+	hint := int(sb.S_first_blo)
+	limit := int(sb.S_blocks_count)
+	start := f.groupStart(ownerInode, sb.S_blocks_count)
+	if start < hint {
+		start = hint
+	}
+
+	first := Models.FindFreeBitmapRun(bitmap, start, limit, count)
+	if first == -1 {
+		first = Models.FindFreeBitmapRun(bitmap, hint, start, count)
+	}
+	if first != -1 {
+		blocks := make([]int32, count)
+		for i := range blocks {
+			blocks[i] = int32(first + i)
+		}
+		return blocks, nil
+	}
+
+	// Sin tramo contiguo: tomar bloques sueltos
+	blocks := make([]int32, 0, count)
+	for _, from := range [][2]int{{start, limit}, {hint, start}} {
+		for position := from[0]; len(blocks) < count; position++ {
+			position = Models.FindFreeBitmapBitFrom(bitmap, position, from[1])
+			if position == -1 {
+				break
+			}
+			blocks = append(blocks, int32(position))
+		}
+	}
+	if len(blocks) < count {
+		return nil, ErrNoFreeBlocks
+	}
+	return blocks, nil
+}
+
+// SetInodeUsed marca un inodo como usado o libre manteniendo contadores y pistas del superbloque
+func (f *EXT2FileManager) SetInodeUsed(inodeNumber int32, used bool) error {
+	return f.updateInodeBitmap(inodeNumber, used)
+}
+
+// SetBlockUsed marca un bloque como usado o libre manteniendo contadores y pistas del superbloque
+func (f *EXT2FileManager) SetBlockUsed(blockNumber int32, used bool) error {
+	return f.updateBlockBitmap(blockNumber, used)
+}
+
+// groupStart retorna el primer índice del grupo al que pertenece nearInode dentro de
+// un bitmap de total bits. Sin grupos o con nearInode negativo retorna 0.
+func (f *EXT2FileManager) groupStart(nearInode int32, total int32) int {
+	sb := f.manager.superBloque
+	groups := sb.S_groups_count
+	if groups <= 1 || nearInode < 0 {
+		return 0
+	}
+
+	inodesPerGroup := (sb.S_inodes_count + groups - 1) / groups
+	perGroup := (total + groups - 1) / groups
+	group := nearInode / inodesPerGroup
+	return int(group * perGroup)
+}
+
+// findFreeBitWrapping busca desde start hasta limit y luego desde hint hasta start
+func findFreeBitWrapping(bitmap []byte, hint int, start int, limit int) int {
+	if start < hint {
+		start = hint
+	}
+	if freeIndex := Models.FindFreeBitmapBitFrom(bitmap, start, limit); freeIndex != -1 {
+		return freeIndex
+	}
+	return Models.FindFreeBitmapBitFrom(bitmap, hint, start)
+}
+
+// nextAllocHint calcula la nueva pista tras cambiar el bit position del bitmap
+func nextAllocHint(bitmap []byte, hint int32, position int32, used bool, total int32) int32 {
+	if !used {
+		if position < hint {
+			return position
+		}
+		return hint
+	}
+	if position != hint {
+		return hint
+	}
+	next := Models.FindFreeBitmapBitFrom(bitmap, int(position)+1, int(total))
+	if next == -1 {
+		return total
+	}
+	return int32(next)
+}
+
+// updateAllocHints guarda S_firts_ino y S_first_blo en disco y en memoria si cambiaron
+func (f *EXT2FileManager) updateAllocHints(firstInode int32, firstBlock int32) error {
+	sb := f.manager.superBloque
+	if sb.S_firts_ino == firstInode && sb.S_first_blo == firstBlock {
+		return nil
+	}
+	sb.S_firts_ino = firstInode
+	sb.S_first_blo = firstBlock
+
+	file, err := Device.OpenWrite(f.manager.diskPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, [2]int32{firstInode, firstBlock})
+	_, err = file.WriteAt(buffer.Bytes(), f.manager.partitionInfo.PartStart+superBlockAllocHintsOffset)
+	return err
+}
@@ -0,0 +1,181 @@
+package System
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Models"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// newAllocTestManager crea una partición vacía en memoria con inodesCount inodos y
+// groups grupos, con los bitmaps en cero y las pistas en el bit 0
+func newAllocTestManager(t *testing.T, name string, inodesCount int32, groups int32) *EXT2FileManager {
+	t.Helper()
+	sb := layoutFor(2, inodesCount)
+	sb.S_groups_count = groups
+	sb.S_firts_ino, sb.S_first_blo = 0, 0
+	sb.S_free_inodes_count, sb.S_free_blocks_count = sb.S_inodes_count, sb.S_blocks_count
+
+	size := int64(sb.S_block_start) + int64(sb.S_blocks_count)*Models.BLOQUE_SIZE
+	diskPath := "mem://pruebas-asignacion/" + name + ".mia"
+	device := Device.NewMemoryDevice(size)
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, sb)
+	device.WriteAt(buffer.Bytes(), 0)
+	Device.Register(diskPath, device)
+	t.Cleanup(func() { Device.Unregister(diskPath) })
+
+	return NewEXT2FileManager(&EXT2Manager{
+		diskPath:      diskPath,
+		partitionInfo: &Models.Partition{PartStart: 0, PartSize: size},
+		superBloque:   sb,
+	})
+}
+
+// diskSuperBlock lee el superbloque tal como quedó en el dispositivo
+func diskSuperBlock(t *testing.T, f *EXT2FileManager) Models.SuperBloque {
+	t.Helper()
+	file, err := Device.Open(f.manager.diskPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var sb Models.SuperBloque
+	if err := binary.Read(file, binary.LittleEndian, &sb); err != nil {
+		t.Fatal(err)
+	}
+	return sb
+}
+
+// useBlocks marca como usados los bloques de [from, to)
+func useBlocks(t *testing.T, f *EXT2FileManager, from int32, to int32) {
+	t.Helper()
+	for block := from; block < to; block++ {
+		if err := f.SetBlockUsed(block, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAllocHintsFollowUsedAndFreedBits(t *testing.T) {
+	f := newAllocTestManager(t, "pistas", 64, 1)
+
+	for inode := int32(0); inode < 5; inode++ {
+		if err := f.SetInodeUsed(inode, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	useBlocks(t, f, 0, 8)
+
+	sb := diskSuperBlock(t, f)
+	if sb.S_firts_ino != 5 || sb.S_first_blo != 8 {
+		t.Fatalf("pistas en disco = %d, %d; se esperaba 5, 8", sb.S_firts_ino, sb.S_first_blo)
+	}
+	if sb.S_free_inodes_count != 64-5 || sb.S_free_blocks_count != 3*64-8 {
+		t.Errorf("contadores libres = %d, %d", sb.S_free_inodes_count, sb.S_free_blocks_count)
+	}
+	if inode, err := f.FindFreeInode(-1); err != nil || inode != 5 {
+		t.Errorf("FindFreeInode = %d, %v; se esperaba 5", inode, err)
+	}
+
+	// Liberar por debajo de la pista la mueve hacia atrás; marcar un bit que no es la
+	// pista no la mueve
+	if err := f.SetInodeUsed(2, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetBlockUsed(3, false); err != nil {
+		t.Fatal(err)
+	}
+	useBlocks(t, f, 20, 21)
+	sb = diskSuperBlock(t, f)
+	if sb.S_firts_ino != 2 || sb.S_first_blo != 3 {
+		t.Fatalf("pistas después de liberar = %d, %d; se esperaba 2, 3", sb.S_firts_ino, sb.S_first_blo)
+	}
+	if inode, err := f.FindFreeInode(-1); err != nil || inode != 2 {
+		t.Errorf("FindFreeInode después de liberar = %d, %v; se esperaba 2", inode, err)
+	}
+	if block, err := f.FindFreeBlock(-1); err != nil || block != 3 {
+		t.Errorf("FindFreeBlock después de liberar = %d, %v; se esperaba 3", block, err)
+	}
+
+	// Ocupar la pista la mueve al siguiente libre
+	useBlocks(t, f, 3, 4)
+	if sb = diskSuperBlock(t, f); sb.S_first_blo != 8 {
+		t.Errorf("S_first_blo = %d; se esperaba 8", sb.S_first_blo)
+	}
+}
+
+func TestFindFreeBlocksPrefersContiguousRun(t *testing.T) {
+	f := newAllocTestManager(t, "tramos", 64, 1)
+	total := 3 * int32(64)
+
+	// Libres: 10, 12-14 y desde 16; se pide un tramo de 3
+	useBlocks(t, f, 0, 10)
+	useBlocks(t, f, 11, 12)
+	useBlocks(t, f, 15, 16)
+	blocks, err := f.FindFreeBlocks(-1, 3)
+	if err != nil || len(blocks) != 3 || blocks[0] != 12 || blocks[2] != 14 {
+		t.Errorf("FindFreeBlocks(3) = %v, %v; se esperaba el tramo 12-14", blocks, err)
+	}
+
+	// Sin tramo contiguo se toman bloques sueltos desde la pista
+	useBlocks(t, f, 12, 14)
+	for block := int32(16); block < total; block++ {
+		if block != 40 {
+			useBlocks(t, f, block, block+1)
+		}
+	}
+	blocks, err = f.FindFreeBlocks(-1, 3)
+	if err != nil || len(blocks) != 3 || blocks[0] != 10 || blocks[1] != 14 || blocks[2] != 40 {
+		t.Errorf("FindFreeBlocks(3) fragmentado = %v, %v; se esperaba [10 14 40]", blocks, err)
+	}
+
+	// Sin suficientes libres el error es de falta de espacio
+	_, err = f.FindFreeBlocks(-1, 4)
+	if !errors.Is(err, ErrNoFreeBlocks) || !IsOutOfSpace(err) {
+		t.Errorf("FindFreeBlocks(4) con 3 libres = %v; se esperaba ErrNoFreeBlocks", err)
+	}
+}
+
+func TestFindFreeInodeWithoutFreeInodes(t *testing.T) {
+	f := newAllocTestManager(t, "sin-inodos", 16, 1)
+	for inode := int32(0); inode < 16; inode++ {
+		if err := f.SetInodeUsed(inode, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sb := diskSuperBlock(t, f); sb.S_firts_ino != 16 || sb.S_free_inodes_count != 0 {
+		t.Errorf("pista y contador con la tabla llena = %d, %d", sb.S_firts_ino, sb.S_free_inodes_count)
+	}
+	if _, err := f.FindFreeInode(3); !errors.Is(err, ErrNoFreeInodes) {
+		t.Errorf("FindFreeInode con la tabla llena = %v; se esperaba ErrNoFreeInodes", err)
+	}
+}
+
+func TestGroupsPlaceNearOwner(t *testing.T) {
+	f := newAllocTestManager(t, "grupos", 64, 4)
+	useBlocks(t, f, 0, 2)
+
+	// 64 inodos y 192 bloques en 4 grupos: 16 inodos y 48 bloques por grupo
+	if inode, err := f.FindFreeInode(40); err != nil || inode != 32 {
+		t.Errorf("FindFreeInode(40) = %d, %v; se esperaba 32 (grupo 2)", inode, err)
+	}
+	if block, err := f.FindFreeBlock(40); err != nil || block != 96 {
+		t.Errorf("FindFreeBlock(40) = %d, %v; se esperaba 96 (grupo 2)", block, err)
+	}
+	if block, err := f.FindFreeBlock(-1); err != nil || block != 2 {
+		t.Errorf("FindFreeBlock(-1) = %d, %v; se esperaba la pista 2", block, err)
+	}
+
+	// Con los grupos 2 y 3 llenos se vuelve a buscar desde la pista
+	useBlocks(t, f, 96, 192)
+	if block, err := f.FindFreeBlock(40); err != nil || block != 2 {
+		t.Errorf("FindFreeBlock(40) con el grupo lleno = %d, %v; se esperaba 2", block, err)
+	}
+	blocks, err := f.FindFreeBlocks(40, 3)
+	if err != nil || blocks[0] != 2 || blocks[2] != 4 {
+		t.Errorf("FindFreeBlocks(40, 3) con el grupo lleno = %v, %v; se esperaba 2-4", blocks, err)
+	}
+}
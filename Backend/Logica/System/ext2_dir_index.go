@@ -0,0 +1,207 @@
+package System
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Models"
+	"bytes"
+	"encoding/binary"
+	"strings"
+)
+
+// Índice de hash de carpetas.
+//
+// Si el superbloque tiene S_dir_index = 1, cada carpeta nueva recibe un BloqueIndice
+// en I_block[DIR_INDEX_BLOCK]. Para buscar un nombre se lee el índice y solo los bloques
+// marcados en su cubeta, en lugar de todos los bloques de la carpeta. El bit de un bloque
+// es su posición módulo 32 (Models.DirIndexBit), así el índice cubre también los bloques
+// indirectos. Un bit de más solo cuesta una lectura extra; al quitar una entrada el bit
+// se limpia solo si ningún bloque que lo comparte tiene otro nombre de la misma cubeta.
+// Las carpetas sin índice (imágenes anteriores) se recorren completas como antes.
+
+// HasDirectoryIndex indica si la carpeta tiene bloque de índice
+func HasDirectoryIndex(dirInodo *Models.Inodo) bool {
+	return dirInodo.I_type == Models.INODO_DIRECTORIO && dirInodo.I_block[Models.DIR_INDEX_BLOCK] != Models.FREE_BLOCK
+}
+
+// FindInDirectory busca una entrada en la carpeta usando su índice si lo tiene
+func (f *EXT2FileManager) FindInDirectory(dirInodo *Models.Inodo, filename string) (int32, error) {
+	return f.findInDirectory(dirInodo, filename)
+}
+
+// CreateDirectoryIndex crea el índice de la carpeta con sus entradas actuales.
+// No hace nada si el sistema no usa índices o la carpeta ya tiene uno.
+func (f *EXT2FileManager) CreateDirectoryIndex(dirInodeNum int32, dirInodo *Models.Inodo) error {
+	if f.manager.superBloque.S_dir_index != 1 || HasDirectoryIndex(dirInodo) {
+		return nil
+	}
+
+	var index Models.BloqueIndice
+	for slot, block := range f.DirectoryBlocks(dirInodo) {
+		dirBlock, err := f.readDirectoryBlock(block)
+		if err != nil {
+			return err
+		}
+		for _, entry := range dirBlock.B_content {
+			if entry.B_inodo != Models.FREE_INODE {
+				index.B_buckets[Models.DirIndexBucket(entryName(entry))] |= Models.DirIndexBit(slot)
+			}
+		}
+	}
+
+	blockNum, err := f.FindFreeBlock(dirInodeNum)
+	if err != nil {
+		return err
+	}
+	if err := f.SetBlockUsed(blockNum, true); err != nil {
+		return err
+	}
+	if err := f.writeDirectoryIndex(blockNum, &index); err != nil {
+		return err
+	}
+
+	dirInodo.I_block[Models.DIR_INDEX_BLOCK] = blockNum
+	return f.writeInode(dirInodeNum, dirInodo)
+}
+
+// indexDirectoryEntry registra en el índice que el bloque slot de la carpeta contiene name
+func (f *EXT2FileManager) indexDirectoryEntry(dirInodo *Models.Inodo, slot int, name string) error {
+	if !HasDirectoryIndex(dirInodo) {
+		return nil
+	}
+
+	index, err := f.readDirectoryIndex(dirInodo.I_block[Models.DIR_INDEX_BLOCK])
+	if err != nil {
+		return err
+	}
+	bucket := Models.DirIndexBucket(name)
+	if index.B_buckets[bucket]&Models.DirIndexBit(slot) != 0 {
+		return nil
+	}
+	index.B_buckets[bucket] |= Models.DirIndexBit(slot)
+	return f.writeDirectoryIndex(dirInodo.I_block[Models.DIR_INDEX_BLOCK], index)
+}
+
+// unindexDirectoryEntry actualiza el índice después de quitar name del bloque slot.
+// dirBlock es el contenido del bloque ya sin la entrada.
+func (f *EXT2FileManager) unindexDirectoryEntry(dirInodo *Models.Inodo, slot int, dirBlock *Models.BloqueCarpeta, name string) error {
+	if !HasDirectoryIndex(dirInodo) {
+		return nil
+	}
+
+	bucket := Models.DirIndexBucket(name)
+	if hasBucketEntry(dirBlock, bucket) {
+		return nil
+	}
+	// Los demás bloques que comparten el bit
+	for other, block := range f.DirectoryBlocks(dirInodo) {
+		if other == slot || Models.DirIndexBit(other) != Models.DirIndexBit(slot) {
+			continue
+		}
+		otherBlock, err := f.readDirectoryBlock(block)
+		if err != nil || hasBucketEntry(otherBlock, bucket) {
+			return err
+		}
+	}
+
+	index, err := f.readDirectoryIndex(dirInodo.I_block[Models.DIR_INDEX_BLOCK])
+	if err != nil {
+		return err
+	}
+	index.B_buckets[bucket] &^= Models.DirIndexBit(slot)
+	return f.writeDirectoryIndex(dirInodo.I_block[Models.DIR_INDEX_BLOCK], index)
+}
+
+// FreeDirectoryIndex libera el bloque de índice de una carpeta que se elimina
+func (f *EXT2FileManager) FreeDirectoryIndex(dirInodo *Models.Inodo) error {
+	if !HasDirectoryIndex(dirInodo) {
+		return nil
+	}
+	err := f.SetBlockUsed(dirInodo.I_block[Models.DIR_INDEX_BLOCK], false)
+	dirInodo.I_block[Models.DIR_INDEX_BLOCK] = Models.FREE_BLOCK
+	return err
+}
+
+// findInIndexedDirectory busca name leyendo solo los bloques de su cubeta
+func (f *EXT2FileManager) findInIndexedDirectory(dirInodo *Models.Inodo, filename string) (int32, error) {
+	index, err := f.readDirectoryIndex(dirInodo.I_block[Models.DIR_INDEX_BLOCK])
+	if err != nil {
+		return -1, err
+	}
+
+	mask := index.B_buckets[Models.DirIndexBucket(filename)]
+	if mask == 0 {
+		return -1, errEntryNotFound
+	}
+	for slot, block := range f.DirectoryBlocks(dirInodo) {
+		if mask&Models.DirIndexBit(slot) == 0 {
+			continue
+		}
+
+		dirBlock, err := f.readDirectoryBlock(block)
+		if err != nil {
+			return -1, err
+		}
+		for _, entry := range dirBlock.B_content {
+			if entry.B_inodo != Models.FREE_INODE && entryName(entry) == filename {
+				return entry.B_inodo, nil
+			}
+		}
+	}
+
+	return -1, errEntryNotFound
+}
+
+func (f *EXT2FileManager) readDirectoryIndex(blockNumber int32) (*Models.BloqueIndice, error) {
+	file, err := Device.Open(f.manager.diskPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blockPos := f.manager.partitionInfo.PartStart + int64(f.manager.superBloque.S_block_start) + int64(blockNumber*Models.BLOQUE_SIZE)
+	_, err = file.Seek(blockPos, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var index Models.BloqueIndice
+	err = binary.Read(file, binary.LittleEndian, &index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &index, nil
+}
+
+func (f *EXT2FileManager) writeDirectoryIndex(blockNumber int32, index *Models.BloqueIndice) error {
+	file, err := Device.OpenWrite(f.manager.diskPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	buffer := new(bytes.Buffer)
+	err = binary.Write(buffer, binary.LittleEndian, index)
+	if err != nil {
+		return err
+	}
+
+	blockPos := f.manager.partitionInfo.PartStart + int64(f.manager.superBloque.S_block_start) + int64(blockNumber*Models.BLOQUE_SIZE)
+	_, err = file.WriteAt(buffer.Bytes(), blockPos)
+	return err
+}
+
+// hasBucketEntry indica si el bloque tiene algún nombre de la cubeta bucket
+func hasBucketEntry(dirBlock *Models.BloqueCarpeta, bucket int) bool {
+	for _, entry := range dirBlock.B_content {
+		if entry.B_inodo != Models.FREE_INODE && Models.DirIndexBucket(entryName(entry)) == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+// entryName retorna el nombre de una entrada de carpeta sin los bytes nulos
+func entryName(entry Models.B_content) string {
+	return strings.TrimRight(string(entry.B_name[:]), "\x00")
+}
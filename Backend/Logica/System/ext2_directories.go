@@ -3,6 +3,7 @@ package System
 import (
 	"MIA_2S2025_P1_202105668/Models"
 	"errors"
+	"path"
 	"strings"
 )
 
@@ -122,13 +123,9 @@ func (d *EXT2DirectoryManager) ListDirectory(dirPath string) ([]DirectoryEntry,
 
 	var entries []DirectoryEntry
 
-	// Recorrer los 12 bloques directos del directorio
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, err := d.fileManager.readDirectoryBlock(dirInodo.I_block[i])
+	// Recorrer los bloques del directorio, directos e indirectos
+	for _, block := range d.fileManager.DirectoryBlocks(dirInodo) {
+		dirBlock, err := d.fileManager.readDirectoryBlock(block)
 		if err != nil {
 			return nil, err
 		}
@@ -165,15 +162,59 @@ func (d *EXT2DirectoryManager) ListDirectory(dirPath string) ([]DirectoryEntry,
 	return entries, nil
 }
 
+// Walk recorre recursivamente el árbol desde dirPath llamando a visit con la ruta completa de cada entrada.
+// Las entradas "." y ".." se omiten y cada inodo se visita una sola vez.
+func (d *EXT2DirectoryManager) Walk(dirPath string, visit func(path string, entry DirectoryEntry)) error {
+	visited := make(map[int32]bool)
+	return d.walk(dirPath, visit, visited)
+}
+
+func (d *EXT2DirectoryManager) walk(dirPath string, visit func(path string, entry DirectoryEntry), visited map[int32]bool) error {
+	entries, err := d.ListDirectory(dirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.Name == "." || entry.Name == ".." || visited[entry.InodeNumber] {
+			continue
+		}
+		visited[entry.InodeNumber] = true
+
+		entryPath := strings.TrimSuffix(dirPath, "/") + "/" + entry.Name
+		visit(entryPath, entry)
+
+		if entry.Type == Models.INODO_DIRECTORIO {
+			if err := d.walk(entryPath, visit, visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // createNewDirectory crea directorio asignando inodo y bloque, inicializando con . y ..
 func (d *EXT2DirectoryManager) createNewDirectory(parentInodeNum int32, dirName string, uid int32, gid int32, permissions int32) error {
 	// Asignar inodo y bloque libre para el nuevo directorio
-	newInodeNum, err := d.fileManager.findFreeInode()
+	newInodeNum, err := d.fileManager.FindFreeInode(parentInodeNum)
 	if err != nil {
 		return err
 	}
 
-	newBlockNum, err := d.fileManager.findFreeBlock()
+	newBlockNum, err := d.fileManager.FindFreeBlock(newInodeNum)
+	if err != nil {
+		return err
+	}
+
+	// Reservar inodo y bloque antes de tocar el padre: si el padre necesita un bloque
+	// nuevo para la entrada, FindFreeBlock no debe devolver el mismo bloque
+	err = d.fileManager.markInodeAsUsed(newInodeNum)
+	if err != nil {
+		return err
+	}
+
+	err = d.fileManager.markBlockAsUsed(newBlockNum)
 	if err != nil {
 		return err
 	}
@@ -220,31 +261,17 @@ func (d *EXT2DirectoryManager) createNewDirectory(parentInodeNum int32, dirName
 		return err
 	}
 
-	err = d.fileManager.addEntryToDirectory(parentInodeNum, dirName, newInodeNum)
+	err = d.fileManager.CreateDirectoryIndex(newInodeNum, &newInodo)
 	if err != nil {
 		return err
 	}
 
-	err = d.fileManager.markInodeAsUsed(newInodeNum)
-	if err != nil {
-		return err
-	}
-
-	err = d.fileManager.markBlockAsUsed(newBlockNum)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return d.fileManager.addEntryToDirectory(parentInodeNum, dirName, newInodeNum)
 }
 
 func (d *EXT2DirectoryManager) isDirectoryEmpty(dirInodo *Models.Inodo) (bool, error) {
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, err := d.fileManager.readDirectoryBlock(dirInodo.I_block[i])
+	for _, block := range d.fileManager.DirectoryBlocks(dirInodo) {
+		dirBlock, err := d.fileManager.readDirectoryBlock(block)
 		if err != nil {
 			return false, err
 		}
@@ -263,18 +290,11 @@ func (d *EXT2DirectoryManager) isDirectoryEmpty(dirInodo *Models.Inodo) (bool, e
 }
 
 func (d *EXT2DirectoryManager) freeDirectoryBlocks(dirInodo *Models.Inodo) error {
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		err := d.fileManager.updateBlockBitmap(dirInodo.I_block[i], false)
-		if err != nil {
-			return err
-		}
+	if err := d.fileManager.FreeInodeBlocks(dirInodo); err != nil {
+		return err
 	}
 
-	return nil
+	return d.fileManager.FreeDirectoryIndex(dirInodo)
 }
 
 func (d *EXT2DirectoryManager) removeEntryFromParent(dirPath string, inodeNum int32) error {
@@ -290,12 +310,8 @@ func (d *EXT2DirectoryManager) removeEntryFromParent(dirPath string, inodeNum in
 		return err
 	}
 
-	for i := 0; i < 12; i++ {
-		if parentInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, err := d.fileManager.readDirectoryBlock(parentInodo.I_block[i])
+	for i, block := range d.fileManager.DirectoryBlocks(parentInodo) {
+		dirBlock, err := d.fileManager.readDirectoryBlock(block)
 		if err != nil {
 			return err
 		}
@@ -309,7 +325,11 @@ func (d *EXT2DirectoryManager) removeEntryFromParent(dirPath string, inodeNum in
 						dirBlock.B_content[j].B_name[k] = 0
 					}
 
-					return d.fileManager.writeDirectoryBlock(parentInodo.I_block[i], dirBlock)
+					err = d.fileManager.writeDirectoryBlock(block, dirBlock)
+					if err != nil {
+						return err
+					}
+					return d.fileManager.DirectoryEntryRemoved(parentInodeNum, parentInodo, i, dirBlock, dirName)
 				}
 			}
 		}
@@ -319,32 +339,17 @@ func (d *EXT2DirectoryManager) removeEntryFromParent(dirPath string, inodeNum in
 }
 
 // ChangeDirectory cambia directorio actual manejando rutas absolutas y relativas
+// (incluyendo componentes "." y "..") y valida que el destino sea un directorio
 func (d *EXT2DirectoryManager) ChangeDirectory(currentDir, targetDir string) (string, error) {
-	// Manejar rutas absolutas directamente
-	if strings.HasPrefix(targetDir, "/") {
-		_, err := d.fileManager.findFileInode(targetDir)
-		if err != nil {
-			return "", errors.New("directorio no encontrado")
-		}
-		return targetDir, nil
+	if currentDir == "" {
+		currentDir = "/"
 	}
 
 	var newPath string
-	if currentDir == "/" {
-		newPath = "/" + targetDir
+	if strings.HasPrefix(targetDir, "/") {
+		newPath = path.Clean(targetDir)
 	} else {
-		if targetDir == ".." {
-			parts := strings.Split(strings.Trim(currentDir, "/"), "/")
-			if len(parts) > 1 {
-				newPath = "/" + strings.Join(parts[:len(parts)-1], "/")
-			} else {
-				newPath = "/"
-			}
-		} else if targetDir == "." {
-			newPath = currentDir
-		} else {
-			newPath = strings.TrimSuffix(currentDir, "/") + "/" + targetDir
-		}
+		newPath = path.Join(currentDir, targetDir)
 	}
 
 	inodeNum, err := d.fileManager.findFileInode(newPath)
@@ -1,11 +1,12 @@
 package System
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Models"
 	"bytes"
 	"encoding/binary"
 	"errors"
-	"os"
+	"fmt"
 	"strings"
 )
 
@@ -85,50 +86,18 @@ func (f *EXT2FileManager) WriteFileContent(filePath string, content string, uid
 
 // findFileInode navega la jerarquia de directorios para encontrar un archivo
 func (f *EXT2FileManager) findFileInode(filePath string) (int32, error) {
-	// Caso especial: directorio raiz
-	if filePath == "/" {
-		return Models.ROOT_INODE, nil
-	}
-
-	// Dividir ruta en partes y empezar desde la raiz
-	pathParts := strings.Split(strings.Trim(filePath, "/"), "/")
-	currentInode := Models.ROOT_INODE
-
-	// Recorrer cada componente de la ruta
-	for _, part := range pathParts {
-		if part == "" {
-			continue
-		}
-
-		inodo, err := f.readInode(int32(currentInode))
-		if err != nil {
-			return -1, err
-		}
-
-		if inodo.I_type != Models.INODO_DIRECTORIO {
-			return -1, errors.New("no es un directorio")
-		}
-
-		nextInode, err := f.findInDirectory(inodo, part)
-		if err != nil {
-			return -1, err
-		}
-
-		currentInode = int(nextInode)
-	}
-
-	return int32(currentInode), nil
+	return f.LookupPath(filePath)
 }
 
 // findInDirectory busca un archivo especifico dentro de un directorio
 func (f *EXT2FileManager) findInDirectory(dirInodo *Models.Inodo, filename string) (int32, error) {
-	// Recorrer los 12 bloques directos del directorio
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
+	if HasDirectoryIndex(dirInodo) {
+		return f.findInIndexedDirectory(dirInodo, filename)
+	}
 
-		dirBlock, err := f.readDirectoryBlock(dirInodo.I_block[i])
+	// Recorrer los bloques del directorio, directos e indirectos
+	for _, block := range f.DirectoryBlocks(dirInodo) {
+		dirBlock, err := f.readDirectoryBlock(block)
 		if err != nil {
 			return -1, err
 		}
@@ -143,11 +112,11 @@ func (f *EXT2FileManager) findInDirectory(dirInodo *Models.Inodo, filename strin
 		}
 	}
 
-	return -1, errors.New("archivo no encontrado")
+	return -1, errEntryNotFound
 }
 
 func (f *EXT2FileManager) readInode(inodeNumber int32) (*Models.Inodo, error) {
-	file, err := os.Open(f.manager.diskPath)
+	file, err := Device.Open(f.manager.diskPath)
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +138,7 @@ func (f *EXT2FileManager) readInode(inodeNumber int32) (*Models.Inodo, error) {
 }
 
 func (f *EXT2FileManager) readDirectoryBlock(blockNumber int32) (*Models.BloqueCarpeta, error) {
-	file, err := os.Open(f.manager.diskPath)
+	file, err := Device.Open(f.manager.diskPath)
 	if err != nil {
 		return nil, err
 	}
@@ -195,19 +164,20 @@ func (f *EXT2FileManager) readInodeContent(inodo *Models.Inodo) ([]byte, error)
 	content := make([]byte, 0, inodo.I_s)
 	bytesRead := int32(0)
 
-	// Leer contenido de los 12 bloques directos
-	for i := 0; i < 12 && i < len(inodo.I_block); i++ {
-		if inodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
+	// Bloques directos e indirectos en orden
+	blocks, _, err := f.InodeBlocks(inodo)
+	if err != nil {
+		return nil, err
+	}
 
+	for _, blockNum := range blocks {
 		// Calcular cuántos bytes leer de este bloque
 		bytesRemaining := inodo.I_s - bytesRead
 		if bytesRemaining <= 0 {
 			break
 		}
 
-		blockContent, err := f.readFileBlock(inodo.I_block[i])
+		blockContent, err := f.readFileBlock(blockNum)
 		if err != nil {
 			return nil, err
 		}
@@ -220,18 +190,13 @@ func (f *EXT2FileManager) readInodeContent(inodo *Models.Inodo) ([]byte, error)
 
 		content = append(content, blockContent[:bytesToTake]...)
 		bytesRead += bytesToTake
-
-		// Si ya leímos todo el archivo, terminar
-		if bytesRead >= inodo.I_s {
-			break
-		}
 	}
 
 	return content, nil
 }
 
 func (f *EXT2FileManager) readFileBlock(blockNumber int32) ([]byte, error) {
-	file, err := os.Open(f.manager.diskPath)
+	file, err := Device.Open(f.manager.diskPath)
 	if err != nil {
 		return nil, err
 	}
@@ -252,20 +217,30 @@ func (f *EXT2FileManager) readFileBlock(blockNumber int32) ([]byte, error) {
 	return fileBlock.GetContent(), nil
 }
 
+// OverwriteFileContent reemplaza el contenido del archivo con ese número de inodo
+func (f *EXT2FileManager) OverwriteFileContent(inodeNumber int32, content string) error {
+	return f.overwriteFileContent(inodeNumber, content)
+}
+
 func (f *EXT2FileManager) overwriteFileContent(inodeNumber int32, content string) error {
 	inodo, err := f.readInode(inodeNumber)
 	if err != nil {
 		return err
 	}
 
+	// Validar el tamaño antes de liberar los bloques actuales
+	if len(content) > MaxFileBlocks*Models.BLOQUE_SIZE {
+		return fmt.Errorf("el contenido excede el tamaño máximo de un archivo (%d bytes)", MaxFileBlocks*Models.BLOQUE_SIZE)
+	}
+
 	// Liberar bloques antiguos
-	err = f.freeInodeBlocks(inodo)
+	err = f.FreeInodeBlocks(inodo)
 	if err != nil {
 		return err
 	}
 
 	// Escribir nuevo contenido con múltiples bloques
-	err = f.writeMultipleBlocks(inodo, []byte(content))
+	err = f.writeMultipleBlocks(inodeNumber, inodo, []byte(content))
 	if err != nil {
 		return err
 	}
@@ -281,7 +256,7 @@ func (f *EXT2FileManager) overwriteFileContent(inodeNumber int32, content string
 // createNewFile crea un archivo nuevo con inodo y múltiples bloques asignados
 func (f *EXT2FileManager) createNewFile(parentInodeNum int32, fileName string, content string, uid int32, gid int32, permissions int32) error {
 	// Asignar inodo libre
-	newInodeNum, err := f.findFreeInode()
+	newInodeNum, err := f.FindFreeInode(parentInodeNum)
 	if err != nil {
 		return err
 	}
@@ -304,7 +279,7 @@ func (f *EXT2FileManager) createNewFile(parentInodeNum int32, fileName string, c
 	}
 
 	// Escribir contenido usando múltiples bloques
-	err = f.writeMultipleBlocks(&newInodo, []byte(content))
+	err = f.writeMultipleBlocks(newInodeNum, &newInodo, []byte(content))
 	if err != nil {
 		return err
 	}
@@ -331,7 +306,7 @@ func (f *EXT2FileManager) createNewFile(parentInodeNum int32, fileName string, c
 }
 
 func (f *EXT2FileManager) writeFileBlock(blockNumber int32, content []byte) error {
-	file, err := os.OpenFile(f.manager.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(f.manager.diskPath)
 	if err != nil {
 		return err
 	}
@@ -357,7 +332,7 @@ func (f *EXT2FileManager) writeFileBlock(blockNumber int32, content []byte) erro
 }
 
 func (f *EXT2FileManager) writeInode(inodeNumber int32, inodo *Models.Inodo) error {
-	file, err := os.OpenFile(f.manager.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(f.manager.diskPath)
 	if err != nil {
 		return err
 	}
@@ -375,44 +350,39 @@ func (f *EXT2FileManager) writeInode(inodeNumber int32, inodo *Models.Inodo) err
 		return err
 	}
 
-	_, err = file.Write(buffer.Bytes())
-	return err
-}
-
-// findFreeInode busca el primer inodo libre usando bitmap
-func (f *EXT2FileManager) findFreeInode() (int32, error) {
-	bitmap, err := f.readInodeBitmap()
-	if err != nil {
-		return -1, err
-	}
-
-	// Buscar primer bit libre en el bitmap de inodos
-	freeIndex := Models.FindFreeBitmapBit(bitmap)
-	if freeIndex == -1 {
-		return -1, errors.New("no hay inodos libres")
+	if _, err = file.Write(buffer.Bytes()); err != nil {
+		return err
 	}
-
-	return int32(freeIndex), nil
+	return MarkPartitionModified(f.manager.diskPath, f.manager.partitionInfo.PartStart)
 }
 
-// findFreeBlock busca el primer bloque libre usando bitmap
-func (f *EXT2FileManager) findFreeBlock() (int32, error) {
-	bitmap, err := f.readBlockBitmap()
+// inodeATimeOffset es la posición de I_atime dentro del inodo serializado
+// (lo preceden I_uid, I_gid e I_s)
+const inodeATimeOffset = 3 * 4
+
+// TouchAccessTime actualiza en disco I_atime del archivo o carpeta con la hora actual.
+// Solo escribe ese campo: no cambia S_mtime.
+func (f *EXT2FileManager) TouchAccessTime(filePath string) error {
+	inodeNumber, err := f.LookupPath(filePath)
 	if err != nil {
-		return -1, err
+		return err
 	}
 
-	// Buscar primer bit libre en el bitmap de bloques
-	freeIndex := Models.FindFreeBitmapBit(bitmap)
-	if freeIndex == -1 {
-		return -1, errors.New("no hay bloques libres")
+	file, err := Device.OpenWrite(f.manager.diskPath)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	return int32(freeIndex), nil
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, float64(Models.GetCurrentUnixTime()))
+	inodoPos := f.manager.partitionInfo.PartStart + int64(f.manager.superBloque.S_inode_start) + int64(inodeNumber*Models.INODO_SIZE)
+	_, err = file.WriteAt(buffer.Bytes(), inodoPos+inodeATimeOffset)
+	return err
 }
 
 func (f *EXT2FileManager) readInodeBitmap() ([]byte, error) {
-	file, err := os.Open(f.manager.diskPath)
+	file, err := Device.Open(f.manager.diskPath)
 	if err != nil {
 		return nil, err
 	}
@@ -435,7 +405,7 @@ func (f *EXT2FileManager) readInodeBitmap() ([]byte, error) {
 }
 
 func (f *EXT2FileManager) readBlockBitmap() ([]byte, error) {
-	file, err := os.Open(f.manager.diskPath)
+	file, err := Device.Open(f.manager.diskPath)
 	if err != nil {
 		return nil, err
 	}
@@ -471,13 +441,26 @@ func (f *EXT2FileManager) updateInodeBitmap(inodeNumber int32, used bool) error
 		return err
 	}
 
+	wasUsed := Models.IsBitmapBitSet(bitmap, int(inodeNumber))
 	if used {
 		Models.SetBitmapBit(bitmap, int(inodeNumber))
 	} else {
 		Models.ClearBitmapBit(bitmap, int(inodeNumber))
 	}
 
-	return f.writeInodeBitmap(bitmap)
+	if err := f.writeInodeBitmap(bitmap); err != nil {
+		return err
+	}
+	if wasUsed == used {
+		return nil
+	}
+	f.resolver().forgetDirectory(inodeNumber)
+	sb := f.manager.superBloque
+	firstInode := nextAllocHint(bitmap, sb.S_firts_ino, inodeNumber, used, sb.S_inodes_count)
+	if err := f.updateAllocHints(firstInode, sb.S_first_blo); err != nil {
+		return err
+	}
+	return f.adjustFreeCounts(0, FreeCountDelta(used))
 }
 
 func (f *EXT2FileManager) updateBlockBitmap(blockNumber int32, used bool) error {
@@ -486,17 +469,42 @@ func (f *EXT2FileManager) updateBlockBitmap(blockNumber int32, used bool) error
 		return err
 	}
 
+	wasUsed := Models.IsBitmapBitSet(bitmap, int(blockNumber))
 	if used {
 		Models.SetBitmapBit(bitmap, int(blockNumber))
 	} else {
 		Models.ClearBitmapBit(bitmap, int(blockNumber))
 	}
 
-	return f.writeBlockBitmap(bitmap)
+	if err := f.writeBlockBitmap(bitmap); err != nil {
+		return err
+	}
+	if wasUsed == used {
+		return nil
+	}
+	sb := f.manager.superBloque
+	firstBlock := nextAllocHint(bitmap, sb.S_first_blo, blockNumber, used, sb.S_blocks_count)
+	if err := f.updateAllocHints(sb.S_firts_ino, firstBlock); err != nil {
+		return err
+	}
+	return f.adjustFreeCounts(FreeCountDelta(used), 0)
+}
+
+// adjustFreeCounts actualiza los contadores de libres del superbloque en disco y en memoria
+func (f *EXT2FileManager) adjustFreeCounts(blockDelta int32, inodeDelta int32) error {
+	file, err := Device.OpenWrite(f.manager.diskPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	f.manager.superBloque.S_free_blocks_count += blockDelta
+	f.manager.superBloque.S_free_inodes_count += inodeDelta
+	return AdjustSuperBlockFreeCounts(file, f.manager.partitionInfo.PartStart, blockDelta, inodeDelta)
 }
 
 func (f *EXT2FileManager) writeInodeBitmap(bitmap []byte) error {
-	file, err := os.OpenFile(f.manager.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(f.manager.diskPath)
 	if err != nil {
 		return err
 	}
@@ -513,7 +521,7 @@ func (f *EXT2FileManager) writeInodeBitmap(bitmap []byte) error {
 }
 
 func (f *EXT2FileManager) writeBlockBitmap(bitmap []byte) error {
-	file, err := os.OpenFile(f.manager.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(f.manager.diskPath)
 	if err != nil {
 		return err
 	}
@@ -529,18 +537,21 @@ func (f *EXT2FileManager) writeBlockBitmap(bitmap []byte) error {
 	return err
 }
 
+// AddDirectoryEntry agrega name a la carpeta dirInodeNum en el primer espacio libre o en
+// un bloque nuevo, que puede quedar en los indirectos
+func (f *EXT2FileManager) AddDirectoryEntry(dirInodeNum int32, name string, inodeNum int32) error {
+	return f.addEntryToDirectory(dirInodeNum, name, inodeNum)
+}
+
 func (f *EXT2FileManager) addEntryToDirectory(dirInodeNum int32, filename string, fileInodeNum int32) error {
 	dirInodo, err := f.readInode(dirInodeNum)
 	if err != nil {
 		return err
 	}
 
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, err := f.readDirectoryBlock(dirInodo.I_block[i])
+	blocks := f.DirectoryBlocks(dirInodo)
+	for i, block := range blocks {
+		dirBlock, err := f.readDirectoryBlock(block)
 		if err != nil {
 			return err
 		}
@@ -549,59 +560,62 @@ func (f *EXT2FileManager) addEntryToDirectory(dirInodeNum int32, filename string
 			if dirBlock.B_content[j].B_inodo == Models.FREE_INODE {
 				dirBlock.B_content[j].B_inodo = int32(fileInodeNum)
 				copy(dirBlock.B_content[j].B_name[:], filename)
-				return f.writeDirectoryBlock(dirInodo.I_block[i], dirBlock)
+				if err := f.writeDirectoryBlock(block, dirBlock); err != nil {
+					return err
+				}
+				return f.DirectoryEntryAdded(dirInodeNum, dirInodo, i, filename)
 			}
 		}
 	}
 
 	// Si no hay espacio en bloques existentes, crear un nuevo bloque
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			// Encontrar bloque libre
-			newBlockNum, err := f.findFreeBlock()
-			if err != nil {
-				return err
-			}
+	if len(blocks) >= MaxFileBlocks {
+		return ErrDirectoryFull
+	}
 
-			// Marcar bloque como usado en bitmap
-			bitmap, err := f.readBlockBitmap()
-			if err != nil {
-				return err
-			}
-			Models.SetBitmapBit(bitmap, int(newBlockNum))
-			err = f.writeBlockBitmap(bitmap)
-			if err != nil {
-				return err
-			}
+	// Encontrar bloque libre
+	newBlockNum, err := f.FindFreeBlock(dirInodeNum)
+	if err != nil {
+		return err
+	}
 
-			dirInodo.I_block[i] = int32(newBlockNum)
+	// Marcar bloque como usado en bitmap
+	err = f.markBlockAsUsed(newBlockNum)
+	if err != nil {
+		return err
+	}
 
-			// Crear bloque de directorio vacío
-			newDirBlock := &Models.BloqueCarpeta{}
-			for j := 0; j < len(newDirBlock.B_content); j++ {
-				newDirBlock.B_content[j].B_inodo = Models.FREE_INODE
-			}
+	// Crear bloque de directorio vacío
+	newDirBlock := &Models.BloqueCarpeta{}
+	for j := 0; j < len(newDirBlock.B_content); j++ {
+		newDirBlock.B_content[j].B_inodo = Models.FREE_INODE
+	}
 
-			// Agregar la nueva entrada en la primera posición
-			newDirBlock.B_content[0].B_inodo = int32(fileInodeNum)
-			copy(newDirBlock.B_content[0].B_name[:], filename)
+	// Agregar la nueva entrada en la primera posición
+	newDirBlock.B_content[0].B_inodo = int32(fileInodeNum)
+	copy(newDirBlock.B_content[0].B_name[:], filename)
 
-			// Escribir el nuevo bloque
-			err = f.writeDirectoryBlock(int32(newBlockNum), newDirBlock)
-			if err != nil {
-				return err
-			}
+	// Escribir el nuevo bloque
+	err = f.writeDirectoryBlock(int32(newBlockNum), newDirBlock)
+	if err != nil {
+		return err
+	}
 
-			// Actualizar el inodo del directorio padre
-			return f.writeInode(dirInodeNum, dirInodo)
-		}
+	// Enlazarlo después del último bloque (directo o indirecto)
+	slot, err := f.appendInodeBlock(dirInodeNum, dirInodo, newBlockNum)
+	if err != nil {
+		return err
 	}
 
-	return errors.New("no hay espacio en el directorio")
+	// Actualizar el inodo del directorio padre
+	if err := f.writeInode(dirInodeNum, dirInodo); err != nil {
+		return err
+	}
+	return f.DirectoryEntryAdded(dirInodeNum, dirInodo, slot, filename)
 }
 
 func (f *EXT2FileManager) writeDirectoryBlock(blockNumber int32, dirBlock *Models.BloqueCarpeta) error {
-	file, err := os.OpenFile(f.manager.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(f.manager.diskPath)
 	if err != nil {
 		return err
 	}
@@ -619,30 +633,33 @@ func (f *EXT2FileManager) writeDirectoryBlock(blockNumber int32, dirBlock *Model
 		return err
 	}
 
-	_, err = file.Write(buffer.Bytes())
-	return err
+	if _, err = file.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+	return MarkPartitionModified(f.manager.diskPath, f.manager.partitionInfo.PartStart)
 }
 
-// writeMultipleBlocks escribe contenido usando múltiples bloques de 64 bytes
-func (f *EXT2FileManager) writeMultipleBlocks(inodo *Models.Inodo, content []byte) error {
+// writeMultipleBlocks escribe contenido usando múltiples bloques de 64 bytes.
+// Los bloques de datos y de apuntadores se piden juntos para que queden contiguos y en
+// el grupo del inodo.
+func (f *EXT2FileManager) writeMultipleBlocks(inodeNumber int32, inodo *Models.Inodo, content []byte) error {
 	totalBytes := len(content)
 	blocksNeeded := (totalBytes + Models.BLOQUE_SIZE - 1) / Models.BLOQUE_SIZE
 
-	// Limitar a 12 bloques directos por ahora
-	if blocksNeeded > 12 {
-		blocksNeeded = 12
-		totalBytes = 12 * Models.BLOQUE_SIZE
-		content = content[:totalBytes]
+	if blocksNeeded > MaxFileBlocks {
+		return fmt.Errorf("el contenido excede el tamaño máximo de un archivo (%d bytes)", MaxFileBlocks*Models.BLOQUE_SIZE)
+	}
+	if blocksNeeded == 0 {
+		return nil
 	}
 
-	// Asignar y escribir bloques
-	for i := 0; i < blocksNeeded; i++ {
-		// Buscar bloque libre
-		blockNum, err := f.findFreeBlock()
-		if err != nil {
-			return err
-		}
+	blocks, err := f.FindFreeBlocks(inodeNumber, blocksNeeded+pointerBlocksNeeded(blocksNeeded))
+	if err != nil {
+		return err
+	}
 
+	// Escribir bloques de datos
+	for i, blockNum := range blocks[:blocksNeeded] {
 		// Calcular qué porción del contenido va en este bloque
 		start := i * Models.BLOQUE_SIZE
 		end := start + Models.BLOQUE_SIZE
@@ -656,32 +673,17 @@ func (f *EXT2FileManager) writeMultipleBlocks(inodo *Models.Inodo, content []byt
 		if err != nil {
 			return err
 		}
+	}
 
-		// Asignar puntero en el inodo
-		inodo.I_block[i] = blockNum
-
-		// Marcar bloque como usado
-		err = f.markBlockAsUsed(blockNum)
-		if err != nil {
+	// Marcar bloques como usados, incluidos los de apuntadores
+	for _, blockNum := range blocks {
+		if err := f.markBlockAsUsed(blockNum); err != nil {
 			return err
 		}
 	}
 
-	return nil
-}
-
-// freeInodeBlocks libera todos los bloques asignados a un inodo
-func (f *EXT2FileManager) freeInodeBlocks(inodo *Models.Inodo) error {
-	for i := 0; i < 12; i++ {
-		if inodo.I_block[i] != Models.FREE_BLOCK {
-			err := f.markBlockAsFree(inodo.I_block[i])
-			if err != nil {
-				return err
-			}
-			inodo.I_block[i] = Models.FREE_BLOCK
-		}
-	}
-	return nil
+	// Asignar punteros en el inodo
+	return f.linkInodeBlocks(inodo, blocks[:blocksNeeded], blocks[blocksNeeded:])
 }
 
 // markBlockAsFree marca un bloque como libre en el bitmap
@@ -0,0 +1,306 @@
+package System
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Models"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Bloques indirectos de los archivos.
+//
+// I_block[0..11] apuntan a bloques de datos. I_block[12] apunta a un BloqueApuntadores con
+// los siguientes 16 bloques de datos (indirecto simple) e I_block[13] a un BloqueApuntadores
+// cuyos punteros son a su vez bloques de apuntadores (indirecto doble). Los punteros se
+// llenan en orden y el primer FREE_BLOCK termina la lista. Las carpetas usan los mismos
+// bloques para sus BloqueCarpeta; I_block[14] (DIR_INDEX_BLOCK) no se usa para datos y en
+// las carpetas apunta a su índice de hash.
+
+const (
+	directBlockCount    = 12
+	singleIndirectIndex = 12
+	doubleIndirectIndex = 13
+	pointersPerBlock    = len(Models.BloqueApuntadores{}.B_pointers)
+
+	// MaxFileBlocks es la cantidad máxima de bloques de datos de un archivo
+	MaxFileBlocks = directBlockCount + pointersPerBlock + pointersPerBlock*pointersPerBlock
+)
+
+// InodeBlocks retorna en orden los bloques de datos del inodo y, aparte, los bloques de
+// apuntadores que los referencian
+func (f *EXT2FileManager) InodeBlocks(inodo *Models.Inodo) ([]int32, []int32, error) {
+	var data, pointers []int32
+	for i := 0; i < directBlockCount; i++ {
+		if inodo.I_block[i] == Models.FREE_BLOCK {
+			return data, pointers, nil
+		}
+		data = append(data, inodo.I_block[i])
+	}
+	for level, index := range []int{singleIndirectIndex, doubleIndirectIndex} {
+		if inodo.I_block[index] == Models.FREE_BLOCK {
+			break
+		}
+		complete, err := f.collectIndirect(inodo.I_block[index], level+1, &data, &pointers)
+		if err != nil || !complete {
+			return data, pointers, err
+		}
+	}
+	return data, pointers, nil
+}
+
+// collectIndirect agrega los bloques alcanzables desde un bloque de apuntadores de nivel
+// level (1 = apunta a datos). Retorna false si encontró el FREE_BLOCK que termina la lista.
+func (f *EXT2FileManager) collectIndirect(block int32, level int, data *[]int32, pointers *[]int32) (bool, error) {
+	pointerBlock, err := f.readPointerBlock(block)
+	if err != nil {
+		return false, err
+	}
+	*pointers = append(*pointers, block)
+
+	for _, ptr := range pointerBlock.B_pointers {
+		if ptr == Models.FREE_BLOCK {
+			return false, nil
+		}
+		if level == 1 {
+			*data = append(*data, ptr)
+			continue
+		}
+		complete, err := f.collectIndirect(ptr, level-1, data, pointers)
+		if err != nil || !complete {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// pointerBlocksNeeded retorna cuántos bloques de apuntadores necesita un archivo de
+// dataBlocks bloques de datos
+func pointerBlocksNeeded(dataBlocks int) int {
+	rest := dataBlocks - directBlockCount
+	if rest <= 0 {
+		return 0
+	}
+	if rest <= pointersPerBlock {
+		return 1
+	}
+	rest -= pointersPerBlock
+	return 2 + (rest+pointersPerBlock-1)/pointersPerBlock
+}
+
+// linkInodeBlocks guarda data en I_block y en los bloques de apuntadores tomados de spare,
+// que debe tener pointerBlocksNeeded(len(data)) bloques ya reservados
+func (f *EXT2FileManager) linkInodeBlocks(inodo *Models.Inodo, data []int32, spare []int32) error {
+	clearDataPointers(inodo)
+	next := 0
+	take := func() int32 {
+		next++
+		return spare[next-1]
+	}
+
+	for i := 0; i < directBlockCount && len(data) > 0; i++ {
+		inodo.I_block[i] = data[0]
+		data = data[1:]
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	// Indirecto simple
+	single := newPointerBlock()
+	count := copy(single.B_pointers[:], data)
+	data = data[count:]
+	inodo.I_block[singleIndirectIndex] = take()
+	if err := f.writePointerBlock(inodo.I_block[singleIndirectIndex], single); err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	// Indirecto doble
+	double := newPointerBlock()
+	inodo.I_block[doubleIndirectIndex] = take()
+	for i := 0; i < pointersPerBlock && len(data) > 0; i++ {
+		child := newPointerBlock()
+		count := copy(child.B_pointers[:], data)
+		data = data[count:]
+		double.B_pointers[i] = take()
+		if err := f.writePointerBlock(double.B_pointers[i], child); err != nil {
+			return err
+		}
+	}
+	return f.writePointerBlock(inodo.I_block[doubleIndirectIndex], double)
+}
+
+// FreeInodeBlocks libera en el bitmap los bloques de datos y de apuntadores de un archivo
+// o carpeta y deja sus punteros en FREE_BLOCK. El índice de una carpeta se libera aparte
+// con FreeDirectoryIndex.
+func (f *EXT2FileManager) FreeInodeBlocks(inodo *Models.Inodo) error {
+	data, pointers, err := f.InodeBlocks(inodo)
+	if err != nil {
+		return err
+	}
+	for _, block := range append(data, pointers...) {
+		if err := f.markBlockAsFree(block); err != nil {
+			return err
+		}
+	}
+	clearDataPointers(inodo)
+	return nil
+}
+
+// DirectoryBlocks retorna en orden los bloques de una carpeta, directos e indirectos. La
+// posición de cada bloque en la lista es la que reciben DirectoryEntryAdded y
+// DirectoryEntryRemoved. Si no puede leer un bloque de apuntadores retorna los anteriores.
+func (f *EXT2FileManager) DirectoryBlocks(dirInodo *Models.Inodo) []int32 {
+	blocks, _, _ := f.InodeBlocks(dirInodo)
+	return blocks
+}
+
+// appendInodeBlock enlaza block después del último bloque de datos del inodo, reservando
+// el bloque de apuntadores que haga falta. Retorna la posición del bloque; no escribe el inodo.
+func (f *EXT2FileManager) appendInodeBlock(inodeNumber int32, inodo *Models.Inodo, block int32) (int, error) {
+	data, _, err := f.InodeBlocks(inodo)
+	if err != nil {
+		return -1, err
+	}
+	slot := len(data)
+	if slot >= MaxFileBlocks {
+		return -1, fmt.Errorf("el inodo ya usa los %d bloques posibles", MaxFileBlocks)
+	}
+
+	if slot < directBlockCount {
+		inodo.I_block[slot] = block
+		return slot, nil
+	}
+
+	// Indirecto simple
+	rest := slot - directBlockCount
+	if rest < pointersPerBlock {
+		return slot, f.setPointer(inodeNumber, &inodo.I_block[singleIndirectIndex], rest, block)
+	}
+
+	// Indirecto doble: primero el bloque de apuntadores del grupo de 16, luego el dato
+	rest -= pointersPerBlock
+	if inodo.I_block[doubleIndirectIndex] == Models.FREE_BLOCK {
+		pointer, err := f.allocPointerBlock(inodeNumber)
+		if err != nil {
+			return -1, err
+		}
+		inodo.I_block[doubleIndirectIndex] = pointer
+	}
+	double, err := f.readPointerBlock(inodo.I_block[doubleIndirectIndex])
+	if err != nil {
+		return -1, err
+	}
+	child := double.B_pointers[rest/pointersPerBlock]
+	if err := f.setPointer(inodeNumber, &child, rest%pointersPerBlock, block); err != nil {
+		return -1, err
+	}
+	if double.B_pointers[rest/pointersPerBlock] != child {
+		double.B_pointers[rest/pointersPerBlock] = child
+		if err := f.writePointerBlock(inodo.I_block[doubleIndirectIndex], double); err != nil {
+			return -1, err
+		}
+	}
+	return slot, nil
+}
+
+// setPointer guarda block en la posición index del bloque de apuntadores *pointer,
+// reservándolo primero si todavía es FREE_BLOCK
+func (f *EXT2FileManager) setPointer(inodeNumber int32, pointer *int32, index int, block int32) error {
+	if *pointer == Models.FREE_BLOCK {
+		newPointer, err := f.allocPointerBlock(inodeNumber)
+		if err != nil {
+			return err
+		}
+		*pointer = newPointer
+	}
+	pointerBlock, err := f.readPointerBlock(*pointer)
+	if err != nil {
+		return err
+	}
+	pointerBlock.B_pointers[index] = block
+	return f.writePointerBlock(*pointer, pointerBlock)
+}
+
+// allocPointerBlock reserva un bloque de apuntadores vacío
+func (f *EXT2FileManager) allocPointerBlock(inodeNumber int32) (int32, error) {
+	block, err := f.FindFreeBlock(inodeNumber)
+	if err != nil {
+		return -1, err
+	}
+	if err := f.markBlockAsUsed(block); err != nil {
+		return -1, err
+	}
+	return block, f.writePointerBlock(block, newPointerBlock())
+}
+
+// clearDataPointers deja en FREE_BLOCK los punteros directos e indirectos del inodo
+func clearDataPointers(inodo *Models.Inodo) {
+	for i := 0; i <= doubleIndirectIndex; i++ {
+		inodo.I_block[i] = Models.FREE_BLOCK
+	}
+}
+
+// ReadInodeContent lee los I_s bytes de contenido de un archivo
+func (f *EXT2FileManager) ReadInodeContent(inodo *Models.Inodo) ([]byte, error) {
+	return f.readInodeContent(inodo)
+}
+
+// WriteInodeContent escribe content en bloques nuevos y los enlaza en el inodo, que debe
+// llegar sin bloques (FreeInodeBlocks). No escribe el inodo ni actualiza I_s.
+func (f *EXT2FileManager) WriteInodeContent(inodeNumber int32, inodo *Models.Inodo, content []byte) error {
+	return f.writeMultipleBlocks(inodeNumber, inodo, content)
+}
+
+func newPointerBlock() *Models.BloqueApuntadores {
+	block := &Models.BloqueApuntadores{}
+	for i := range block.B_pointers {
+		block.B_pointers[i] = Models.FREE_BLOCK
+	}
+	return block
+}
+
+func (f *EXT2FileManager) readPointerBlock(blockNumber int32) (*Models.BloqueApuntadores, error) {
+	if blockNumber < 0 || blockNumber >= f.manager.superBloque.S_blocks_count {
+		return nil, fmt.Errorf("bloque de apuntadores %d fuera de rango", blockNumber)
+	}
+	file, err := Device.Open(f.manager.diskPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	blockPos := f.manager.partitionInfo.PartStart + int64(f.manager.superBloque.S_block_start) + int64(blockNumber)*Models.BLOQUE_SIZE
+	if _, err := file.Seek(blockPos, 0); err != nil {
+		return nil, err
+	}
+
+	var pointerBlock Models.BloqueApuntadores
+	if err := binary.Read(file, binary.LittleEndian, &pointerBlock); err != nil {
+		return nil, err
+	}
+	return &pointerBlock, nil
+}
+
+func (f *EXT2FileManager) writePointerBlock(blockNumber int32, pointerBlock *Models.BloqueApuntadores) error {
+	file, err := Device.OpenWrite(f.manager.diskPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	blockPos := f.manager.partitionInfo.PartStart + int64(f.manager.superBloque.S_block_start) + int64(blockNumber)*Models.BLOQUE_SIZE
+	if _, err := file.Seek(blockPos, 0); err != nil {
+		return err
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := binary.Write(buffer, binary.LittleEndian, pointerBlock); err != nil {
+		return err
+	}
+	_, err = file.Write(buffer.Bytes())
+	return err
+}
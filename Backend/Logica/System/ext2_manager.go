@@ -1,11 +1,13 @@
 package System
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Models"
 	"bytes"
 	"encoding/binary"
 	"errors"
-	"os"
+	"math"
+	"time"
 )
 
 // MountInfo almacena informacion de una particion montada
@@ -23,6 +25,8 @@ type EXT2Manager struct {
 	diskPath      string
 	partitionInfo *Models.Partition
 	superBloque   *Models.SuperBloque
+	blockGroups   int32 // Grupos de bloques pedidos para el próximo formateo
+	dirIndex      bool  // Crear carpetas con índice de hash en el próximo formateo
 }
 
 func NewEXT2Manager(mountInfo *MountInfo) *EXT2Manager {
@@ -58,6 +62,8 @@ func (e *EXT2Manager) FormatPartition() error {
 	if err != nil {
 		return err
 	}
+	e.applyFormatOptions()
+	dropPartitionPathCache(e.diskPath, e.partitionInfo.PartStart)
 
 	// Escribir SuperBloque con metadatos del sistema
 	err = e.writeSuperBloque()
@@ -83,12 +89,23 @@ func (e *EXT2Manager) FormatPartition() error {
 		return err
 	}
 
-	return nil
+	// Reservar los bloques de las copias del superbloque antes de asignar otros
+	err = e.reserveSuperBlockBackups()
+	if err != nil {
+		return err
+	}
+
+	err = e.createRootIndex()
+	if err != nil {
+		return err
+	}
+
+	return syncSuperBlockBackups(e.diskPath, e.partitionInfo)
 }
 
 // LoadPartitionInfo carga metadatos de la particion desde el MBR
 func (e *EXT2Manager) LoadPartitionInfo() error {
-	file, err := os.Open(e.diskPath)
+	file, err := Device.Open(e.diskPath)
 	if err != nil {
 		return err
 	}
@@ -122,7 +139,7 @@ func (e *EXT2Manager) LoadSuperBlock() error {
 		return nil
 	}
 
-	file, err := os.Open(e.diskPath)
+	file, err := Device.Open(e.diskPath)
 	if err != nil {
 		return err
 	}
@@ -172,8 +189,102 @@ func (e *EXT2Manager) calculateEXT2Layout() error {
 	return nil
 }
 
+// applyFormatOptions guarda en el superbloque las opciones de SetBlockGroups y
+// SetDirectoryIndex. Los grupos no superan la cantidad de inodos (cada grupo necesita uno).
+func (e *EXT2Manager) applyFormatOptions() {
+	groups := e.blockGroups
+	if groups > e.superBloque.S_inodes_count {
+		groups = e.superBloque.S_inodes_count
+	}
+	if groups < 1 {
+		groups = 1
+	}
+	e.superBloque.S_groups_count = groups
+	if e.dirIndex {
+		e.superBloque.S_dir_index = 1
+	}
+}
+
+// createRootIndex crea el índice de hash de la raíz (ya con users.txt) si el formato lo pide
+func (e *EXT2Manager) createRootIndex() error {
+	fileManager := NewEXT2FileManager(e)
+	rootInodo, err := fileManager.readInode(Models.ROOT_INODE)
+	if err != nil {
+		return err
+	}
+	return fileManager.CreateDirectoryIndex(Models.ROOT_INODE, rootInodo)
+}
+
+// FreeCountDelta retorna el cambio en el contador de libres al marcar un bit como usado (-1) o libre (+1)
+func FreeCountDelta(used bool) int32 {
+	if used {
+		return -1
+	}
+	return 1
+}
+
+// superBlockFreeCountsOffset es la posición de S_free_blocks_count (seguido de
+// S_free_inodes_count) dentro del superbloque serializado
+const superBlockFreeCountsOffset = 3 * 4
+
+// AdjustSuperBlockFreeCounts suma los deltas a S_free_blocks_count y S_free_inodes_count en disco.
+// Se llama cada vez que un bit de los bitmaps cambia de estado para que df refleje el uso real.
+func AdjustSuperBlockFreeCounts(file *Device.Handle, partitionStart int64, blockDelta int32, inodeDelta int32) error {
+	var counts [2]int32
+	raw := make([]byte, 8)
+	if _, err := file.ReadAt(raw, partitionStart+superBlockFreeCountsOffset); err != nil {
+		return err
+	}
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &counts); err != nil {
+		return err
+	}
+
+	counts[0] += blockDelta
+	counts[1] += inodeDelta
+
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, counts)
+	if _, err := file.WriteAt(buffer.Bytes(), partitionStart+superBlockFreeCountsOffset); err != nil {
+		return err
+	}
+	return touchSuperBlockMtime(file, partitionStart)
+}
+
+// superBlockMtimeOffset es la posición de S_mtime dentro del superbloque serializado
+const superBlockMtimeOffset = 5 * 4
+
+// MarkPartitionModified actualiza S_mtime del superbloque en disco. La llaman las
+// escrituras de inodos, bloques de carpeta y bitmaps; la caché de reportes usa S_mtime,
+// la ruta del disco y el inicio de la partición como versión. Actualizar I_atime no lo
+// cambia, así una lectura no invalida los reportes.
+func MarkPartitionModified(diskPath string, partitionStart int64) error {
+	file, err := Device.OpenWrite(diskPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return touchSuperBlockMtime(file, partitionStart)
+}
+
+// touchSuperBlockMtime escribe la hora actual en S_mtime. El valor nuevo siempre es
+// mayor que el anterior, aunque dos escrituras caigan en el mismo instante.
+func touchSuperBlockMtime(file *Device.Handle, partitionStart int64) error {
+	raw := make([]byte, 8)
+	if _, err := file.ReadAt(raw, partitionStart+superBlockMtimeOffset); err != nil {
+		return err
+	}
+	previous := math.Float64frombits(binary.LittleEndian.Uint64(raw))
+	now := float64(time.Now().UnixNano()) / 1e9
+	if now <= previous {
+		now = math.Nextafter(previous, math.Inf(1))
+	}
+	binary.LittleEndian.PutUint64(raw, math.Float64bits(now))
+	_, err := file.WriteAt(raw, partitionStart+superBlockMtimeOffset)
+	return err
+}
+
 func (e *EXT2Manager) writeSuperBloque() error {
-	file, err := os.OpenFile(e.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(e.diskPath)
 	if err != nil {
 		return err
 	}
@@ -200,7 +311,7 @@ func (e *EXT2Manager) writeSuperBloque() error {
 
 // initializeBitmaps crea y escribe bitmaps iniciales de inodos y bloques
 func (e *EXT2Manager) initializeBitmaps() error {
-	file, err := os.OpenFile(e.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(e.diskPath)
 	if err != nil {
 		return err
 	}
@@ -248,7 +359,7 @@ func (e *EXT2Manager) initializeBitmaps() error {
 
 // createRootDirectory crea directorio raiz con inodo 0 y bloque 0
 func (e *EXT2Manager) createRootDirectory() error {
-	file, err := os.OpenFile(e.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(e.diskPath)
 	if err != nil {
 		return err
 	}
@@ -295,10 +406,18 @@ func (e *EXT2Manager) createRootDirectory() error {
 	return nil
 }
 
-// createUsersFile crea archivo users.txt con usuario root predeterminado
+// createUsersFile crea archivo users.txt con usuario root predeterminado. El contenido
+// ocupa bloques consecutivos desde usersFileBlock; el primero ya está marcado en el bitmap.
 func (e *EXT2Manager) createUsersFile() error {
 	// Contenido inicial usando la funcion de Models
-	usersContent := Models.CreateInitialUsersContent()
+	usersContent, err := Models.CreateInitialUsersContent()
+	if err != nil {
+		return err
+	}
+	blocksNeeded := (len(usersContent) + Models.BLOQUE_SIZE - 1) / Models.BLOQUE_SIZE
+	if blocksNeeded > usersFileBlocks || usersFileBlock+int32(blocksNeeded) > e.superBloque.S_blocks_count {
+		return errors.New("users.txt inicial no cabe en los bloques reservados")
+	}
 
 	// Crear inodo del archivo users.txt
 	usersInodo := Models.Inodo{
@@ -315,9 +434,11 @@ func (e *EXT2Manager) createUsersFile() error {
 	for i := range usersInodo.I_block {
 		usersInodo.I_block[i] = Models.FREE_BLOCK
 	}
-	usersInodo.I_block[0] = 100  // Usar bloque alto para evitar conflictos
+	for i := 0; i < blocksNeeded; i++ {
+		usersInodo.I_block[i] = usersFileBlock + int32(i) // Usar bloques altos para evitar conflictos
+	}
 
-	file, err := os.OpenFile(e.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(e.diskPath)
 	if err != nil {
 		return err
 	}
@@ -339,23 +460,31 @@ func (e *EXT2Manager) createUsersFile() error {
 		return err
 	}
 
-	blockPos := e.partitionInfo.PartStart + int64(e.superBloque.S_block_start) + int64(100*Models.BLOQUE_SIZE)
-	_, err = file.Seek(blockPos, 0)
-	if err != nil {
-		return err
-	}
+	fileManager := NewEXT2FileManager(e)
+	for i := 0; i < blocksNeeded; i++ {
+		start := i * Models.BLOQUE_SIZE
+		end := start + Models.BLOQUE_SIZE
+		if end > len(usersContent) {
+			end = len(usersContent)
+		}
+		block := usersInodo.I_block[i]
 
-	contentBlock := Models.BloqueArchivos{}
-	contentBlock.SetContent([]byte(usersContent))
+		contentBlock := Models.BloqueArchivos{}
+		contentBlock.SetContent([]byte(usersContent[start:end]))
 
-	buffer = new(bytes.Buffer)
-	err = binary.Write(buffer, binary.LittleEndian, &contentBlock)
-	if err != nil {
-		return err
-	}
-	_, err = file.Write(buffer.Bytes())
-	if err != nil {
-		return err
+		blockPos := e.partitionInfo.PartStart + int64(e.superBloque.S_block_start) + int64(block)*Models.BLOQUE_SIZE
+		buffer = new(bytes.Buffer)
+		if err := binary.Write(buffer, binary.LittleEndian, &contentBlock); err != nil {
+			return err
+		}
+		if _, err := file.WriteAt(buffer.Bytes(), blockPos); err != nil {
+			return err
+		}
+		if block != usersFileBlock {
+			if err := fileManager.markBlockAsUsed(block); err != nil {
+				return err
+			}
+		}
 	}
 
 	return e.addFileToRootDirectory("users.txt", 1)
@@ -363,7 +492,7 @@ func (e *EXT2Manager) createUsersFile() error {
 
 // addFileToRootDirectory agrega una entrada de archivo al directorio raiz
 func (e *EXT2Manager) addFileToRootDirectory(filename string, inodoNumber int32) error {
-	file, err := os.OpenFile(e.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(e.diskPath)
 	if err != nil {
 		return err
 	}
@@ -434,3 +563,13 @@ func (e *EXT2Manager) GetSuperBlock() *Models.SuperBloque {
 func (e *EXT2Manager) SetSuperBlock(sb *Models.SuperBloque) {
 	e.superBloque = sb
 }
+
+// SetBlockGroups define en cuántos grupos se reparte la partición al formatear (1 = sin grupos)
+func (e *EXT2Manager) SetBlockGroups(groups int32) {
+	e.blockGroups = groups
+}
+
+// SetDirectoryIndex define si las carpetas del próximo formateo llevan índice de hash
+func (e *EXT2Manager) SetDirectoryIndex(enabled bool) {
+	e.dirIndex = enabled
+}
@@ -1,11 +1,14 @@
 package System
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
-	"os"
 )
 
+// ext3JournalingConstant es el tamaño del journal por inodo (constante especificada)
+const ext3JournalingConstant = 50
+
 // EXT3Manager extiende EXT2Manager con soporte de journaling
 type EXT3Manager struct {
 	*EXT2Manager
@@ -39,6 +42,8 @@ func (e *EXT3Manager) FormatPartition() error {
 	if err != nil {
 		return err
 	}
+	e.applyFormatOptions()
+	dropPartitionPathCache(e.diskPath, e.partitionInfo.PartStart)
 
 	// Escribir SuperBloque con metadatos del sistema (tipo 3 para EXT3)
 	err = e.writeSuperBloqueEXT3()
@@ -46,11 +51,18 @@ func (e *EXT3Manager) FormatPartition() error {
 		return err
 	}
 
-	// Inicializar journal
+	// Inicializar journal y vaciar el registro de intenciones del formato anterior
 	err = e.initializeJournal()
 	if err != nil {
 		return err
 	}
+	err = resetIntentLog(e.diskPath, e.partitionInfo, e.superBloque)
+	if err != nil {
+		return err
+	}
+	if missingIntentLog(e.superBloque) {
+		warnMissingIntentLog(e.partitionInfo)
+	}
 
 	// Inicializar bitmaps de inodos y bloques
 	err = e.initializeBitmaps()
@@ -70,7 +82,18 @@ func (e *EXT3Manager) FormatPartition() error {
 		return err
 	}
 
-	return nil
+	// Reservar los bloques de las copias del superbloque antes de asignar otros
+	err = e.reserveSuperBlockBackups()
+	if err != nil {
+		return err
+	}
+
+	err = e.createRootIndex()
+	if err != nil {
+		return err
+	}
+
+	return syncSuperBlockBackups(e.diskPath, e.partitionInfo)
 }
 
 // calculateEXT3Layout calcula la distribucion del espacio para EXT3 con Journaling
@@ -93,7 +116,7 @@ func (e *EXT3Manager) calculateEXT3Layout() error {
 	// Despejando n:
 	// n = (tamaño_particion - sizeof(superblock)) / (sizeof(Journaling) + 1 + 3 + sizeof(inodos) + 3*sizeof(block))
 
-	journalingConstant := int64(50) // Constante especificada
+	journalingConstant := int64(ext3JournalingConstant)
 	numerator := float64(partitionSize - superBlockSize)
 	denominator := float64(journalingConstant + 1 + 3 + inodoSize + 3*blockSize)
 	n := numerator / denominator
@@ -104,28 +127,34 @@ func (e *EXT3Manager) calculateEXT3Layout() error {
 		return nil
 	}
 
+	// Crear superbloque con tipo 3 (EXT3)
+	e.superBloque = &Models.SuperBloque{}
+	*e.superBloque = newEXT3SuperBloque(inodesCount)
+
+	return nil
+}
+
+// newEXT3SuperBloque crea el superbloque EXT3 de una partición con inodesCount inodos
+func newEXT3SuperBloque(inodesCount int32) Models.SuperBloque {
 	// Relacion 3:1 bloques por inodo (3 tipos de bloques)
 	blocksCount := 3 * inodesCount
 
 	// Calcular posiciones de estructuras en el disco para EXT3
 	inodeBitmapSize := inodesCount
 	blockBitmapSize := blocksCount
-	journalSize := inodesCount * int32(journalingConstant)
+	journalSize := inodesCount * ext3JournalingConstant
 
-	// Crear superbloque con tipo 3 (EXT3)
-	e.superBloque = &Models.SuperBloque{}
-	*e.superBloque = Models.NewSuperBloque(inodesCount, blocksCount)
-	e.superBloque.S_filesystem_type = 3
+	sb := Models.NewSuperBloque(inodesCount, blocksCount)
+	sb.S_filesystem_type = 3
 
 	// Layout EXT3:
 	// [SuperBloque][Journal][Bitmap_Inodos][Bitmap_Bloques][Tabla_Inodos][Bloques]
-	e.superBloque.S_journal_start = Models.SUPERBLOQUE_SIZE
-	e.superBloque.S_bm_inode_start = Models.SUPERBLOQUE_SIZE + journalSize
-	e.superBloque.S_bm_block_start = e.superBloque.S_bm_inode_start + inodeBitmapSize
-	e.superBloque.S_inode_start = e.superBloque.S_bm_block_start + blockBitmapSize
-	e.superBloque.S_block_start = e.superBloque.S_inode_start + (inodesCount * Models.INODO_SIZE)
-
-	return nil
+	sb.S_journal_start = Models.SUPERBLOQUE_SIZE
+	sb.S_bm_inode_start = Models.SUPERBLOQUE_SIZE + journalSize
+	sb.S_bm_block_start = sb.S_bm_inode_start + inodeBitmapSize
+	sb.S_inode_start = sb.S_bm_block_start + blockBitmapSize
+	sb.S_block_start = sb.S_inode_start + (inodesCount * Models.INODO_SIZE)
+	return sb
 }
 
 // writeSuperBloqueEXT3 escribe el superbloque con tipo EXT3
@@ -137,7 +166,7 @@ func (e *EXT3Manager) writeSuperBloqueEXT3() error {
 	// Cambiar el tipo de sistema de archivos a EXT3
 	e.superBloque.S_filesystem_type = 3
 
-	file, err := os.OpenFile(e.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(e.diskPath)
 	if err != nil {
 		return err
 	}
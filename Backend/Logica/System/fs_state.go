@@ -0,0 +1,143 @@
+package System
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Models"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Estado de montaje del superbloque.
+//
+// S_state vale SB_STATE_DIRTY desde la primera escritura después de montar y vuelve a
+// SB_STATE_CLEAN al desmontar, cuando la caché y el journal ya están en el disco. Si al
+// montar la partición sigue sucia, el programa terminó sin desmontarla: mount aplica el
+// registro de intenciones (EXT3) y revisa el sistema de archivos con CheckFileSystem.
+// El estado se escribe directo al disco (Device.WriteThrough) para que la marca no
+// quede solo en la caché. Un montaje de solo lectura (-ro) no modifica el superbloque.
+
+// RecordMount actualiza S_mnt_count, S_mtime y S_umtime al montar la partición y retorna
+// si quedó sucia del montaje anterior. Las particiones sin formato no se modifican.
+func RecordMount(mountInfo *MountInfo) (bool, error) {
+	manager := NewEXT2Manager(mountInfo)
+	if manager == nil || manager.superBloque.S_magic != Models.EXT2_MAGIC {
+		return false, nil
+	}
+
+	sb := manager.superBloque
+	now := float64(time.Now().UnixNano()) / 1e9
+	sb.S_mnt_count++
+	sb.S_mtime = now
+	sb.S_umtime = now
+	return sb.S_state == Models.SB_STATE_DIRTY, writeSuperBlockThrough(manager)
+}
+
+// IsSuperBlockDirty indica si la partición quedó sucia del montaje anterior sin modificar
+// el superbloque; lo usa el montaje de solo lectura en lugar de RecordMount
+func IsSuperBlockDirty(mountInfo *MountInfo) bool {
+	manager := NewEXT2Manager(mountInfo)
+	if manager == nil || manager.superBloque.S_magic != Models.EXT2_MAGIC {
+		return false
+	}
+	return manager.superBloque.S_state == Models.SB_STATE_DIRTY
+}
+
+// MarkSuperBlockDirty marca la partición como sucia si todavía no lo está. Se llama antes
+// de cada operación que escribe en la partición.
+func MarkSuperBlockDirty(mountInfo *MountInfo) error {
+	return setSuperBlockState(mountInfo, Models.SB_STATE_DIRTY)
+}
+
+// MarkSuperBlockClean marca la partición como desmontada correctamente
+func MarkSuperBlockClean(mountInfo *MountInfo) error {
+	return setSuperBlockState(mountInfo, Models.SB_STATE_CLEAN)
+}
+
+func setSuperBlockState(mountInfo *MountInfo, state int32) error {
+	manager := NewEXT2Manager(mountInfo)
+	if manager == nil || manager.superBloque.S_magic != Models.EXT2_MAGIC || manager.superBloque.S_state == state {
+		return nil
+	}
+	manager.superBloque.S_state = state
+	return writeSuperBlockThrough(manager)
+}
+
+// writeSuperBlockThrough escribe el superbloque del manager directo al disco
+func writeSuperBlockThrough(manager *EXT2Manager) error {
+	var buffer bytes.Buffer
+	if err := binary.Write(&buffer, binary.LittleEndian, manager.superBloque); err != nil {
+		return err
+	}
+	return Device.WriteThrough(manager.diskPath, buffer.Bytes(), manager.partitionInfo.PartStart)
+}
+
+// CheckFileSystem revisa una partición que no se desmontó correctamente y retorna lo que
+// encontró. Corrige los contadores libres del superbloque según los bitmaps; las entradas
+// que apuntan a inodos libres y los inodos usados sin entrada solo se reportan.
+func CheckFileSystem(mountInfo *MountInfo) ([]string, error) {
+	manager := NewEXT2Manager(mountInfo)
+	if manager == nil || manager.superBloque.S_magic != Models.EXT2_MAGIC {
+		return nil, nil
+	}
+	fileManager := NewEXT2FileManager(manager)
+	sb := manager.superBloque
+
+	inodeBitmap, err := fileManager.readInodeBitmap()
+	if err != nil {
+		return nil, err
+	}
+	blockBitmap, err := fileManager.readBlockBitmap()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []string
+
+	// Contadores libres del superbloque contra los bitmaps
+	freeInodes := sb.S_inodes_count - countUsedBits(inodeBitmap, sb.S_inodes_count)
+	freeBlocks := sb.S_blocks_count - countUsedBits(blockBitmap, sb.S_blocks_count)
+	if freeInodes != sb.S_free_inodes_count || freeBlocks != sb.S_free_blocks_count {
+		findings = append(findings, fmt.Sprintf("contadores libres: superbloque %d inodos y %d bloques, bitmaps %d y %d (corregido)",
+			sb.S_free_inodes_count, sb.S_free_blocks_count, freeInodes, freeBlocks))
+		if err := fileManager.adjustFreeCounts(freeBlocks-sb.S_free_blocks_count, freeInodes-sb.S_free_inodes_count); err != nil {
+			return findings, err
+		}
+	}
+
+	// Entradas de carpeta contra el bitmap de inodos
+	reachable := map[int32]bool{Models.ROOT_INODE: true}
+	walkErr := NewEXT2DirectoryManager(manager).Walk("/", func(path string, entry DirectoryEntry) {
+		reachable[entry.InodeNumber] = true
+		if !Models.IsBitmapBitSet(inodeBitmap, int(entry.InodeNumber)) {
+			findings = append(findings, fmt.Sprintf("%s apunta al inodo %d, que está libre", path, entry.InodeNumber))
+		}
+	})
+	if walkErr != nil {
+		findings = append(findings, fmt.Sprintf("no se pudo recorrer el árbol: %v", walkErr))
+		return findings, nil
+	}
+
+	orphans := 0
+	for i := int32(0); i < sb.S_inodes_count; i++ {
+		if Models.IsBitmapBitSet(inodeBitmap, int(i)) && !reachable[i] {
+			orphans++
+		}
+	}
+	if orphans > 0 {
+		findings = append(findings, fmt.Sprintf("%d inodo(s) marcados en uso sin entrada en ninguna carpeta", orphans))
+	}
+	return findings, nil
+}
+
+// countUsedBits cuenta los bits ocupados dentro de los primeros total elementos
+func countUsedBits(bitmap []byte, total int32) int32 {
+	var used int32
+	for i := 0; i < int(total); i++ {
+		if Models.IsBitmapBitSet(bitmap, i) {
+			used++
+		}
+	}
+	return used
+}
@@ -0,0 +1,424 @@
+package System
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Models"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Registro de intenciones (write-ahead) de EXT3.
+//
+// Un comando escribe por separado bitmaps, inodos, bloques, superbloque y journal; si se
+// interrumpe a la mitad quedan bloques perdidos o entradas que apuntan a inodos libres.
+// En EXT3 las escrituras del comando se acumulan en una Device.Transaction y al
+// confirmarla se registran primero en el espacio del journal que sigue a Models.Journal:
+//
+//	[IntentHeader][IntentImage + datos]...[IntentHeader][IntentImage + datos]...
+//
+// La cabecera se escribe en estado BEGIN junto con las imágenes y pasa a COMMIT cuando
+// todo está en el disco; recién entonces las imágenes se escriben en su lugar, a través
+// de la caché. Las transacciones se agregan una tras otra; cuando no hay espacio, y en
+// sync y unmount, se escribe la caché y el registro vuelve a empezar (checkpoint).
+// Al montar, ReplayIntentLog vuelve a aplicar en orden las transacciones confirmadas y
+// descarta la que quedó sin COMMIT, así cada comando queda completo o no queda.
+
+var intentHeaderSize = int64(binary.Size(Models.IntentHeader{}))
+
+// intentArea es el espacio del registro de una partición, entre Journal y el bitmap de inodos
+type intentArea struct {
+	diskPath  string
+	partStart int64
+	partSize  int64
+	start     int64 // Posición absoluta en el disco
+	size      int64
+}
+
+// intentLogState es la posición de escritura del registro de una partición. mu ordena
+// las transacciones y los checkpoint de sync, que no toma el bloqueo de la partición.
+type intentLogState struct {
+	mu       sync.Mutex
+	tail     int64
+	sequence int64 // Secuencia de la última transacción escrita
+}
+
+var (
+	intentLogsMu sync.Mutex
+	intentLogs   = make(map[partitionKey]*intentLogState)
+)
+
+// Transaction agrupa las escrituras de un comando sobre una partición EXT3
+type Transaction struct {
+	mountID string
+	area    *intentArea
+	device  *Device.Transaction
+	done    bool
+}
+
+// BeginTransaction inicia una transacción sobre la partición montada. Retorna nil sin
+// error si la partición no es EXT3: sus escrituras siguen yendo directo al disco.
+func BeginTransaction(mountInfo *MountInfo) (*Transaction, error) {
+	manager := NewEXT2Manager(mountInfo)
+	if manager == nil {
+		return nil, nil
+	}
+	area, ok := newIntentArea(manager.diskPath, manager.partitionInfo, manager.superBloque)
+	if !ok {
+		return nil, nil
+	}
+
+	device, err := Device.BeginTransaction(area.diskPath, area.partStart, area.partSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{mountID: mountInfo.MountID, area: area, device: device}, nil
+}
+
+// Abort descarta las escrituras de la transacción; no hace nada si ya terminó
+func (t *Transaction) Abort() {
+	if t.done {
+		return
+	}
+	t.done = true
+	t.device.End()
+
+	// La caché de rutas pudo guardar entradas que nunca llegaron al disco
+	dropPartitionPathCache(t.area.diskPath, t.area.partStart)
+}
+
+// Commit registra las escrituras en el journal y después las aplica en su lugar. Si la
+// transacción no cabe en el registro se descarta completa y retorna el error.
+func (t *Transaction) Commit() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	t.device.End()
+
+	images := t.device.Images()
+	if len(images) == 0 {
+		return nil
+	}
+
+	if err := t.area.commit(t.mountID, images); err != nil {
+		dropPartitionPathCache(t.area.diskPath, t.area.partStart)
+		return fmt.Errorf("error escribiendo el journal: %v", err)
+	}
+	return nil
+}
+
+// ReplayIntentLog vuelve a aplicar las transacciones confirmadas del registro de la
+// partición y lo vacía. Se llama al montar, antes de activar la caché de la partición.
+func ReplayIntentLog(diskPath string, partition *Models.Partition) (int, error) {
+	file, err := Device.Open(diskPath)
+	if err != nil {
+		return 0, err
+	}
+	var sb Models.SuperBloque
+	file.Seek(partition.PartStart, 0)
+	err = binary.Read(file, binary.LittleEndian, &sb)
+	file.Close()
+	if err != nil {
+		return 0, nil
+	}
+
+	area, ok := newIntentArea(diskPath, partition, &sb)
+	if !ok {
+		if missingIntentLog(&sb) {
+			warnMissingIntentLog(partition)
+		}
+		return 0, nil
+	}
+	defer dropPartitionPathCache(diskPath, partition.PartStart)
+
+	count, tail, _, err := area.scan(area.apply)
+	if err != nil {
+		return count, err
+	}
+	if tail > 0 {
+		if err := area.reset(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// CheckpointIntentLog escribe la caché de la partición y vacía su registro de intenciones
+func CheckpointIntentLog(mountInfo *MountInfo) error {
+	manager := NewEXT2Manager(mountInfo)
+	if manager == nil {
+		return nil
+	}
+	area, ok := newIntentArea(manager.diskPath, manager.partitionInfo, manager.superBloque)
+	if !ok {
+		return nil
+	}
+
+	state, err := area.state()
+	if err != nil {
+		return err
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if state.tail == 0 {
+		return nil
+	}
+	return area.checkpoint(mountInfo.MountID, state)
+}
+
+// resetIntentLog vacía el registro al formatear o al simular una pérdida
+func resetIntentLog(diskPath string, partition *Models.Partition, sb *Models.SuperBloque) error {
+	area, ok := newIntentArea(diskPath, partition, sb)
+	if !ok {
+		return nil
+	}
+	return area.reset()
+}
+
+// newIntentArea ubica el registro de la partición; falla si no es EXT3 o si el journal
+// no deja espacio después de Models.Journal
+func newIntentArea(diskPath string, partition *Models.Partition, sb *Models.SuperBloque) (*intentArea, bool) {
+	if sb == nil || sb.S_magic != Models.EXT2_MAGIC || sb.S_filesystem_type != 3 || sb.S_journal_start <= 0 {
+		return nil, false
+	}
+
+	start := int64(sb.S_journal_start) + int64(binary.Size(Models.Journal{}))
+	end := int64(sb.S_bm_inode_start)
+	if end-start <= intentHeaderSize {
+		return nil, false
+	}
+	return &intentArea{
+		diskPath:  diskPath,
+		partStart: partition.PartStart,
+		partSize:  partition.PartSize,
+		start:     partition.PartStart + start,
+		size:      end - start,
+	}, true
+}
+
+// missingIntentLog indica si la partición es EXT3 pero su journal no deja espacio para el
+// registro de intenciones. Pasa en particiones muy pequeñas: BeginTransaction retorna nil
+// y los comandos escriben directo al disco, sin atomicidad.
+func missingIntentLog(sb *Models.SuperBloque) bool {
+	if sb == nil || sb.S_magic != Models.EXT2_MAGIC || sb.S_filesystem_type != 3 {
+		return false
+	}
+	_, ok := newIntentArea("", &Models.Partition{}, sb)
+	return !ok
+}
+
+// warnMissingIntentLog avisa que la partición EXT3 funciona sin registro de intenciones.
+// Se muestra al formatear y al montar.
+func warnMissingIntentLog(partition *Models.Partition) {
+	name := strings.TrimRight(string(partition.PartName[:]), "\x00")
+	Output.Printf("ADVERTENCIA: el journal de %s no deja espacio para el registro de intenciones; "+
+		"los comandos se aplican sin registro y una interrupción puede dejarlos a medias\n", name)
+}
+
+// state retorna la posición de escritura del registro, recorriéndolo la primera vez
+func (a *intentArea) state() (*intentLogState, error) {
+	intentLogsMu.Lock()
+	defer intentLogsMu.Unlock()
+
+	key := partitionKey{diskPath: a.diskPath, start: a.partStart}
+	if state, ok := intentLogs[key]; ok {
+		return state, nil
+	}
+
+	count, tail, last, err := a.scan(nil)
+	if err != nil {
+		return nil, err
+	}
+	// Las secuencias nuevas son mayores que cualquier cabecera vieja que quede en el
+	// registro, para que el recorrido no las tome como continuación
+	sequence := time.Now().UnixNano()
+	if count > 0 {
+		sequence = last
+	}
+	state := &intentLogState{tail: tail, sequence: sequence}
+	intentLogs[key] = state
+	return state, nil
+}
+
+// forget descarta la posición de escritura guardada en memoria
+func (a *intentArea) forget() {
+	intentLogsMu.Lock()
+	defer intentLogsMu.Unlock()
+	delete(intentLogs, partitionKey{diskPath: a.diskPath, start: a.partStart})
+}
+
+// commit agrega la transacción al final del registro, la confirma y aplica las imágenes.
+// Se aplican con mu tomado para que un checkpoint no vacíe el registro antes.
+func (a *intentArea) commit(mountID string, images []Device.Image) error {
+	state, err := a.state()
+	if err != nil {
+		return err
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	var body bytes.Buffer
+	for _, image := range images {
+		binary.Write(&body, binary.LittleEndian, Models.IntentImage{
+			T_offset: image.Offset - a.partStart,
+			T_length: int32(len(image.Data)),
+		})
+		body.Write(image.Data)
+	}
+
+	recordSize := intentHeaderSize + int64(body.Len())
+	if state.tail+recordSize > a.size && state.tail > 0 {
+		if err := a.checkpoint(mountID, state); err != nil {
+			return err
+		}
+	}
+	// Aplicarla sin registro dejaría de ser atómica: se descarta completa
+	if recordSize > a.size {
+		return fmt.Errorf("la operación modifica %d bytes y no cabe en el registro de intenciones (%d bytes); no se aplicó",
+			body.Len(), a.size-intentHeaderSize)
+	}
+
+	header := Models.IntentHeader{
+		T_magic:    Models.INTENT_MAGIC,
+		T_state:    Models.INTENT_BEGIN,
+		T_sequence: state.sequence + 1,
+		T_count:    int32(len(images)),
+		T_length:   int32(body.Len()),
+		T_checksum: crc32.ChecksumIEEE(body.Bytes()),
+	}
+
+	var record bytes.Buffer
+	binary.Write(&record, binary.LittleEndian, &header)
+	record.Write(body.Bytes())
+
+	pos := a.start + state.tail
+	if err := Device.WriteThrough(a.diskPath, record.Bytes(), pos); err != nil {
+		return err
+	}
+
+	header.T_state = Models.INTENT_COMMIT
+	var commitHeader bytes.Buffer
+	binary.Write(&commitHeader, binary.LittleEndian, &header)
+	if err := Device.WriteThrough(a.diskPath, commitHeader.Bytes(), pos); err != nil {
+		return err
+	}
+
+	state.tail += recordSize
+	state.sequence = header.T_sequence
+	return a.apply(images)
+}
+
+// apply escribe las imágenes en su lugar de la partición
+func (a *intentArea) apply(images []Device.Image) error {
+	file, err := Device.OpenWrite(a.diskPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for _, image := range images {
+		if _, err := file.WriteAt(image.Data, image.Offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkpoint escribe la caché de la partición, con lo que todo lo registrado queda en su
+// lugar, y vacía el registro
+func (a *intentArea) checkpoint(mountID string, state *intentLogState) error {
+	if _, _, err := Device.FlushCache(mountID); err != nil {
+		return err
+	}
+	if err := Device.WriteThrough(a.diskPath, make([]byte, intentHeaderSize), a.start); err != nil {
+		return err
+	}
+	state.tail = 0
+	return nil
+}
+
+// reset invalida la primera cabecera del registro y olvida la posición de escritura
+func (a *intentArea) reset() error {
+	a.forget()
+	return Device.WriteThrough(a.diskPath, make([]byte, intentHeaderSize), a.start)
+}
+
+// scan recorre las transacciones confirmadas en orden, pasándole a apply (si no es nil)
+// las imágenes de cada una. Se detiene en la primera cabecera inválida, sin COMMIT, que
+// no continúa la secuencia o cuyo checksum no coincide. Retorna cuántas recorrió, dónde
+// termina la última y su secuencia.
+func (a *intentArea) scan(apply func([]Device.Image) error) (int, int64, int64, error) {
+	file, err := Device.Open(a.diskPath)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer file.Close()
+
+	count := 0
+	var pos, last int64
+	for pos+intentHeaderSize <= a.size {
+		var header Models.IntentHeader
+		file.Seek(a.start+pos, 0)
+		if err := binary.Read(file, binary.LittleEndian, &header); err != nil {
+			break
+		}
+		if header.T_magic != Models.INTENT_MAGIC || header.T_state != Models.INTENT_COMMIT ||
+			header.T_length < 0 || pos+intentHeaderSize+int64(header.T_length) > a.size {
+			break
+		}
+		if count > 0 && header.T_sequence != last+1 {
+			break
+		}
+
+		body := make([]byte, header.T_length)
+		if _, err := io.ReadFull(file, body); err != nil {
+			break
+		}
+		if crc32.ChecksumIEEE(body) != header.T_checksum {
+			break
+		}
+		images, ok := a.decode(body, header.T_count)
+		if !ok {
+			break
+		}
+
+		if apply != nil {
+			if err := apply(images); err != nil {
+				return count, pos, last, err
+			}
+		}
+		count++
+		last = header.T_sequence
+		pos += intentHeaderSize + int64(header.T_length)
+	}
+	return count, pos, last, nil
+}
+
+// decode separa las imágenes del cuerpo de una transacción, validando que caigan dentro
+// de la partición
+func (a *intentArea) decode(body []byte, count int32) ([]Device.Image, bool) {
+	reader := bytes.NewReader(body)
+	images := make([]Device.Image, 0, count)
+	for i := int32(0); i < count; i++ {
+		var image Models.IntentImage
+		if err := binary.Read(reader, binary.LittleEndian, &image); err != nil {
+			return nil, false
+		}
+		if image.T_offset < 0 || image.T_length < 0 || image.T_offset+int64(image.T_length) > a.partSize {
+			return nil, false
+		}
+		data := make([]byte, image.T_length)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, false
+		}
+		images = append(images, Device.Image{Offset: a.partStart + image.T_offset, Data: data})
+	}
+	return images, reader.Len() == 0
+}
@@ -1,9 +1,9 @@
 package System
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
-	"os"
 )
 
 // JournalManager gestiona las operaciones del journal de EXT3
@@ -44,7 +44,7 @@ func (jm *JournalManager) InitializeJournal() error {
 
 // LoadJournal carga el journal desde el disco
 func (jm *JournalManager) LoadJournal() error {
-	file, err := os.Open(jm.diskPath)
+	file, err := Device.Open(jm.diskPath)
 	if err != nil {
 		return err
 	}
@@ -66,7 +66,7 @@ func (jm *JournalManager) LoadJournal() error {
 
 // WriteJournal escribe el journal al disco
 func (jm *JournalManager) WriteJournal() error {
-	file, err := os.OpenFile(jm.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(jm.diskPath)
 	if err != nil {
 		return err
 	}
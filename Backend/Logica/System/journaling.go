@@ -1,10 +1,11 @@
 package System
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 )
@@ -22,7 +23,7 @@ func NewJournalingViewer(diskPath string, partitionInfo *Models.Partition) *Jour
 }
 
 func (jv *JournalingViewer) ShowJournal() error {
-	file, err := os.Open(jv.diskPath)
+	file, err := Device.Open(jv.diskPath)
 	if err != nil {
 		return err
 	}
@@ -46,16 +47,16 @@ func (jv *JournalingViewer) ShowJournal() error {
 	}
 
 	if len(entries) == 0 {
-		fmt.Println("No hay transacciones registradas en el journal")
+		Output.Println("No hay transacciones registradas en el journal")
 		return nil
 	}
 
-	fmt.Println("╔════════════════════════════════════════════════════════════════════════════╗")
-	fmt.Println("║                         JOURNAL - TRANSACCIONES EXT3                       ║")
-	fmt.Println("╠════════════════════════════════════════════════════════════════════════════╣")
-	fmt.Printf("║ Total de transacciones: %-52d║\n", len(entries))
-	fmt.Println("╚════════════════════════════════════════════════════════════════════════════╝")
-	fmt.Println()
+	Output.Println("╔════════════════════════════════════════════════════════════════════════════╗")
+	Output.Println("║                         JOURNAL - TRANSACCIONES EXT3                       ║")
+	Output.Println("╠════════════════════════════════════════════════════════════════════════════╣")
+	Output.Printf("║ Total de transacciones: %-52d║\n", len(entries))
+	Output.Println("╚════════════════════════════════════════════════════════════════════════════╝")
+	Output.Println()
 
 	for i, entry := range entries {
 		operation := strings.TrimRight(string(entry.I_operation[:]), "\x00")
@@ -63,25 +64,25 @@ func (jv *JournalingViewer) ShowJournal() error {
 		content := strings.TrimRight(string(entry.I_content[:]), "\x00")
 		date := time.Unix(int64(entry.I_date), 0)
 
-		fmt.Println("┌────────────────────────────────────────────────────────────────────────────┐")
-		fmt.Printf("│ Transacción #%-65d│\n", i+1)
-		fmt.Println("├────────────────────────────────────────────────────────────────────────────┤")
-		fmt.Printf("│ Operación:  %-66s│\n", operation)
-		fmt.Printf("│ Ruta:       %-66s│\n", path)
+		Output.Println("┌────────────────────────────────────────────────────────────────────────────┐")
+		Output.Printf("│ Transacción #%-65d│\n", i+1)
+		Output.Println("├────────────────────────────────────────────────────────────────────────────┤")
+		Output.Printf("│ Operación:  %-66s│\n", operation)
+		Output.Printf("│ Ruta:       %-66s│\n", path)
 
 		if content != "" {
 			if len(content) > 60 {
-				fmt.Printf("│ Contenido:  %-66s│\n", content[:60]+"...")
+				Output.Printf("│ Contenido:  %-66s│\n", content[:60]+"...")
 			} else {
-				fmt.Printf("│ Contenido:  %-66s│\n", content)
+				Output.Printf("│ Contenido:  %-66s│\n", content)
 			}
 		} else {
-			fmt.Printf("│ Contenido:  %-66s│\n", "(vacío)")
+			Output.Printf("│ Contenido:  %-66s│\n", "(vacío)")
 		}
 
-		fmt.Printf("│ Fecha/Hora: %-66s│\n", date.Format("2006-01-02 15:04:05"))
-		fmt.Println("└────────────────────────────────────────────────────────────────────────────┘")
-		fmt.Println()
+		Output.Printf("│ Fecha/Hora: %-66s│\n", date.Format("2006-01-02 15:04:05"))
+		Output.Println("└────────────────────────────────────────────────────────────────────────────┘")
+		Output.Println()
 	}
 
 	return nil
@@ -1,10 +1,10 @@
 package System
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
-	"fmt"
-	"os"
 )
 
 type LossSimulator struct {
@@ -21,7 +21,7 @@ func NewLossSimulator(diskPath string, partitionInfo *Models.Partition) *LossSim
 }
 
 func (ls *LossSimulator) SimulateSystemLoss() error {
-	file, err := os.OpenFile(ls.diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(ls.diskPath)
 	if err != nil {
 		return err
 	}
@@ -35,39 +35,52 @@ func (ls *LossSimulator) SimulateSystemLoss() error {
 	}
 
 	ls.superBloque = &sb
+	defer dropPartitionPathCache(ls.diskPath, ls.partitionInfo.PartStart)
 
-	fmt.Println("Simulando pérdida del sistema de archivos...")
-	fmt.Println("ADVERTENCIA: Esta operación destruirá todos los datos en la partición")
+	// Las transacciones registradas no deben volver a aplicarse sobre la partición vacía
+	err = resetIntentLog(ls.diskPath, ls.partitionInfo, ls.superBloque)
+	if err != nil {
+		return err
+	}
+
+	Output.Println("Simulando pérdida del sistema de archivos...")
+	Output.Println("ADVERTENCIA: Esta operación destruirá todos los datos en la partición")
 
 	err = ls.clearInodeBitmap(file)
 	if err != nil {
 		return err
 	}
-	fmt.Println("✓ Bitmap de Inodos limpiado")
+	Output.Println("✓ Bitmap de Inodos limpiado")
 
 	err = ls.clearBlockBitmap(file)
 	if err != nil {
 		return err
 	}
-	fmt.Println("✓ Bitmap de Bloques limpiado")
+	Output.Println("✓ Bitmap de Bloques limpiado")
 
 	err = ls.clearInodeArea(file)
 	if err != nil {
 		return err
 	}
-	fmt.Println("✓ Área de Inodos limpiada")
+	Output.Println("✓ Área de Inodos limpiada")
 
 	err = ls.clearBlockArea(file)
 	if err != nil {
 		return err
 	}
-	fmt.Println("✓ Área de Bloques limpiada")
+	Output.Println("✓ Área de Bloques limpiada")
+
+	// La partición cambió completa: los reportes en caché ya no la representan
+	err = touchSuperBlockMtime(file, ls.partitionInfo.PartStart)
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("Pérdida del sistema simulada exitosamente")
+	Output.Println("Pérdida del sistema simulada exitosamente")
 	return nil
 }
 
-func (ls *LossSimulator) clearInodeBitmap(file *os.File) error {
+func (ls *LossSimulator) clearInodeBitmap(file *Device.Handle) error {
 	bitmapSize := ls.superBloque.S_inodes_count
 	bitmapPos := ls.partitionInfo.PartStart + int64(ls.superBloque.S_bm_inode_start)
 
@@ -77,7 +90,7 @@ func (ls *LossSimulator) clearInodeBitmap(file *os.File) error {
 	return err
 }
 
-func (ls *LossSimulator) clearBlockBitmap(file *os.File) error {
+func (ls *LossSimulator) clearBlockBitmap(file *Device.Handle) error {
 	bitmapSize := ls.superBloque.S_blocks_count
 	bitmapPos := ls.partitionInfo.PartStart + int64(ls.superBloque.S_bm_block_start)
 
@@ -87,7 +100,7 @@ func (ls *LossSimulator) clearBlockBitmap(file *os.File) error {
 	return err
 }
 
-func (ls *LossSimulator) clearInodeArea(file *os.File) error {
+func (ls *LossSimulator) clearInodeArea(file *Device.Handle) error {
 	inodeCount := ls.superBloque.S_inodes_count
 	inodeSize := int64(Models.GetInodoSize())
 	inodeAreaSize := int64(inodeCount) * inodeSize
@@ -99,7 +112,7 @@ func (ls *LossSimulator) clearInodeArea(file *os.File) error {
 	return err
 }
 
-func (ls *LossSimulator) clearBlockArea(file *os.File) error {
+func (ls *LossSimulator) clearBlockArea(file *Device.Handle) error {
 	blockCount := ls.superBloque.S_blocks_count
 	blockSize := int64(Models.GetBloqueSize())
 	blockAreaSize := int64(blockCount) * blockSize
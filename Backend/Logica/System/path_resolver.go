@@ -0,0 +1,184 @@
+package System
+
+import (
+	"MIA_2S2025_P1_202105668/Models"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Resolución de rutas con caché de entradas (dentry cache).
+//
+// Cada partición tiene un PathResolver que recuerda, por carpeta, a qué inodo lleva
+// cada nombre ya buscado (positivo) o que el nombre no existe (negativo). Resolver
+// /a/b/c solo lee del disco los componentes que no están en caché.
+//
+// La caché se invalida en los mismos puntos que modifican carpetas:
+// DirectoryEntryAdded y DirectoryEntryRemoved (mkdir, mkfile, copy, move, rename,
+// remove) olvidan el nombre afectado, y al ocupar o liberar un inodo se olvida todo lo
+// que se sabía de él como carpeta. mkfs, loss, unmount, mkdisk y rmdisk descartan la
+// caché completa.
+
+// DentryCacheSize es la cantidad máxima de entradas por partición; al superarla se vacía
+const DentryCacheSize = 4096
+
+// errEntryNotFound indica que la carpeta no tiene una entrada con ese nombre
+var errEntryNotFound = errors.New("archivo no encontrado")
+
+// dentryKey identifica un nombre dentro de una carpeta
+type dentryKey struct {
+	dir  int32
+	name string
+}
+
+// partitionKey identifica una partición por disco y posición de inicio
+type partitionKey struct {
+	diskPath string
+	start    int64
+}
+
+// PathResolver guarda las entradas resueltas de una partición.
+// Un valor FREE_INODE indica que el nombre no existe en la carpeta.
+type PathResolver struct {
+	mu      sync.Mutex
+	entries map[dentryKey]int32
+}
+
+var (
+	resolversMu sync.Mutex
+	resolvers   = make(map[partitionKey]*PathResolver)
+)
+
+// LookupPath resuelve una ruta absoluta a su número de inodo usando la caché de la partición
+func (f *EXT2FileManager) LookupPath(filePath string) (int32, error) {
+	resolver := f.resolver()
+	currentInode := int32(Models.ROOT_INODE)
+
+	for _, part := range strings.Split(strings.Trim(filePath, "/"), "/") {
+		if part == "" {
+			continue
+		}
+
+		if next, ok := resolver.lookup(currentInode, part); ok {
+			if next == Models.FREE_INODE {
+				return -1, errEntryNotFound
+			}
+			currentInode = next
+			continue
+		}
+
+		inodo, err := f.readInode(currentInode)
+		if err != nil {
+			return -1, err
+		}
+		if inodo.I_type != Models.INODO_DIRECTORIO {
+			return -1, errors.New("no es un directorio")
+		}
+
+		next, err := f.findInDirectory(inodo, part)
+		if err != nil && err != errEntryNotFound {
+			return -1, err
+		}
+
+		// "." y ".." no se guardan: move cambia ".." sin pasar por DirectoryEntryAdded
+		if part != "." && part != ".." {
+			resolver.store(currentInode, part, next)
+		}
+		if err != nil {
+			return -1, err
+		}
+		currentInode = next
+	}
+
+	return currentInode, nil
+}
+
+// DirectoryEntryAdded se llama después de escribir una entrada nueva en el bloque slot
+// de la carpeta: olvida el nombre en la caché de rutas y actualiza el índice de hash
+func (f *EXT2FileManager) DirectoryEntryAdded(dirInodeNum int32, dirInodo *Models.Inodo, slot int, name string) error {
+	f.resolver().forget(dirInodeNum, name)
+	return f.indexDirectoryEntry(dirInodo, slot, name)
+}
+
+// DirectoryEntryRemoved se llama después de quitar name del bloque slot de la carpeta.
+// dirBlock es el contenido del bloque ya sin la entrada.
+func (f *EXT2FileManager) DirectoryEntryRemoved(dirInodeNum int32, dirInodo *Models.Inodo, slot int, dirBlock *Models.BloqueCarpeta, name string) error {
+	f.resolver().forget(dirInodeNum, name)
+	return f.unindexDirectoryEntry(dirInodo, slot, dirBlock, name)
+}
+
+// DropPathCache descarta la caché de rutas de todas las particiones del disco
+func DropPathCache(diskPath string) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+
+	for key := range resolvers {
+		if key.diskPath == diskPath {
+			delete(resolvers, key)
+		}
+	}
+}
+
+// dropPartitionPathCache descarta la caché de rutas de una partición (mkfs, loss)
+func dropPartitionPathCache(diskPath string, start int64) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	delete(resolvers, partitionKey{diskPath: diskPath, start: start})
+}
+
+// resolver retorna la caché de rutas de la partición del manager, creándola si no existe
+func (f *EXT2FileManager) resolver() *PathResolver {
+	key := partitionKey{diskPath: f.manager.diskPath, start: f.manager.partitionInfo.PartStart}
+
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+
+	resolver, ok := resolvers[key]
+	if !ok {
+		resolver = &PathResolver{entries: make(map[dentryKey]int32)}
+		resolvers[key] = resolver
+	}
+	return resolver
+}
+
+func (r *PathResolver) lookup(dir int32, name string) (int32, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	inode, ok := r.entries[dentryKey{dir: dir, name: name}]
+	return inode, ok
+}
+
+func (r *PathResolver) store(dir int32, name string, inode int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.entries) >= DentryCacheSize {
+		r.entries = make(map[dentryKey]int32)
+	}
+	r.entries[dentryKey{dir: dir, name: name}] = inode
+}
+
+// forget olvida un nombre de la carpeta, también en la forma en que se guarda en
+// B_name (máximo 12 bytes), que es la que encuentran las búsquedas
+func (r *PathResolver) forget(dir int32, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stored [12]byte
+	delete(r.entries, dentryKey{dir: dir, name: name})
+	delete(r.entries, dentryKey{dir: dir, name: string(stored[:copy(stored[:], name)])})
+}
+
+// forgetDirectory olvida todas las entradas de una carpeta y las que apuntan a ella
+// (el inodo se liberó o se va a reutilizar)
+func (r *PathResolver) forgetDirectory(inode int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, target := range r.entries {
+		if key.dir == inode || target == inode {
+			delete(r.entries, key)
+		}
+	}
+}
@@ -1,17 +1,13 @@
 package System
 
-import (
-	"MIA_2S2025_P1_202105668/Models"
-)
-
 // ValidateFileReadPermission valida permisos de lectura para un archivo
-func ValidateFileReadPermission(fileOwnerID, fileGroupID int32, permissions [3]byte, userID, userGroupID int) bool {
+func ValidateFileReadPermission(fileOwnerID, fileGroupID int32, permissions [3]byte, userID int, userGroupIDs []int) bool {
 	// Si es root (UserID = 1), siempre tiene permisos
 	if userID == 1 {
 		return true
 	}
 
-	perms := Models.GetPermissions(permissions)
+	perms := octalPermissions(permissions)
 
 	// Determinar categoría del usuario
 	if int32(userID) == fileOwnerID {
@@ -19,8 +15,8 @@ func ValidateFileReadPermission(fileOwnerID, fileGroupID int32, permissions [3]b
 		return (perms & 0400) != 0 // 0400 = 100 000 000 (r-- --- ---)
 	}
 
-	if int32(userGroupID) == fileGroupID {
-		// Usuario del mismo grupo - verificar bit de lectura del grupo (-r-)
+	if containsGroupID(userGroupIDs, fileGroupID) {
+		// Usuario de alguno de los grupos del archivo - verificar bit de lectura del grupo (-r-)
 		return (perms & 0040) != 0 // 0040 = 000 100 000 (--- r-- ---)
 	}
 
@@ -29,13 +25,13 @@ func ValidateFileReadPermission(fileOwnerID, fileGroupID int32, permissions [3]b
 }
 
 // ValidateFileWritePermission valida permisos de escritura para un archivo/directorio
-func ValidateFileWritePermission(fileOwnerID, fileGroupID int32, permissions [3]byte, userID, userGroupID int) bool {
+func ValidateFileWritePermission(fileOwnerID, fileGroupID int32, permissions [3]byte, userID int, userGroupIDs []int) bool {
 	// Si es root (UserID = 1), siempre tiene permisos
 	if userID == 1 {
 		return true
 	}
 
-	perms := Models.GetPermissions(permissions)
+	perms := octalPermissions(permissions)
 
 	// Determinar categoría del usuario
 	if int32(userID) == fileOwnerID {
@@ -43,8 +39,8 @@ func ValidateFileWritePermission(fileOwnerID, fileGroupID int32, permissions [3]
 		return (perms & 0200) != 0 // 0200 = 010 000 000 (-w- --- ---)
 	}
 
-	if int32(userGroupID) == fileGroupID {
-		// Usuario del mismo grupo - verificar bit de escritura del grupo (-w-)
+	if containsGroupID(userGroupIDs, fileGroupID) {
+		// Usuario de alguno de los grupos del archivo - verificar bit de escritura del grupo (-w-)
 		return (perms & 0020) != 0 // 0020 = 000 010 000 (--- -w- ---)
 	}
 
@@ -53,13 +49,13 @@ func ValidateFileWritePermission(fileOwnerID, fileGroupID int32, permissions [3]
 }
 
 // ValidateFileExecutePermission valida permisos de ejecución para un archivo
-func ValidateFileExecutePermission(fileOwnerID, fileGroupID int32, permissions [3]byte, userID, userGroupID int) bool {
+func ValidateFileExecutePermission(fileOwnerID, fileGroupID int32, permissions [3]byte, userID int, userGroupIDs []int) bool {
 	// Si es root (UserID = 1), siempre tiene permisos
 	if userID == 1 {
 		return true
 	}
 
-	perms := Models.GetPermissions(permissions)
+	perms := octalPermissions(permissions)
 
 	// Determinar categoría del usuario
 	if int32(userID) == fileOwnerID {
@@ -67,11 +63,26 @@ func ValidateFileExecutePermission(fileOwnerID, fileGroupID int32, permissions [
 		return (perms & 0100) != 0 // 0100 = 001 000 000 (--x --- ---)
 	}
 
-	if int32(userGroupID) == fileGroupID {
-		// Usuario del mismo grupo - verificar bit de ejecución del grupo (--x)
+	if containsGroupID(userGroupIDs, fileGroupID) {
+		// Usuario de alguno de los grupos del archivo - verificar bit de ejecución del grupo (--x)
 		return (perms & 0010) != 0 // 0010 = 000 001 000 (--- --x ---)
 	}
 
 	// Otro usuario - verificar bit de ejecución de otros (--x)
 	return (perms & 0001) != 0 // 0001 = 000 000 001 (--- --- --x)
-}
\ No newline at end of file
+}
+
+// octalPermissions convierte el formato [7,5,5] al valor octal 0755 para evaluar los bits UGO
+func octalPermissions(permissions [3]byte) int32 {
+	return int32(permissions[0])<<6 | int32(permissions[1])<<3 | int32(permissions[2])
+}
+
+// containsGroupID indica si alguno de los grupos del usuario (principal o secundarios) es el del archivo
+func containsGroupID(userGroupIDs []int, fileGroupID int32) bool {
+	for _, groupID := range userGroupIDs {
+		if int32(groupID) == fileGroupID {
+			return true
+		}
+	}
+	return false
+}
@@ -1,10 +1,11 @@
 package System
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
 	"fmt"
-	"os"
 	"strings"
 )
 
@@ -23,7 +24,7 @@ func NewRecoveryManager(diskPath string, partitionInfo *Models.Partition) *Recov
 }
 
 func (rm *RecoveryManager) RecoverFileSystem() error {
-	file, err := os.Open(rm.diskPath)
+	file, err := Device.Open(rm.diskPath)
 	if err != nil {
 		return err
 	}
@@ -49,7 +50,7 @@ func (rm *RecoveryManager) RecoverFileSystem() error {
 	}
 
 	if len(entries) == 0 {
-		fmt.Println("No hay entradas en el journal para recuperar")
+		Output.Println("No hay entradas en el journal para recuperar")
 		return nil
 	}
 
@@ -63,11 +64,11 @@ func (rm *RecoveryManager) RecoverFileSystem() error {
 	}
 
 	if lastFormatIndex == -1 {
-		fmt.Println("No se encontró operación de formato en el journal")
+		Output.Println("No se encontró operación de formato en el journal")
 		return nil
 	}
 
-	fmt.Printf("Recuperando sistema de archivos al estado anterior al formato (entrada %d)...\n", lastFormatIndex)
+	Output.Printf("Recuperando sistema de archivos al estado anterior al formato (entrada %d)...\n", lastFormatIndex)
 
 	// Crear MountInfo temporal para inicializar el gestor EXT3
 	tempMountInfo := &MountInfo{
@@ -88,6 +89,9 @@ func (rm *RecoveryManager) RecoverFileSystem() error {
 	rm.ext3Manager.EXT2Manager.superBloque = &sb
 	rm.ext3Manager.EXT2Manager.partitionInfo = rm.partitionInfo
 	rm.ext3Manager.journalManager = journalManager
+	// Conservar las opciones con las que se formateó
+	rm.ext3Manager.SetBlockGroups(sb.S_groups_count)
+	rm.ext3Manager.SetDirectoryIndex(sb.S_dir_index == 1)
 
 	entriesToRecover := entries[:lastFormatIndex]
 
@@ -102,15 +106,15 @@ func (rm *RecoveryManager) RecoverFileSystem() error {
 		path := strings.TrimRight(string(entry.I_path[:]), "\x00")
 		content := strings.TrimRight(string(entry.I_content[:]), "\x00")
 
-		fmt.Printf("[%d/%d] Recuperando operación: %s en %s\n", i+1, len(entriesToRecover), operation, path)
+		Output.Printf("[%d/%d] Recuperando operación: %s en %s\n", i+1, len(entriesToRecover), operation, path)
 
 		err := rm.replayOperation(operation, path, content)
 		if err != nil {
-			fmt.Printf("  ADVERTENCIA: No se pudo recuperar operación %s: %v\n", operation, err)
+			Output.Printf("  ADVERTENCIA: No se pudo recuperar operación %s: %v\n", operation, err)
 		}
 	}
 
-	fmt.Println("Recuperación del sistema de archivos completada")
+	Output.Println("Recuperación del sistema de archivos completada")
 	return nil
 }
 
@@ -126,6 +130,8 @@ func (rm *RecoveryManager) replayOperation(operation, path, content string) erro
 		return rm.recoverRemove(fileManager, path)
 	case "edit":
 		return rm.recoverEdit(fileManager, path, content)
+	case "restore":
+		return rm.recoverRestore(fileManager, path, content)
 	default:
 		return nil
 	}
@@ -157,8 +163,8 @@ func (rm *RecoveryManager) recoverMkdir(fileManager *EXT2FileManager, path strin
 			}
 
 			parentInodeNum, _ := fileManager.findFileInode(parentPath)
-			freeInode, _ := fileManager.findFreeInode()
-			freeBlock, _ := fileManager.findFreeBlock()
+			freeInode, _ := fileManager.FindFreeInode(parentInodeNum)
+			freeBlock, _ := fileManager.FindFreeBlock(freeInode)
 
 			newDirInodo := Models.Inodo{
 				I_uid:   1,
@@ -189,6 +195,7 @@ func (rm *RecoveryManager) recoverMkdir(fileManager *EXT2FileManager, path strin
 			fileManager.writeDirectoryBlock(freeBlock, &dirBlock)
 			fileManager.markInodeAsUsed(freeInode)
 			fileManager.markBlockAsUsed(freeBlock)
+			fileManager.CreateDirectoryIndex(freeInode, &newDirInodo)
 			fileManager.addEntryToDirectory(parentInodeNum, part, freeInode)
 		}
 	}
@@ -216,6 +223,18 @@ func (rm *RecoveryManager) recoverRemove(fileManager *EXT2FileManager, path stri
 	return nil
 }
 
+// recoverRestore asegura que exista la entrada restaurada desde la papelera. El journal
+// no guarda su contenido, así que si falta se recrea vacía según el tipo registrado.
+func (rm *RecoveryManager) recoverRestore(fileManager *EXT2FileManager, path, entryType string) error {
+	if _, err := fileManager.findFileInode(path); err == nil {
+		return nil
+	}
+	if entryType == "dir" {
+		return rm.recoverMkdir(fileManager, path)
+	}
+	return rm.recoverMkfile(fileManager, path, "")
+}
+
 func (rm *RecoveryManager) recoverEdit(fileManager *EXT2FileManager, path, content string) error {
 	_, err := fileManager.findFileInode(path)
 	if err != nil {
@@ -0,0 +1,282 @@
+package System
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Models"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Copias de respaldo del superbloque.
+//
+// mkfs reserva en el bitmap de bloques superBlockBackupCount tramos de
+// superBlockBackupSpan bloques, que empiezan en k*S_blocks_count/(superBlockBackupCount+1)
+// para k = 1..superBlockBackupCount, y copia ahí el superbloque. La distribución de la
+// partición depende de su tamaño, del tipo (EXT2 o EXT3) y de la cantidad de inodos;
+// recovery -sb prueba las cantidades que mkfs pudo calcular (layoutCandidates) sin leer
+// el superbloque principal. Las copias se actualizan en sync y unmount. Un superbloque
+// (principal o copia) es válido si tiene EXT2_MAGIC y sus posiciones coinciden con las
+// que resultan de sus propios contadores (validLayout): así se aceptan imágenes
+// formateadas cuando los structs tenían otro tamaño y mkfs daba otra cantidad de inodos.
+
+const (
+	superBlockBackupCount = 2
+	usersFileBlock        = 100 // Primer bloque fijo de users.txt (createUsersFile)
+	usersFileBlocks       = 2   // Bloques del users.txt inicial, con la contraseña de root cifrada
+)
+
+// superBlockBackupSpan es la cantidad de bloques que ocupa cada copia
+var superBlockBackupSpan = int32((binary.Size(Models.SuperBloque{}) + Models.BLOQUE_SIZE - 1) / Models.BLOQUE_SIZE)
+
+// superBlockBackupBlocks retorna el primer bloque de cada copia. Se omiten las que no
+// caben en el área de bloques o en la partición, y las que pisarían la raíz o users.txt.
+func superBlockBackupBlocks(sb *Models.SuperBloque, partition *Models.Partition) []int32 {
+	var blocks []int32
+	for k := int32(1); k <= superBlockBackupCount; k++ {
+		first := k * sb.S_blocks_count / (superBlockBackupCount + 1)
+		end := first + superBlockBackupSpan
+		if first < 1 || end > sb.S_blocks_count || (first < usersFileBlock+usersFileBlocks && usersFileBlock < end) {
+			continue
+		}
+		if int64(sb.S_block_start)+int64(end)*Models.BLOQUE_SIZE > partition.PartSize {
+			continue
+		}
+		blocks = append(blocks, first)
+	}
+	return blocks
+}
+
+// superBlockBackupOffset retorna la posición absoluta de la copia que empieza en block
+func superBlockBackupOffset(sb *Models.SuperBloque, partition *Models.Partition, block int32) int64 {
+	return partition.PartStart + int64(sb.S_block_start) + int64(block)*Models.BLOQUE_SIZE
+}
+
+// layoutFor retorna el superbloque que mkfs crea para el tipo indicado (2 o 3) con
+// inodesCount inodos
+func layoutFor(fsType int32, inodesCount int32) *Models.SuperBloque {
+	sb := Models.NewSuperBloque(inodesCount, 3*inodesCount)
+	if fsType == 3 {
+		sb = newEXT3SuperBloque(inodesCount)
+	}
+	return &sb
+}
+
+// bytesPerInode es lo máximo que mkfs reparte por cada inodo: bitmaps, inodo, 3 bloques
+// y, en EXT3, su parte del journal
+func bytesPerInode(fsType int32) int64 {
+	perInode := int64(4 + Models.INODO_SIZE + 3*Models.BLOQUE_SIZE)
+	if fsType == 3 {
+		perInode += ext3JournalingConstant
+	}
+	return perInode
+}
+
+// minInodesCount es la menor cantidad de inodos que mkfs pudo calcular para la partición.
+// La fórmula divide entre los tamaños de los structs SuperBloque e Inodo, que cambiaron
+// entre versiones pero nunca superan SUPERBLOQUE_SIZE e INODO_SIZE.
+func minInodesCount(partition *Models.Partition, fsType int32) int64 {
+	n := (partition.PartSize - Models.SUPERBLOQUE_SIZE) / bytesPerInode(fsType)
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// validLayout verifica el número mágico, los tamaños de inodo y bloque, y que las
+// posiciones de sb sean las que mkfs calcula para sus contadores, con el área de
+// bloques empezando dentro de la partición
+func validLayout(sb *Models.SuperBloque, partition *Models.Partition) bool {
+	if sb.S_magic != Models.EXT2_MAGIC ||
+		(sb.S_filesystem_type != 2 && sb.S_filesystem_type != 3) ||
+		sb.S_inode_s != Models.INODO_SIZE || sb.S_block_s != Models.BLOQUE_SIZE ||
+		sb.S_blocks_count != 3*sb.S_inodes_count ||
+		int64(sb.S_inodes_count) < minInodesCount(partition, sb.S_filesystem_type) {
+		return false
+	}
+
+	expected := layoutFor(sb.S_filesystem_type, sb.S_inodes_count)
+	return sb.S_bm_inode_start == expected.S_bm_inode_start &&
+		sb.S_bm_block_start == expected.S_bm_block_start &&
+		sb.S_inode_start == expected.S_inode_start &&
+		sb.S_block_start == expected.S_block_start &&
+		sb.S_journal_start == expected.S_journal_start &&
+		int64(sb.S_block_start) < partition.PartSize
+}
+
+// layoutCandidates retorna las distribuciones que mkfs pudo calcular para la partición
+// con el tipo indicado y que tienen al menos una copia del superbloque
+func layoutCandidates(partition *Models.Partition, fsType int32) []*Models.SuperBloque {
+	var candidates []*Models.SuperBloque
+	for n := minInodesCount(partition, fsType); ; n++ {
+		candidate := layoutFor(fsType, int32(n))
+		if !validLayout(candidate, partition) {
+			break
+		}
+		if len(superBlockBackupBlocks(candidate, partition)) > 0 {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// reserveSuperBlockBackups marca como usados los bloques de las copias. El formato la
+// llama antes de asignar cualquier bloque con FindFreeBlock.
+func (e *EXT2Manager) reserveSuperBlockBackups() error {
+	fileManager := NewEXT2FileManager(e)
+	for _, block := range superBlockBackupBlocks(e.superBloque, e.partitionInfo) {
+		for i := int32(0); i < superBlockBackupSpan; i++ {
+			if err := fileManager.markBlockAsUsed(block + i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SyncSuperBlockBackups copia el superbloque principal en sus copias de respaldo.
+// Se llama en sync y unmount.
+func SyncSuperBlockBackups(mountInfo *MountInfo) error {
+	manager := &EXT2Manager{mountInfo: mountInfo, diskPath: mountInfo.DiskPath}
+	if err := manager.LoadPartitionInfo(); err != nil {
+		return nil
+	}
+	return syncSuperBlockBackups(manager.diskPath, manager.partitionInfo)
+}
+
+// syncSuperBlockBackups lee el superbloque principal del disco y lo escribe en cada copia.
+// Si el principal no es válido no se toca nada, para no reemplazar copias buenas. Tampoco
+// se escribe en copias cuyos bloques no están reservados: la partición se formateó antes
+// de existir las copias y esos bloques pueden tener datos.
+func syncSuperBlockBackups(diskPath string, partition *Models.Partition) error {
+	manager := &EXT2Manager{diskPath: diskPath, partitionInfo: partition}
+	if err := manager.LoadSuperBlock(); err != nil {
+		return err
+	}
+	sb := manager.superBloque
+	if !validLayout(sb, partition) {
+		return nil
+	}
+
+	bitmap, err := NewEXT2FileManager(manager).readBlockBitmap()
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	if err := binary.Write(&buffer, binary.LittleEndian, sb); err != nil {
+		return err
+	}
+	for _, block := range superBlockBackupBlocks(sb, partition) {
+		if !Models.IsBitmapBitSet(bitmap, int(block)) || !Models.IsBitmapBitSet(bitmap, int(block+superBlockBackupSpan-1)) {
+			continue
+		}
+		if err := Device.WriteThrough(diskPath, buffer.Bytes(), superBlockBackupOffset(sb, partition, block)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreSuperBlock reconstruye el superbloque principal desde la copia válida más
+// reciente (recovery -sb). Los contadores libres y las pistas de asignación de la copia
+// pueden ser de antes del último sync, así que se recalculan desde los bitmaps. En EXT3
+// aplica después el registro de intenciones, que el montaje no pudo leer sin superbloque.
+func RestoreSuperBlock(mountInfo *MountInfo) error {
+	manager := &EXT2Manager{mountInfo: mountInfo, diskPath: mountInfo.DiskPath}
+	if err := manager.LoadPartitionInfo(); err != nil {
+		return err
+	}
+	partition := manager.partitionInfo
+
+	file, err := Device.Open(manager.diskPath)
+	if err != nil {
+		return err
+	}
+
+	var primary Models.SuperBloque
+	file.Seek(partition.PartStart, 0)
+	if binary.Read(file, binary.LittleEndian, &primary) == nil && validLayout(&primary, partition) {
+		file.Close()
+		Output.Println("El superbloque principal es válido, no se modificó")
+		return nil
+	}
+
+	var best *Models.SuperBloque
+	var bestBlock int32
+	for _, fsType := range []int32{2, 3} {
+		for _, candidate := range layoutCandidates(partition, fsType) {
+			for _, block := range superBlockBackupBlocks(candidate, partition) {
+				var backup Models.SuperBloque
+				file.Seek(superBlockBackupOffset(candidate, partition, block), 0)
+				if binary.Read(file, binary.LittleEndian, &backup) != nil || !validLayout(&backup, partition) ||
+					backup.S_filesystem_type != fsType || backup.S_inodes_count != candidate.S_inodes_count {
+					continue
+				}
+				if best == nil || backup.S_mtime > best.S_mtime {
+					best = &backup
+					bestBlock = block
+				}
+			}
+		}
+	}
+	file.Close()
+
+	if best == nil {
+		return errors.New("ERROR: no se encontró ninguna copia válida del superbloque")
+	}
+	Output.Printf("Copia del superbloque encontrada en el bloque %d (EXT%d, modificada %s)\n",
+		bestBlock, best.S_filesystem_type, time.Unix(int64(best.S_mtime), 0).Format("2006-01-02 15:04:05"))
+
+	manager.superBloque = best
+	fileManager := NewEXT2FileManager(manager)
+	inodeBitmap, err := fileManager.readInodeBitmap()
+	if err != nil {
+		return err
+	}
+	blockBitmap, err := fileManager.readBlockBitmap()
+	if err != nil {
+		return err
+	}
+
+	freeInodes := best.S_inodes_count - countUsedBits(inodeBitmap, best.S_inodes_count)
+	freeBlocks := best.S_blocks_count - countUsedBits(blockBitmap, best.S_blocks_count)
+	if freeInodes != best.S_free_inodes_count || freeBlocks != best.S_free_blocks_count {
+		Output.Printf("Contadores libres actualizados según los bitmaps: %d inodos y %d bloques\n", freeInodes, freeBlocks)
+	}
+	best.S_free_inodes_count = freeInodes
+	best.S_free_blocks_count = freeBlocks
+	best.S_firts_ino = firstFreeBit(inodeBitmap, best.S_inodes_count)
+	best.S_first_blo = firstFreeBit(blockBitmap, best.S_blocks_count)
+	best.S_mtime = float64(time.Now().UnixNano()) / 1e9
+	best.S_state = Models.SB_STATE_DIRTY
+
+	if err := writeSuperBlockThrough(manager); err != nil {
+		return err
+	}
+	dropPartitionPathCache(manager.diskPath, partition.PartStart)
+	Output.Println("Superbloque principal reconstruido")
+
+	replayed, err := ReplayIntentLog(manager.diskPath, partition)
+	if err != nil {
+		return fmt.Errorf("error aplicando el journal: %v", err)
+	}
+	if replayed > 0 {
+		Output.Printf("Journal: %d transacción(es) pendiente(s) aplicada(s)\n", replayed)
+	}
+
+	// Las demás copias pueden estar dañadas o atrasadas
+	return syncSuperBlockBackups(manager.diskPath, partition)
+}
+
+// firstFreeBit retorna el primer bit libre del bitmap, o total si no hay
+func firstFreeBit(bitmap []byte, total int32) int32 {
+	free := Models.FindFreeBitmapBitFrom(bitmap, 0, int(total))
+	if free == -1 {
+		return total
+	}
+	return int32(free)
+}
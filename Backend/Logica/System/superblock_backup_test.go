@@ -0,0 +1,59 @@
+package System
+
+import (
+	"MIA_2S2025_P1_202105668/Models"
+	"testing"
+)
+
+// Una imagen de otra versión tiene otra cantidad de inodos para la misma partición:
+// su distribución debe seguir siendo válida y estar entre las que prueba recovery -sb
+func TestLayoutCandidatesIncludeCurrentAndOlderLayouts(t *testing.T) {
+	for _, size := range []int64{100 * 1024, 1024 * 1024, 3 * 1024 * 1024} {
+		partition := &Models.Partition{PartSize: size}
+		for _, fsType := range []int32{2, 3} {
+			manager := &EXT2Manager{partitionInfo: partition}
+			if fsType == 2 {
+				manager.calculateEXT2Layout()
+			} else {
+				(&EXT3Manager{EXT2Manager: manager}).calculateEXT3Layout()
+			}
+			current := manager.superBloque
+			older := layoutFor(fsType, int32(minInodesCount(partition, fsType)))
+
+			candidates := make(map[int32]bool)
+			for _, candidate := range layoutCandidates(partition, fsType) {
+				candidates[candidate.S_inodes_count] = true
+			}
+			for _, sb := range []*Models.SuperBloque{current, older} {
+				if !validLayout(sb, partition) {
+					t.Errorf("EXT%d de %d bytes: la distribución con %d inodos no es válida", fsType, size, sb.S_inodes_count)
+				}
+				if !candidates[sb.S_inodes_count] {
+					t.Errorf("EXT%d de %d bytes: %d inodos no está entre los candidatos", fsType, size, sb.S_inodes_count)
+				}
+			}
+		}
+	}
+}
+
+func TestValidLayoutRejectsDamagedSuperBlock(t *testing.T) {
+	partition := &Models.Partition{PartSize: 1024 * 1024}
+	sb := layoutFor(3, int32(minInodesCount(partition, 3)))
+
+	damaged := *sb
+	damaged.S_magic = 0
+	if validLayout(&damaged, partition) {
+		t.Error("se aceptó un superbloque sin EXT2_MAGIC")
+	}
+
+	damaged = *sb
+	damaged.S_inode_start++
+	if validLayout(&damaged, partition) {
+		t.Error("se aceptó un superbloque con posiciones que no corresponden a sus contadores")
+	}
+
+	damaged = *layoutFor(3, int32(partition.PartSize))
+	if validLayout(&damaged, partition) {
+		t.Error("se aceptó un superbloque con el área de bloques fuera de la partición")
+	}
+}
@@ -2,6 +2,7 @@ package Comandos
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"errors"
 	"fmt"
@@ -51,8 +52,9 @@ func (cmd *ChgrpCommand) Execute(username, newGroupName string) error {
 		return errors.New("ERROR: El grupo no existe")
 	}
 
-	// Cambiar el grupo del usuario
+	// Cambiar el grupo del usuario (si era secundario deja de serlo)
 	user.Group = newGroupName
+	user.RemoveGroup(newGroupName)
 
 	// Guardar cambios
 	return cmd.userManager.WriteUsersFile(records)
@@ -111,8 +113,9 @@ func ChGrp(params map[string]string) error {
 		return fmt.Errorf("ERROR: El grupo '%s' no existe", grp)
 	}
 
-	// Cambiar el grupo del usuario
+	// Cambiar el grupo del usuario (si era secundario deja de serlo)
 	user.Group = grp
+	user.RemoveGroup(grp)
 
 	// Guardar cambios
 	err = userManager.WriteUsersFile(records)
@@ -120,6 +123,6 @@ func ChGrp(params map[string]string) error {
 		return err
 	}
 
-	fmt.Printf("Grupo de usuario '%s' cambiado a '%s' exitosamente\n", usr, grp)
+	Output.Printf("Grupo de usuario '%s' cambiado a '%s' exitosamente\n", usr, grp)
 	return nil
 }
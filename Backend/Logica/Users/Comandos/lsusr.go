@@ -0,0 +1,96 @@
+package Comandos
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LsUsr - Función exportada para comando lsusr
+// Lista los usuarios activos de la partición de la sesión como tabla o JSON (-format=json).
+func LsUsr(params map[string]string) error {
+	asJSON, err := parseListFormat(params)
+	if err != nil {
+		return err
+	}
+
+	mountID, err := sessionMountID()
+	if err != nil {
+		return err
+	}
+
+	users, err := ListUsers(mountID)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printJSON(users)
+	}
+
+	Output.Printf("%-4s %-10s %-10s %s\n", "ID", "USUARIO", "GRUPO", "SECUNDARIOS")
+	for _, user := range users {
+		Output.Printf("%-4d %-10s %-10s %s\n", user.ID, user.Username, user.Group, formatNameList(user.Groups))
+	}
+	return nil
+}
+
+// LsGrp - Función exportada para comando lsgrp
+// Lista los grupos activos de la partición de la sesión como tabla o JSON (-format=json).
+func LsGrp(params map[string]string) error {
+	asJSON, err := parseListFormat(params)
+	if err != nil {
+		return err
+	}
+
+	mountID, err := sessionMountID()
+	if err != nil {
+		return err
+	}
+
+	groups, err := ListGroups(mountID)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printJSON(groups)
+	}
+
+	Output.Printf("%-4s %-10s %s\n", "ID", "GRUPO", "MIEMBROS")
+	for _, group := range groups {
+		Output.Printf("%-4d %-10s %s\n", group.ID, group.Name, formatNameList(group.Members))
+	}
+	return nil
+}
+
+// parseListFormat interpreta -format (table por defecto o json)
+func parseListFormat(params map[string]string) (bool, error) {
+	switch strings.ToLower(params["format"]) {
+	case "", "table":
+		return false, nil
+	case "json":
+		return true, nil
+	default:
+		return false, fmt.Errorf("valor de -format debe ser: table o json")
+	}
+}
+
+// printJSON imprime el valor con sangría
+func printJSON(value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error generando JSON: %v", err)
+	}
+	Output.Println(string(data))
+	return nil
+}
+
+// formatNameList une los nombres con coma o muestra "-" si no hay ninguno
+func formatNameList(names []string) string {
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ", ")
+}
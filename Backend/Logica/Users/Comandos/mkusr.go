@@ -2,11 +2,14 @@ package Comandos
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Models"
 	"errors"
 	"fmt"
+	"path"
 )
 
 // MkusrCommand maneja la creacion de usuarios
@@ -129,12 +132,35 @@ func MkUsr(params map[string]string) error {
 
 	userManager := Users.NewUserManager(mountInfo.DiskPath, partitionInfo, superBloque)
 
+	// -home crea la carpeta personal del usuario
+	_, createHome := params["home"]
+	home := Users.HomeDirectory(user)
+
+	manager := &System.EXT2Manager{}
+	manager.SetPartitionInfo(partitionInfo)
+	manager.SetSuperBlock(superBloque)
+	manager.SetDiskPath(mountInfo.DiskPath)
+	dirManager := System.NewEXT2DirectoryManager(manager)
+
+	if createHome {
+		if _, err := dirManager.GetDirectoryInfo(home); err == nil {
+			return fmt.Errorf("ERROR: La carpeta '%s' ya existe", home)
+		}
+	}
+
 	// Usar la lógica existente del UserManager
 	err = userManager.CreateUser(user, grp, password)
 	if err != nil {
 		return err
 	}
 
+	if createHome {
+		if err := createHomeDirectory(userManager, dirManager, user, home); err != nil {
+			return err
+		}
+		Events.EmitFSChange(session.MountID, Events.ActionCreated, home)
+	}
+
 	// Si es EXT3, registrar en el journal
 	if superBloque.S_filesystem_type == 3 {
 		systemMountInfo := &System.MountInfo{
@@ -150,6 +176,38 @@ func MkUsr(params map[string]string) error {
 		}
 	}
 
-	fmt.Printf("User: \"%s\" creado en el grupo \"%s\"\n", user, grp)
+	Output.Printf("User: \"%s\" creado en el grupo \"%s\"\n", user, grp)
+	return nil
+}
+
+// createHomeDirectory crea /home si no existe y la carpeta personal del usuario con permisos 700
+func createHomeDirectory(userManager *Users.UserManager, dirManager *System.EXT2DirectoryManager, username string, home string) error {
+	records, err := userManager.ReadUsersFile()
+	if err != nil {
+		return err
+	}
+
+	user := userManager.FindUserByName(records, username)
+	if user == nil {
+		return fmt.Errorf("ERROR: El usuario '%s' no existe", username)
+	}
+	group := userManager.FindGroupByName(records, user.Group)
+	if group == nil {
+		return fmt.Errorf("ERROR: El grupo '%s' no existe", user.Group)
+	}
+
+	// La carpeta /home pertenece a root
+	homeParent := path.Dir(home)
+	if _, err := dirManager.GetDirectoryInfo(homeParent); err != nil {
+		if err := dirManager.CreateDirectory(homeParent, 1, 1, 755); err != nil {
+			return fmt.Errorf("ERROR: No se pudo crear '%s': %v", homeParent, err)
+		}
+	}
+
+	if err := dirManager.CreateDirectory(home, int32(user.ID), int32(group.ID), 700); err != nil {
+		return fmt.Errorf("ERROR: No se pudo crear '%s': %v", home, err)
+	}
+
+	Output.Printf("Carpeta personal '%s' creada\n", home)
 	return nil
 }
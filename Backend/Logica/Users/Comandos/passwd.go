@@ -0,0 +1,95 @@
+package Comandos
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"fmt"
+)
+
+// Passwd - Función exportada para comando passwd
+// Un usuario cambia su propia contraseña indicando -old; root puede restablecer la de cualquiera.
+func Passwd(params map[string]string) error {
+	newPassword, hasNew := params["new"]
+	if !hasNew || newPassword == "" {
+		return fmt.Errorf("parametro -new requerido")
+	}
+
+	// Verificar sesión activa
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return fmt.Errorf("ERROR: Debe iniciar sesión para usar este comando")
+	}
+
+	// Sin -user se cambia la contraseña del usuario de la sesión
+	username := params["user"]
+	if username == "" {
+		username = session.Username
+	}
+
+	isRoot := session.Username == "root"
+	if !isRoot && username != session.Username {
+		return fmt.Errorf("ERROR: Solo el usuario root puede cambiar la contraseña de otros usuarios")
+	}
+
+	if len(newPassword) > 10 {
+		return fmt.Errorf("ERROR: La contraseña no puede exceder 10 caracteres")
+	}
+
+	// Obtener UserManager para la sesión activa
+	mountInfo, err := Disk.GetMountInfoByID(session.MountID)
+	if err != nil {
+		return fmt.Errorf("partición no encontrada: %v", err)
+	}
+
+	partitionInfo, superBloque, err := Users.GetPartitionAndSuperBlock(mountInfo)
+	if err != nil {
+		return fmt.Errorf("error accediendo al sistema de archivos: %v", err)
+	}
+
+	userManager := Users.NewUserManager(mountInfo.DiskPath, partitionInfo, superBloque)
+
+	records, err := userManager.ReadUsersFile()
+	if err != nil {
+		return err
+	}
+
+	user := userManager.FindUserByName(records, username)
+	if user == nil {
+		return fmt.Errorf("ERROR: El usuario '%s' no existe", username)
+	}
+
+	// Los usuarios normales deben confirmar su contraseña actual
+	if !isRoot {
+		oldPassword, hasOld := params["old"]
+		if !hasOld {
+			return fmt.Errorf("parametro -old requerido")
+		}
+		if !user.CheckPassword(oldPassword) {
+			return fmt.Errorf("ERROR: Contraseña actual incorrecta")
+		}
+	}
+
+	if err := userManager.ChangePassword(username, newPassword); err != nil {
+		return err
+	}
+
+	// Si es EXT3, registrar en el journal (sin la contraseña)
+	if superBloque.S_filesystem_type == 3 {
+		systemMountInfo := &System.MountInfo{
+			DiskPath:      mountInfo.DiskPath,
+			PartitionName: mountInfo.PartitionName,
+			MountID:       mountInfo.MountID,
+			DiskLetter:    mountInfo.DiskLetter,
+			PartNumber:    mountInfo.PartNumber,
+		}
+		ext3Manager := System.NewEXT3Manager(systemMountInfo)
+		if ext3Manager != nil {
+			ext3Manager.LogOperation("passwd", username, "")
+		}
+	}
+
+	Output.Printf("Contraseña de \"%s\" actualizada\n", username)
+	return nil
+}
@@ -0,0 +1,109 @@
+package Comandos
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"fmt"
+)
+
+// RenUsr - Función exportada para comando renusr
+// Cambia el nombre de un usuario conservando su ID (solo root).
+func RenUsr(params map[string]string) error {
+	usr, hasUsr := params["user"]
+	if !hasUsr || usr == "" {
+		return fmt.Errorf("parametro -user requerido")
+	}
+
+	newName, hasName := params["name"]
+	if !hasName {
+		return fmt.Errorf("parametro -name requerido")
+	}
+
+	partition, err := requireRootPartition("renombrar usuarios")
+	if err != nil {
+		return err
+	}
+
+	if usr == "root" {
+		return fmt.Errorf("ERROR: No se puede renombrar al usuario root")
+	}
+	if err := validateRecordName(newName, "usuario"); err != nil {
+		return err
+	}
+
+	if err := partition.userManager.RenameUser(usr, newName); err != nil {
+		return err
+	}
+
+	logUsersJournal(partition, "renusr", usr, newName)
+
+	Output.Printf("Usuario '%s' renombrado a '%s'\n", usr, newName)
+	return nil
+}
+
+// RenGrp - Función exportada para comando rengrp
+// Cambia el nombre de un grupo conservando su ID y actualiza a sus miembros (solo root).
+func RenGrp(params map[string]string) error {
+	grp, hasGrp := params["grp"]
+	if !hasGrp || grp == "" {
+		return fmt.Errorf("parametro -grp requerido")
+	}
+
+	newName, hasName := params["name"]
+	if !hasName {
+		return fmt.Errorf("parametro -name requerido")
+	}
+
+	partition, err := requireRootPartition("renombrar grupos")
+	if err != nil {
+		return err
+	}
+
+	if grp == "root" {
+		return fmt.Errorf("ERROR: No se puede renombrar el grupo root")
+	}
+	if err := validateRecordName(newName, "grupo"); err != nil {
+		return err
+	}
+
+	if err := partition.userManager.RenameGroup(grp, newName); err != nil {
+		return err
+	}
+
+	logUsersJournal(partition, "rengrp", grp, newName)
+
+	Output.Printf("Grupo '%s' renombrado a '%s'\n", grp, newName)
+	return nil
+}
+
+// requireRootPartition valida que la sesión activa sea de root y carga users.txt de su partición
+func requireRootPartition(action string) (*partitionUsers, error) {
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return nil, fmt.Errorf("ERROR: Debe iniciar sesión para usar este comando")
+	}
+	if session.Username != "root" {
+		return nil, fmt.Errorf("ERROR: Solo el usuario root puede %s", action)
+	}
+	return loadPartitionUsers(session.MountID)
+}
+
+// logUsersJournal registra la operación en el journal si la partición es EXT3
+func logUsersJournal(partition *partitionUsers, operation string, path string, content string) {
+	if partition.superBloque.S_filesystem_type != 3 {
+		return
+	}
+
+	systemMountInfo := &System.MountInfo{
+		DiskPath:      partition.mountInfo.DiskPath,
+		PartitionName: partition.mountInfo.PartitionName,
+		MountID:       partition.mountInfo.MountID,
+		DiskLetter:    partition.mountInfo.DiskLetter,
+		PartNumber:    partition.mountInfo.PartNumber,
+	}
+	ext3Manager := System.NewEXT3Manager(systemMountInfo)
+	if ext3Manager != nil {
+		ext3Manager.LogOperation(operation, path, content)
+	}
+}
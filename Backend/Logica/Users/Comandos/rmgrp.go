@@ -2,6 +2,7 @@ package Comandos
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"errors"
 	"fmt"
@@ -90,6 +91,6 @@ func RmGrp(params map[string]string) error {
 		return err
 	}
 
-	fmt.Printf("Grupo '%s' eliminado exitosamente\n", name)
+	Output.Printf("Grupo '%s' eliminado exitosamente\n", name)
 	return nil
 }
@@ -2,7 +2,9 @@ package Comandos
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Logica/Users/Operations"
 	"errors"
 	"fmt"
 )
@@ -84,12 +86,61 @@ func RmUsr(params map[string]string) error {
 
 	userManager := Users.NewUserManager(mountInfo.DiskPath, partitionInfo, superBloque)
 
+	// -purge elimina lo que pertenece al usuario; -reassign lo entrega a otro usuario
+	_, purge := params["purge"]
+	reassignTo, reassign := params["reassign"]
+	if purge || reassign {
+		if err := purgeUserFiles(userManager, usr, reassignTo, reassign); err != nil {
+			return err
+		}
+	}
+
 	// Usar la lógica existente del UserManager
 	err = userManager.DeleteUser(usr)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Usuario '%s' eliminado exitosamente\n", usr)
+	Output.Printf("Usuario '%s' eliminado exitosamente\n", usr)
+	return nil
+}
+
+// purgeUserFiles reasigna o elimina los archivos y carpetas cuyo propietario es el usuario
+func purgeUserFiles(userManager *Users.UserManager, username string, reassignTo string, reassign bool) error {
+	records, err := userManager.ReadUsersFile()
+	if err != nil {
+		return err
+	}
+
+	user := userManager.FindUserByName(records, username)
+	if user == nil {
+		return fmt.Errorf("ERROR: El usuario '%s' no existe", username)
+	}
+
+	if reassign {
+		newOwner := userManager.FindUserByName(records, reassignTo)
+		if newOwner == nil {
+			return fmt.Errorf("ERROR: El usuario '%s' no existe", reassignTo)
+		}
+		if newOwner.ID == user.ID {
+			return fmt.Errorf("ERROR: No se puede reasignar al mismo usuario que se elimina")
+		}
+
+		changed, err := Operations.ReassignOwnedFiles(int32(user.ID), int32(newOwner.ID))
+		if err != nil {
+			return err
+		}
+		Output.Printf("%d elementos reasignados a '%s'\n", len(changed), reassignTo)
+		return nil
+	}
+
+	removed, kept, err := Operations.PurgeOwnedFiles(int32(user.ID))
+	if err != nil {
+		return err
+	}
+	Output.Printf("%d elementos eliminados\n", len(removed))
+	for _, path := range kept {
+		Output.Printf("Carpeta conservada (contiene archivos de otros usuarios), ahora de root: %s\n", path)
+	}
 	return nil
 }
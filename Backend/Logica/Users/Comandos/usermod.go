@@ -0,0 +1,79 @@
+package Comandos
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"fmt"
+)
+
+// UserMod - Función exportada para comando usermod
+// Agrega (-addgrp) o quita (-delgrp) grupos secundarios de un usuario. Solo root puede usarlo.
+func UserMod(params map[string]string) error {
+	usr, hasUsr := params["user"]
+	if !hasUsr || usr == "" {
+		return fmt.Errorf("parametro -user requerido")
+	}
+
+	addGrp := params["addgrp"]
+	delGrp := params["delgrp"]
+	if addGrp == "" && delGrp == "" {
+		return fmt.Errorf("parametro -addgrp o -delgrp requerido")
+	}
+
+	// Verificar sesión activa
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return fmt.Errorf("ERROR: Debe iniciar sesión para usar este comando")
+	}
+
+	// Verificar permisos de usuario root
+	if session.Username != "root" {
+		return fmt.Errorf("ERROR: Solo el usuario root puede modificar los grupos de un usuario")
+	}
+
+	// Obtener UserManager para la sesión activa
+	mountInfo, err := Disk.GetMountInfoByID(session.MountID)
+	if err != nil {
+		return fmt.Errorf("partición no encontrada: %v", err)
+	}
+
+	partitionInfo, superBloque, err := Users.GetPartitionAndSuperBlock(mountInfo)
+	if err != nil {
+		return fmt.Errorf("error accediendo al sistema de archivos: %v", err)
+	}
+
+	userManager := Users.NewUserManager(mountInfo.DiskPath, partitionInfo, superBloque)
+
+	if addGrp != "" {
+		if err := userManager.AddUserToGroup(usr, addGrp); err != nil {
+			return err
+		}
+		Output.Printf("Usuario '%s' agregado al grupo '%s'\n", usr, addGrp)
+	}
+
+	if delGrp != "" {
+		if err := userManager.RemoveUserFromGroup(usr, delGrp); err != nil {
+			return err
+		}
+		Output.Printf("Usuario '%s' retirado del grupo '%s'\n", usr, delGrp)
+	}
+
+	// Si es EXT3, registrar en el journal
+	if superBloque.S_filesystem_type == 3 {
+		systemMountInfo := &System.MountInfo{
+			DiskPath:      mountInfo.DiskPath,
+			PartitionName: mountInfo.PartitionName,
+			MountID:       mountInfo.MountID,
+			DiskLetter:    mountInfo.DiskLetter,
+			PartNumber:    mountInfo.PartNumber,
+		}
+		ext3Manager := System.NewEXT3Manager(systemMountInfo)
+		if ext3Manager != nil {
+			ext3Manager.LogOperation("usermod", usr, "+"+addGrp+" -"+delGrp)
+		}
+	}
+
+	return nil
+}
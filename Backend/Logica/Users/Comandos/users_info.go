@@ -0,0 +1,182 @@
+package Comandos
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Models"
+	"fmt"
+	"strings"
+)
+
+// UserInfo describe un usuario activo de users.txt (sin su contraseña)
+type UserInfo struct {
+	ID       int      `json:"id"`
+	Username string   `json:"username"`
+	Group    string   `json:"group"`
+	Groups   []string `json:"groups"`
+}
+
+// UserDetail amplía UserInfo con la carpeta personal y los archivos propios (usrinfo)
+type UserDetail struct {
+	UserInfo
+	Home       string   `json:"home"`
+	HomeExists bool     `json:"homeExists"`
+	OwnedFiles []string `json:"ownedFiles"`
+}
+
+// GroupInfo describe un grupo activo de users.txt con sus miembros
+type GroupInfo struct {
+	ID      int      `json:"id"`
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// partitionUsers agrupa lo necesario para consultar users.txt de una partición montada
+type partitionUsers struct {
+	mountInfo   *Disk.MountInfo
+	superBloque *Models.SuperBloque
+	userManager *Users.UserManager
+	records     []*Models.UserRecord
+	dirManager  *System.EXT2DirectoryManager
+}
+
+// loadPartitionUsers lee users.txt de la partición indicada
+func loadPartitionUsers(mountID string) (*partitionUsers, error) {
+	mountInfo, err := Disk.GetMountInfoByID(mountID)
+	if err != nil {
+		return nil, fmt.Errorf("partición no encontrada: %v", err)
+	}
+
+	partitionInfo, superBloque, err := Users.GetPartitionAndSuperBlock(mountInfo)
+	if err != nil {
+		return nil, fmt.Errorf("error accediendo al sistema de archivos: %v", err)
+	}
+
+	userManager := Users.NewUserManager(mountInfo.DiskPath, partitionInfo, superBloque)
+	records, err := userManager.ReadUsersFile()
+	if err != nil {
+		return nil, err
+	}
+
+	manager := &System.EXT2Manager{}
+	manager.SetPartitionInfo(partitionInfo)
+	manager.SetSuperBlock(superBloque)
+	manager.SetDiskPath(mountInfo.DiskPath)
+
+	return &partitionUsers{
+		mountInfo:   mountInfo,
+		superBloque: superBloque,
+		userManager: userManager,
+		records:     records,
+		dirManager:  System.NewEXT2DirectoryManager(manager),
+	}, nil
+}
+
+// sessionMountID obtiene la partición de la sesión activa
+func sessionMountID() (string, error) {
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return "", fmt.Errorf("ERROR: Debe iniciar sesión para usar este comando")
+	}
+	return session.MountID, nil
+}
+
+// ListUsers retorna los usuarios activos de la partición (los registros con ID 0 se omiten)
+func ListUsers(mountID string) ([]UserInfo, error) {
+	partition, err := loadPartitionUsers(mountID)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []UserInfo{}
+	for _, record := range partition.records {
+		if record.Type != "U" || record.ID == 0 {
+			continue
+		}
+		users = append(users, newUserInfo(record))
+	}
+	return users, nil
+}
+
+// ListGroups retorna los grupos activos de la partición (los registros con ID 0 se omiten)
+func ListGroups(mountID string) ([]GroupInfo, error) {
+	partition, err := loadPartitionUsers(mountID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []GroupInfo{}
+	for _, record := range partition.records {
+		if record.Type != "G" || record.ID == 0 {
+			continue
+		}
+		groups = append(groups, GroupInfo{
+			ID:      record.ID,
+			Name:    record.Group,
+			Members: partition.userManager.GetGroupMembers(partition.records, record.Group),
+		})
+	}
+	return groups, nil
+}
+
+// GetUserInfo retorna los grupos, la carpeta personal y los archivos propios de un usuario
+func GetUserInfo(mountID string, username string) (*UserDetail, error) {
+	partition, err := loadPartitionUsers(mountID)
+	if err != nil {
+		return nil, err
+	}
+
+	user := partition.userManager.FindUserByName(partition.records, username)
+	if user == nil {
+		return nil, fmt.Errorf("ERROR: El usuario '%s' no existe", username)
+	}
+
+	info := UserDetail{
+		UserInfo:   newUserInfo(user),
+		Home:       Users.HomeDirectory(user.Username),
+		OwnedFiles: []string{},
+	}
+
+	if partition.dirManager == nil {
+		return &info, nil
+	}
+
+	// Recorrer todo el árbol buscando inodos cuyo propietario sea el usuario
+	partition.dirManager.Walk("/", func(path string, entry System.DirectoryEntry) {
+		if path == info.Home && entry.Type == Models.INODO_DIRECTORIO {
+			info.HomeExists = true
+		}
+		if int(entry.UID) == user.ID {
+			info.OwnedFiles = append(info.OwnedFiles, path)
+		}
+	})
+
+	return &info, nil
+}
+
+// newUserInfo convierte un registro de users.txt en UserInfo
+func newUserInfo(record *Models.UserRecord) UserInfo {
+	groups := append([]string{}, record.Groups...)
+	return UserInfo{
+		ID:       record.ID,
+		Username: record.Username,
+		Group:    record.Group,
+		Groups:   groups,
+	}
+}
+
+// validateRecordName valida un nombre de usuario o grupo para users.txt
+func validateRecordName(name string, label string) error {
+	if name == "" {
+		return fmt.Errorf("ERROR: El nombre del %s no puede estar vacío", label)
+	}
+	if len(name) > 10 {
+		return fmt.Errorf("ERROR: El nombre del %s no puede exceder 10 caracteres", label)
+	}
+	// La coma separa campos y el punto y coma los grupos secundarios
+	if strings.ContainsAny(name, ","+Models.SupplementaryGroupSeparator) {
+		return fmt.Errorf("ERROR: El nombre del %s no puede contener ',' ni '%s'", label, Models.SupplementaryGroupSeparator)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package Comandos
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"fmt"
+)
+
+// UsrInfo - Función exportada para comando usrinfo
+// Muestra los grupos, la carpeta personal y los archivos propios de un usuario.
+func UsrInfo(params map[string]string) error {
+	usr, hasUsr := params["user"]
+	if !hasUsr || usr == "" {
+		return fmt.Errorf("parametro -user requerido")
+	}
+
+	asJSON, err := parseListFormat(params)
+	if err != nil {
+		return err
+	}
+
+	mountID, err := sessionMountID()
+	if err != nil {
+		return err
+	}
+
+	info, err := GetUserInfo(mountID, usr)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printJSON(info)
+	}
+
+	home := info.Home
+	if !info.HomeExists {
+		home += " (no existe)"
+	}
+
+	Output.Printf("Usuario:     %s (ID %d)\n", info.Username, info.ID)
+	Output.Printf("Grupo:       %s\n", info.Group)
+	Output.Printf("Secundarios: %s\n", formatNameList(info.Groups))
+	Output.Printf("Home:        %s\n", home)
+	Output.Printf("Archivos:    %d\n", len(info.OwnedFiles))
+	for _, path := range info.OwnedFiles {
+		Output.Printf("  %s\n", path)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Models"
+	"errors"
+)
+
+// CheckWriteAccess valida con la sesión activa si se puede escribir en la ruta.
+// Si el archivo existe aplica las mismas reglas que Edit (lectura y escritura);
+// si no existe exige permiso de escritura sobre la carpeta padre. Retorna si la ruta existe.
+func CheckWriteAccess(path string) (bool, error) {
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return false, errors.New("ERROR: Debe iniciar sesión para usar este comando")
+	}
+
+	mountInfo, err := Disk.GetMountInfoByID(session.MountID)
+	if err != nil {
+		return false, errors.New("ERROR: partición no encontrada")
+	}
+	partitionInfo, superBloque, err := Users.GetPartitionAndSuperBlock(mountInfo)
+	if err != nil {
+		return false, errors.New("ERROR: error accediendo al sistema de archivos")
+	}
+
+	manager := &System.EXT2Manager{}
+	manager.SetPartitionInfo(partitionInfo)
+	manager.SetSuperBlock(superBloque)
+	manager.SetDiskPath(mountInfo.DiskPath)
+
+	fileManager := System.NewEXT2FileManager(manager)
+	path = normalizePath(path)
+
+	inodeNum, err := findFileInode(fileManager, path)
+	if err == nil {
+		inodo, err := readInode(fileManager, inodeNum)
+		if err != nil {
+			return true, err
+		}
+
+		if inodo.I_type != Models.INODO_ARCHIVO {
+			return true, errors.New("ERROR: La ruta ya existe y no es un archivo")
+		}
+
+		hasReadPermission := System.ValidateFileReadPermission(inodo.I_uid, inodo.I_gid, inodo.I_perm, session.UserID, session.GroupIDs)
+		hasWritePermission := System.ValidateFileWritePermission(inodo.I_uid, inodo.I_gid, inodo.I_perm, session.UserID, session.GroupIDs)
+		if !hasReadPermission || !hasWritePermission {
+			return true, errors.New("ERROR: No tiene permisos de lectura y escritura sobre el archivo")
+		}
+		return true, nil
+	}
+
+	return false, checkParentWriteAccess(fileManager, path, session)
+}
+
+// CheckParentWriteAccess valida que la sesión activa pueda crear entradas en la carpeta padre de la ruta
+func CheckParentWriteAccess(path string) error {
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return errors.New("ERROR: Debe iniciar sesión para usar este comando")
+	}
+
+	mountInfo, err := Disk.GetMountInfoByID(session.MountID)
+	if err != nil {
+		return errors.New("ERROR: partición no encontrada")
+	}
+	partitionInfo, superBloque, err := Users.GetPartitionAndSuperBlock(mountInfo)
+	if err != nil {
+		return errors.New("ERROR: error accediendo al sistema de archivos")
+	}
+
+	manager := &System.EXT2Manager{}
+	manager.SetPartitionInfo(partitionInfo)
+	manager.SetSuperBlock(superBloque)
+	manager.SetDiskPath(mountInfo.DiskPath)
+
+	return checkParentWriteAccess(System.NewEXT2FileManager(manager), normalizePath(path), session)
+}
+
+// checkParentWriteAccess busca el ancestro existente más cercano y valida su permiso de escritura
+func checkParentWriteAccess(fileManager *System.EXT2FileManager, path string, session *Users.Session) error {
+	parentPath, _ := splitPath(path)
+
+	for {
+		parentInodeNum, err := findFileInode(fileManager, parentPath)
+		if err == nil {
+			parentInodo, err := readInode(fileManager, parentInodeNum)
+			if err != nil {
+				return err
+			}
+			if parentInodo.I_type != Models.INODO_DIRECTORIO {
+				return errors.New("ERROR: La carpeta padre no es un directorio")
+			}
+			if !System.ValidateFileWritePermission(parentInodo.I_uid, parentInodo.I_gid, parentInodo.I_perm, session.UserID, session.GroupIDs) {
+				return errors.New("ERROR: Sin permisos de escritura en directorio padre")
+			}
+			return nil
+		}
+
+		if parentPath == "/" {
+			return errors.New("ERROR: No existe la ruta")
+		}
+		parentPath, _ = splitPath(parentPath)
+	}
+}
+
+// logJournalOperation registra la operación en el journal si la partición es EXT3
+func logJournalOperation(mountInfo *Disk.MountInfo, superBloque *Models.SuperBloque, operation string, path string, content string) {
+	if superBloque == nil || superBloque.S_filesystem_type != 3 {
+		return
+	}
+
+	systemMountInfo := &System.MountInfo{
+		DiskPath:      mountInfo.DiskPath,
+		PartitionName: mountInfo.PartitionName,
+		MountID:       mountInfo.MountID,
+		DiskLetter:    mountInfo.DiskLetter,
+		PartNumber:    mountInfo.PartNumber,
+	}
+
+	ext3Manager := System.NewEXT3Manager(systemMountInfo)
+	if ext3Manager == nil {
+		return
+	}
+
+	// Limitar contenido a 64 bytes para el journal
+	if len(content) > 64 {
+		content = content[:64]
+	}
+	ext3Manager.LogOperation(operation, path, content)
+}
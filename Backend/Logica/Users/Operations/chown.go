@@ -2,6 +2,7 @@ package Operations
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Models"
@@ -49,6 +50,7 @@ func Chown(params map[string]string) error {
 		changeOwner(fileManager, inodeNum, inodo, int32(newOwnerUser.ID))
 	}
 
+	Events.EmitFSChange(session.MountID, Events.ActionModified, path)
 	return nil
 }
 
@@ -67,12 +69,8 @@ func chownRecursive(fileManager *System.EXT2FileManager, currentPath string, cur
 	}
 
 	if currentInodo.I_type == Models.INODO_DIRECTORIO {
-		for i := 0; i < 12; i++ {
-			if currentInodo.I_block[i] == Models.FREE_BLOCK {
-				break
-			}
-
-			dirBlock, err := readDirectoryBlock(fileManager, currentInodo.I_block[i])
+		for _, block := range fileManager.DirectoryBlocks(currentInodo) {
+			dirBlock, err := readDirectoryBlock(fileManager, block)
 			if err != nil {
 				continue
 			}
@@ -2,12 +2,12 @@ package Operations
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Models"
-	"encoding/binary"
 	"errors"
-	"os"
+	"fmt"
 	"strings"
 )
 
@@ -46,12 +46,17 @@ func Copy(params map[string]string) error {
 		return errors.New("ERROR: No existe la carpeta destino")
 	}
 
+	// Copiar una carpeta dentro de sí misma recorrería también la copia sin terminar
+	if sourceInodo.I_type == Models.INODO_DIRECTORIO && (destPath == sourcePath || strings.HasPrefix(destPath, sourcePath+"/")) {
+		return errors.New("ERROR: No se puede copiar una carpeta dentro de sí misma")
+	}
+
 	hasWritePermission := System.ValidateFileWritePermission(
 		destInodo.I_uid,
 		destInodo.I_gid,
 		destInodo.I_perm,
 		session.UserID,
-		session.GroupID,
+		session.GroupIDs,
 	)
 
 	if !hasWritePermission {
@@ -65,18 +70,32 @@ func Copy(params map[string]string) error {
 		return nil
 	}
 
+	// Un error deja la copia a medias; en EXT3 la transacción del comando la descarta completa
 	if sourceInodo.I_type == Models.INODO_ARCHIVO {
-		copyFile(fileManager, sourceInodeNum, sourceInodo, destInodeNum, sourceName, session.UserID, session.GroupID)
+		err = copyFile(fileManager, sourceInodeNum, sourceInodo, destInodeNum, sourceName, session.UserID, session.GroupIDs)
 	} else {
-		copyDirectory(fileManager, sourcePath, sourceInodeNum, sourceInodo, destInodeNum, sourceName, session.UserID, session.GroupID)
+		err = copyDirectory(fileManager, sourcePath, sourceInodeNum, sourceInodo, destInodeNum, sourceName, session.UserID, session.GroupIDs)
+	}
+	if err != nil {
+		return fmt.Errorf("ERROR: No se pudo copiar '%s': %v", sourcePath, err)
 	}
 
+	Events.EmitFSChange(session.MountID, Events.ActionCreated, joinPath(destPath, sourceName))
 	return nil
 }
 
-func copyFile(fileManager *System.EXT2FileManager, sourceInodeNum int32, sourceInodo *Models.Inodo, destDirInodeNum int32, fileName string, uid, gid int) {
-	content, _ := readFileContent(fileManager, sourceInodo)
-	newInodeNum, _ := findFreeInode(fileManager)
+func copyFile(fileManager *System.EXT2FileManager, sourceInodeNum int32, sourceInodo *Models.Inodo, destDirInodeNum int32, fileName string, uid int, gids []int) error {
+	content, err := readFileContent(fileManager, sourceInodo)
+	if err != nil {
+		return err
+	}
+	newInodeNum, err := findFreeInode(fileManager, destDirInodeNum)
+	if err != nil {
+		return err
+	}
+	if err := updateInodeBitmap(fileManager, newInodeNum, true); err != nil {
+		return err
+	}
 
 	newInodo := Models.Inodo{
 		I_uid:   sourceInodo.I_uid,
@@ -93,15 +112,31 @@ func copyFile(fileManager *System.EXT2FileManager, sourceInodeNum int32, sourceI
 		newInodo.I_block[i] = Models.FREE_BLOCK
 	}
 
-	writeMultipleBlocksForCopy(fileManager, &newInodo, content)
-	writeInode(fileManager, newInodeNum, &newInodo)
-	addEntryToDirectory(fileManager, destDirInodeNum, fileName, newInodeNum)
-	updateInodeBitmap(fileManager, newInodeNum, true)
+	if err := writeMultipleBlocksForCopy(fileManager, newInodeNum, &newInodo, content); err != nil {
+		return err
+	}
+	if err := writeInode(fileManager, newInodeNum, &newInodo); err != nil {
+		return err
+	}
+	return addEntryToDirectory(fileManager, destDirInodeNum, fileName, newInodeNum)
 }
 
-func copyDirectory(fileManager *System.EXT2FileManager, sourcePath string, sourceInodeNum int32, sourceInodo *Models.Inodo, destDirInodeNum int32, dirName string, uid, gid int) {
-	newDirInodeNum, _ := findFreeInode(fileManager)
-	newDirBlockNum, _ := findFreeBlock(fileManager)
+func copyDirectory(fileManager *System.EXT2FileManager, sourcePath string, sourceInodeNum int32, sourceInodo *Models.Inodo, destDirInodeNum int32, dirName string, uid int, gids []int) error {
+	// Reservar inodo y bloque antes de tocar la carpeta destino, que puede necesitar un bloque nuevo
+	newDirInodeNum, err := findFreeInode(fileManager, destDirInodeNum)
+	if err != nil {
+		return err
+	}
+	if err := updateInodeBitmap(fileManager, newDirInodeNum, true); err != nil {
+		return err
+	}
+	newDirBlockNum, err := findFreeBlock(fileManager, newDirInodeNum)
+	if err != nil {
+		return err
+	}
+	if err := updateBlockBitmap(fileManager, newDirBlockNum, true); err != nil {
+		return err
+	}
 
 	newDirInodo := Models.Inodo{
 		I_uid:   sourceInodo.I_uid,
@@ -132,19 +167,25 @@ func copyDirectory(fileManager *System.EXT2FileManager, sourcePath string, sourc
 	dirBlock.B_content[1].B_inodo = destDirInodeNum
 	copy(dirBlock.B_content[1].B_name[:], "..")
 
-	writeDirectoryBlock(fileManager, newDirBlockNum, &dirBlock)
-	writeInode(fileManager, newDirInodeNum, &newDirInodo)
-	addEntryToDirectory(fileManager, destDirInodeNum, dirName, newDirInodeNum)
-	updateInodeBitmap(fileManager, newDirInodeNum, true)
-	updateBlockBitmap(fileManager, newDirBlockNum, true)
+	if err := writeDirectoryBlock(fileManager, newDirBlockNum, &dirBlock); err != nil {
+		return err
+	}
+	if err := writeInode(fileManager, newDirInodeNum, &newDirInodo); err != nil {
+		return err
+	}
+	if err := fileManager.CreateDirectoryIndex(newDirInodeNum, &newDirInodo); err != nil {
+		return err
+	}
+	if err := addEntryToDirectory(fileManager, destDirInodeNum, dirName, newDirInodeNum); err != nil {
+		return err
+	}
 
-	for i := 0; i < 12; i++ {
-		if sourceInodo.I_block[i] == Models.FREE_BLOCK {
-			break
+	for _, block := range fileManager.DirectoryBlocks(sourceInodo) {
+		sourceDirBlock, err := readDirectoryBlock(fileManager, block)
+		if err != nil {
+			return err
 		}
 
-		sourceDirBlock, _ := readDirectoryBlock(fileManager, sourceInodo.I_block[i])
-
 		for _, entry := range sourceDirBlock.B_content {
 			if entry.B_inodo == Models.FREE_INODE {
 				continue
@@ -166,7 +207,7 @@ func copyDirectory(fileManager *System.EXT2FileManager, sourcePath string, sourc
 				entryInodo.I_gid,
 				entryInodo.I_perm,
 				uid,
-				gid,
+				gids,
 			)
 
 			if !hasReadPermission {
@@ -174,129 +215,27 @@ func copyDirectory(fileManager *System.EXT2FileManager, sourcePath string, sourc
 			}
 
 			if entryInodo.I_type == Models.INODO_ARCHIVO {
-				copyFile(fileManager, entry.B_inodo, entryInodo, newDirInodeNum, entryName, uid, gid)
+				err = copyFile(fileManager, entry.B_inodo, entryInodo, newDirInodeNum, entryName, uid, gids)
 			} else if entryInodo.I_type == Models.INODO_DIRECTORIO {
 				subSourcePath := sourcePath + "/" + entryName
-				copyDirectory(fileManager, subSourcePath, entry.B_inodo, entryInodo, newDirInodeNum, entryName, uid, gid)
+				err = copyDirectory(fileManager, subSourcePath, entry.B_inodo, entryInodo, newDirInodeNum, entryName, uid, gids)
+			}
+			if err != nil {
+				return err
 			}
 		}
 	}
+	return nil
 }
 
 func readFileContent(fileManager *System.EXT2FileManager, inodo *Models.Inodo) ([]byte, error) {
-	content := make([]byte, 0, inodo.I_s)
-	bytesRead := int32(0)
-
-	for i := 0; i < 12 && i < len(inodo.I_block); i++ {
-		if inodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		bytesRemaining := inodo.I_s - bytesRead
-		if bytesRemaining <= 0 {
-			break
-		}
-
-		blockContent, _ := readFileBlock(fileManager, inodo.I_block[i])
-
-		bytesToTake := int32(Models.BLOQUE_SIZE)
-		if bytesToTake > bytesRemaining {
-			bytesToTake = bytesRemaining
-		}
-
-		content = append(content, blockContent[:bytesToTake]...)
-		bytesRead += bytesToTake
-
-		if bytesRead >= inodo.I_s {
-			break
-		}
-	}
-
-	return content, nil
-}
-
-func readFileBlock(fileManager *System.EXT2FileManager, blockNumber int32) ([]byte, error) {
-	manager := fileManager.GetManager()
-	diskPath := manager.GetDiskPath()
-	partitionInfo := manager.GetPartitionInfo()
-	superBloque := manager.GetSuperBlock()
-
-	file, _ := os.Open(diskPath)
-	defer file.Close()
-
-	blockPos := partitionInfo.PartStart + int64(superBloque.S_block_start) + int64(blockNumber*Models.BLOQUE_SIZE)
-	file.Seek(blockPos, 0)
-
-	var fileBlock Models.BloqueArchivos
-	binary.Read(file, binary.LittleEndian, &fileBlock)
-
-	return fileBlock.GetContent(), nil
+	return fileManager.ReadInodeContent(inodo)
 }
 
-func writeMultipleBlocksForCopy(fileManager *System.EXT2FileManager, inodo *Models.Inodo, content []byte) {
-	totalBytes := len(content)
-	blocksNeeded := (totalBytes + Models.BLOQUE_SIZE - 1) / Models.BLOQUE_SIZE
-
-	if blocksNeeded > 12 {
-		blocksNeeded = 12
-		totalBytes = 12 * Models.BLOQUE_SIZE
-		content = content[:totalBytes]
-	}
-
-	for i := 0; i < blocksNeeded; i++ {
-		blockNum, _ := findFreeBlock(fileManager)
-
-		start := i * Models.BLOQUE_SIZE
-		end := start + Models.BLOQUE_SIZE
-		if end > len(content) {
-			end = len(content)
-		}
-
-		blockContent := content[start:end]
-		writeFileBlock(fileManager, blockNum, blockContent)
-
-		inodo.I_block[i] = blockNum
-		updateBlockBitmap(fileManager, blockNum, true)
-	}
+func writeMultipleBlocksForCopy(fileManager *System.EXT2FileManager, inodeNum int32, inodo *Models.Inodo, content []byte) error {
+	return fileManager.WriteInodeContent(inodeNum, inodo, content)
 }
 
-func addEntryToDirectory(fileManager *System.EXT2FileManager, dirInodeNum int32, fileName string, fileInodeNum int32) {
-	dirInodo, _ := readInode(fileManager, dirInodeNum)
-
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, _ := readDirectoryBlock(fileManager, dirInodo.I_block[i])
-
-		for j := 0; j < len(dirBlock.B_content); j++ {
-			if dirBlock.B_content[j].B_inodo == Models.FREE_INODE {
-				dirBlock.B_content[j].B_inodo = fileInodeNum
-				copy(dirBlock.B_content[j].B_name[:], fileName)
-				writeDirectoryBlock(fileManager, dirInodo.I_block[i], dirBlock)
-				return
-			}
-		}
-	}
-
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			newBlockNum, _ := findFreeBlock(fileManager)
-			dirInodo.I_block[i] = newBlockNum
-
-			newDirBlock := &Models.BloqueCarpeta{}
-			for j := 0; j < len(newDirBlock.B_content); j++ {
-				newDirBlock.B_content[j].B_inodo = Models.FREE_INODE
-			}
-
-			newDirBlock.B_content[0].B_inodo = fileInodeNum
-			copy(newDirBlock.B_content[0].B_name[:], fileName)
-
-			writeDirectoryBlock(fileManager, newBlockNum, newDirBlock)
-			updateBlockBitmap(fileManager, newBlockNum, true)
-			writeInode(fileManager, dirInodeNum, dirInodo)
-			return
-		}
-	}
+func addEntryToDirectory(fileManager *System.EXT2FileManager, dirInodeNum int32, fileName string, fileInodeNum int32) error {
+	return fileManager.AddDirectoryEntry(dirInodeNum, fileName, fileInodeNum)
 }
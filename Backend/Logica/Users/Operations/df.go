@@ -0,0 +1,91 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Models"
+	"fmt"
+)
+
+// DfUsage es el uso de inodos y bloques de una partición montada según su SuperBloque
+type DfUsage struct {
+	MountID     string `json:"mountId"`
+	Partition   string `json:"partition"`
+	DiskPath    string `json:"diskPath"`
+	Formatted   bool   `json:"formatted"`
+	FileSystem  string `json:"fileSystem,omitempty"`
+	BlockSize   int32  `json:"blockSize,omitempty"`
+	Blocks      int32  `json:"blocks"`
+	UsedBlocks  int32  `json:"usedBlocks"`
+	FreeBlocks  int32  `json:"freeBlocks"`
+	Inodes      int32  `json:"inodes"`
+	UsedInodes  int32  `json:"usedInodes"`
+	FreeInodes  int32  `json:"freeInodes"`
+	BlocksUsage int    `json:"blocksUsage"`
+}
+
+// Df - Función exportada para comando df
+// Muestra los inodos y bloques usados y libres de cada partición montada.
+func Df(params map[string]string) error {
+	asJSON, err := parseOutputFormat(params)
+	if err != nil {
+		return err
+	}
+
+	usages := []DfUsage{}
+	for _, mountInfo := range Disk.GetMountedPartitions() {
+		usages = append(usages, partitionUsage(mountInfo))
+	}
+
+	if asJSON {
+		return printJSON(usages)
+	}
+
+	if len(usages) == 0 {
+		Output.Println("No hay particiones montadas")
+		return nil
+	}
+
+	Output.Printf("%-6s %-12s %-4s %8s %8s %8s %5s %8s %8s %8s\n",
+		"ID", "PARTICION", "FS", "BLOQUES", "USADOS", "LIBRES", "USO%", "INODOS", "USADOS", "LIBRES")
+	for _, usage := range usages {
+		if !usage.Formatted {
+			Output.Printf("%-6s %-12s %s\n", usage.MountID, usage.Partition, "(sin formato)")
+			continue
+		}
+		Output.Printf("%-6s %-12s %-4s %8d %8d %8d %4d%% %8d %8d %8d\n",
+			usage.MountID, usage.Partition, usage.FileSystem,
+			usage.Blocks, usage.UsedBlocks, usage.FreeBlocks, usage.BlocksUsage,
+			usage.Inodes, usage.UsedInodes, usage.FreeInodes)
+	}
+	return nil
+}
+
+// partitionUsage lee el SuperBloque de la partición montada y calcula su uso
+func partitionUsage(mountInfo Disk.MountInfo) DfUsage {
+	usage := DfUsage{
+		MountID:   mountInfo.MountID,
+		Partition: mountInfo.PartitionName,
+		DiskPath:  mountInfo.DiskPath,
+	}
+
+	_, superBloque, err := Users.GetPartitionAndSuperBlock(&mountInfo)
+	if err != nil || superBloque.S_magic != Models.EXT2_MAGIC {
+		return usage
+	}
+
+	usage.Formatted = true
+	usage.FileSystem = fmt.Sprintf("ext%d", superBloque.S_filesystem_type)
+	usage.BlockSize = superBloque.S_block_s
+	usage.Blocks = superBloque.S_blocks_count
+	usage.FreeBlocks = superBloque.S_free_blocks_count
+	usage.UsedBlocks = superBloque.S_blocks_count - superBloque.S_free_blocks_count
+	usage.Inodes = superBloque.S_inodes_count
+	usage.FreeInodes = superBloque.S_free_inodes_count
+	usage.UsedInodes = superBloque.S_inodes_count - superBloque.S_free_inodes_count
+	if usage.Blocks > 0 {
+		usage.BlocksUsage = int(int64(usage.UsedBlocks) * 100 / int64(usage.Blocks))
+	}
+	return usage
+}
@@ -0,0 +1,106 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Models"
+	"fmt"
+)
+
+// DuUsage es el tamaño acumulado de una carpeta o archivo
+type DuUsage struct {
+	Path        string    `json:"path"`
+	Size        int64     `json:"size"`
+	Files       int       `json:"files"`
+	Directories int       `json:"directories"`
+	Denied      []string  `json:"denied,omitempty"`
+	Subdirs     []DuUsage `json:"subdirs,omitempty"`
+}
+
+// Du - Función exportada para comando du
+// Calcula el tamaño recursivo (suma de I_s) de -path. Con -s solo muestra el total.
+// Las carpetas sin permiso de lectura no se recorren y se reportan aparte.
+func Du(params map[string]string) error {
+	asJSON, err := parseOutputFormat(params)
+	if err != nil {
+		return err
+	}
+	_, summary := params["s"]
+
+	ctx, err := newInspectContext()
+	if err != nil {
+		return err
+	}
+
+	path := inspectPath(params)
+	_, inodo, err := ctx.lookup(path)
+	if err != nil {
+		return err
+	}
+	if inodo.I_type == Models.INODO_DIRECTORIO && !ctx.canRead(inodo) {
+		return fmt.Errorf("ERROR: Sin permisos de lectura sobre '%s'", path)
+	}
+
+	usage := diskUsage(ctx, path, inodo)
+
+	if asJSON {
+		return printJSON(usage)
+	}
+
+	if !summary {
+		printDuSubdirs(usage.Subdirs)
+	}
+	Output.Printf("%d\t%s\n", usage.Size, usage.Path)
+	for _, denied := range usage.Denied {
+		Output.Printf("ERROR: Sin permisos de lectura sobre '%s'\n", denied)
+	}
+	return nil
+}
+
+// diskUsage suma recursivamente los tamaños de los archivos legibles bajo la ruta
+func diskUsage(ctx *inspectContext, path string, inodo *Models.Inodo) DuUsage {
+	usage := DuUsage{Path: path}
+	if inodo.I_type != Models.INODO_DIRECTORIO {
+		usage.Size = int64(inodo.I_s)
+		usage.Files = 1
+		return usage
+	}
+
+	for _, entry := range ctx.readEntries(path, inodo) {
+		if entry.Name == "." || entry.Name == ".." {
+			continue
+		}
+
+		if entry.Type != "dir" {
+			usage.Size += int64(entry.Size)
+			usage.Files++
+			continue
+		}
+
+		usage.Directories++
+		childInodo, err := readInode(ctx.fileManager, entry.Inode)
+		if err != nil {
+			continue
+		}
+		if !ctx.canRead(childInodo) {
+			usage.Denied = append(usage.Denied, entry.Path)
+			continue
+		}
+
+		child := diskUsage(ctx, entry.Path, childInodo)
+		usage.Size += child.Size
+		usage.Files += child.Files
+		usage.Directories += child.Directories
+		usage.Denied = append(usage.Denied, child.Denied...)
+		child.Denied = nil
+		usage.Subdirs = append(usage.Subdirs, child)
+	}
+	return usage
+}
+
+// printDuSubdirs imprime el tamaño de cada subcarpeta antes que su padre
+func printDuSubdirs(subdirs []DuUsage) {
+	for _, subdir := range subdirs {
+		printDuSubdirs(subdir.Subdirs)
+		Output.Printf("%d\t%s\n", subdir.Size, subdir.Path)
+	}
+}
@@ -2,10 +2,12 @@ package Operations
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Models"
 	"errors"
+	"fmt"
 	"io/ioutil"
 )
 
@@ -44,7 +46,7 @@ func Edit(params map[string]string) error {
 		inodo.I_gid,
 		inodo.I_perm,
 		session.UserID,
-		session.GroupID,
+		session.GroupIDs,
 	)
 
 	hasWritePermission := System.ValidateFileWritePermission(
@@ -52,60 +54,43 @@ func Edit(params map[string]string) error {
 		inodo.I_gid,
 		inodo.I_perm,
 		session.UserID,
-		session.GroupID,
+		session.GroupIDs,
 	)
 
 	if !hasReadPermission || !hasWritePermission {
 		return errors.New("ERROR: No tiene permisos de lectura y escritura sobre el archivo")
 	}
 
-	editFileContent(fileManager, inodeNum, inodo, newContent)
+	if err := editFileContent(fileManager, inodeNum, inodo, newContent); err != nil {
+		return fmt.Errorf("ERROR: %v", err)
+	}
+	logJournalOperation(mountInfo, superBloque, "edit", path, newContent)
+	Events.EmitFSChange(session.MountID, Events.ActionModified, path)
 	return nil
 }
 
-func editFileContent(fileManager *System.EXT2FileManager, inodeNum int32, inodo *Models.Inodo, newContent string) {
+func editFileContent(fileManager *System.EXT2FileManager, inodeNum int32, inodo *Models.Inodo, newContent string) error {
+	// Validar el tamaño antes de liberar los bloques actuales
+	if len(newContent) > System.MaxFileBlocks*Models.BLOQUE_SIZE {
+		return fmt.Errorf("el contenido excede el tamaño máximo de un archivo (%d bytes)", System.MaxFileBlocks*Models.BLOQUE_SIZE)
+	}
+
 	freeInodeBlocks(fileManager, inodo)
-	writeMultipleBlocks(fileManager, inodo, []byte(newContent))
+	if err := writeMultipleBlocks(fileManager, inodeNum, inodo, []byte(newContent)); err != nil {
+		return err
+	}
 
 	inodo.I_s = int32(len(newContent))
 	inodo.I_mtime = float64(Models.GetCurrentUnixTime())
 	inodo.I_atime = float64(Models.GetCurrentUnixTime())
 
-	writeInode(fileManager, inodeNum, inodo)
+	return writeInode(fileManager, inodeNum, inodo)
 }
 
-func writeMultipleBlocks(fileManager *System.EXT2FileManager, inodo *Models.Inodo, content []byte) {
-	totalBytes := len(content)
-	blocksNeeded := (totalBytes + Models.BLOQUE_SIZE - 1) / Models.BLOQUE_SIZE
-
-	if blocksNeeded > 12 {
-		blocksNeeded = 12
-		totalBytes = 12 * Models.BLOQUE_SIZE
-		content = content[:totalBytes]
-	}
-
-	for i := 0; i < blocksNeeded; i++ {
-		blockNum, _ := findFreeBlock(fileManager)
-
-		start := i * Models.BLOQUE_SIZE
-		end := start + Models.BLOQUE_SIZE
-		if end > len(content) {
-			end = len(content)
-		}
-
-		blockContent := content[start:end]
-		writeFileBlock(fileManager, blockNum, blockContent)
-
-		inodo.I_block[i] = blockNum
-		updateBlockBitmap(fileManager, blockNum, true)
-	}
+func writeMultipleBlocks(fileManager *System.EXT2FileManager, inodeNum int32, inodo *Models.Inodo, content []byte) error {
+	return fileManager.WriteInodeContent(inodeNum, inodo, content)
 }
 
 func freeInodeBlocks(fileManager *System.EXT2FileManager, inodo *Models.Inodo) {
-	for i := 0; i < 12; i++ {
-		if inodo.I_block[i] != Models.FREE_BLOCK {
-			updateBlockBitmap(fileManager, inodo.I_block[i], false)
-			inodo.I_block[i] = Models.FREE_BLOCK
-		}
-	}
+	fileManager.FreeInodeBlocks(inodo)
 }
@@ -1,50 +1,37 @@
 package Operations
 
 import (
-	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/System"
-	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Models"
 	"fmt"
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// Find - Función exportada para comando find
+// Busca bajo -path las entradas que cumplen todos los filtros indicados y las muestra
+// como árbol o como JSON (-format=json).
 func Find(params map[string]string) error {
-	searchPath := params["path"]
-	searchName := params["name"]
-	session := Users.GetCurrentSession()
-	mountInfo, _ := Disk.GetMountInfoByID(session.MountID)
-	partitionInfo, superBloque, _ := Users.GetPartitionAndSuperBlock(mountInfo)
-
-	manager := &System.EXT2Manager{}
-	manager.SetPartitionInfo(partitionInfo)
-	manager.SetSuperBlock(superBloque)
-	manager.SetDiskPath(mountInfo.DiskPath)
-
-	fileManager := System.NewEXT2FileManager(manager)
-	searchPath = normalizePath(searchPath)
-	searchInodeNum, _ := findFileInode(fileManager, searchPath)
-	searchInodo, _ := readInode(fileManager, searchInodeNum)
-
-	hasReadPermission := System.ValidateFileReadPermission(
-		searchInodo.I_uid,
-		searchInodo.I_gid,
-		searchInodo.I_perm,
-		session.UserID,
-		session.GroupID,
-	)
-
-	if !hasReadPermission {
-		return nil
+	asJSON, err := parseOutputFormat(params)
+	if err != nil {
+		return err
 	}
 
-	pattern := wildcardToRegex(searchName)
-	results := []FindResult{}
-	searchRecursive(fileManager, searchPath, searchInodeNum, pattern, session.UserID, session.GroupID, &results, 0)
+	searchPath, results, err := FindMatches(params)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printJSON(results)
+	}
 
 	if len(results) == 0 {
-		fmt.Println("No se encontraron coincidencias")
+		Output.Println("No se encontraron coincidencias")
 		return nil
 	}
 
@@ -52,38 +39,269 @@ func Find(params map[string]string) error {
 	return nil
 }
 
+// FindMatches ejecuta la búsqueda de find y retorna la ruta base y las coincidencias.
+// Filtros: -name (comodines * y ?), -type=f|d, -size=[+|-]N (bytes de I_s), -user, -group,
+// -perm (octal exacto), -mtime/-ctime=[+|-]N[s|m|h|d] y -maxdepth.
+func FindMatches(params map[string]string) (string, []FindResult, error) {
+	ctx, err := newInspectContext()
+	if err != nil {
+		return "", nil, err
+	}
+
+	filters, err := parseFindFilters(ctx, params)
+	if err != nil {
+		return "", nil, err
+	}
+
+	searchPath := inspectPath(params)
+	searchInodeNum, searchInodo, err := ctx.lookup(searchPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if searchInodo.I_type != Models.INODO_DIRECTORIO {
+		return "", nil, fmt.Errorf("ERROR: '%s' no es una carpeta", searchPath)
+	}
+	if !ctx.canRead(searchInodo) {
+		return "", nil, fmt.Errorf("ERROR: Sin permisos de lectura sobre '%s'", searchPath)
+	}
+
+	results := []FindResult{}
+	searchRecursive(ctx, searchPath, searchInodeNum, filters, &results, 1)
+	return searchPath, results, nil
+}
+
+// FindResult es una coincidencia de find
 type FindResult struct {
-	Path        string
-	Name        string
-	IsDirectory bool
-	Permissions int32
-	Level       int
+	Path        string `json:"path"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	IsDirectory bool   `json:"-"`
+	Size        int32  `json:"size"`
+	Permissions int32  `json:"permissions"`
+	Owner       string `json:"owner"`
+	Group       string `json:"group"`
+	MTime       string `json:"mtime"`
+	CTime       string `json:"ctime"`
+	Level       int    `json:"level"`
+}
+
+// findFilters son los criterios de búsqueda; los campos nil o -1 no filtran
+type findFilters struct {
+	pattern  *regexp.Regexp
+	fileType int
+	size     *numericFilter
+	uid      int32
+	gid      int32
+	perm     int32
+	mtime    *timeFilter
+	ctime    *timeFilter
+	maxDepth int
+}
+
+// numericFilter compara un valor contra N: +N mayor, -N menor, N igual
+type numericFilter struct {
+	sign  byte
+	value int64
+}
+
+// timeFilter compara la antigüedad de un timestamp medida en unidades de unit segundos
+type timeFilter struct {
+	numericFilter
+	unit float64
+}
+
+func (f *numericFilter) match(value int64) bool {
+	switch f.sign {
+	case '+':
+		return value > f.value
+	case '-':
+		return value < f.value
+	default:
+		return value == f.value
+	}
+}
+
+// parseFindFilters valida los parámetros de find y resuelve -user y -group con users.txt
+func parseFindFilters(ctx *inspectContext, params map[string]string) (*findFilters, error) {
+	filters := &findFilters{
+		fileType: -1,
+		uid:      -1,
+		gid:      -1,
+		perm:     -1,
+		maxDepth: -1,
+	}
+
+	name := params["name"]
+	if name == "" {
+		name = "*"
+	}
+	filters.pattern = wildcardToRegex(name)
+
+	if fileType, ok := params["type"]; ok {
+		switch strings.ToLower(fileType) {
+		case "f":
+			filters.fileType = int(Models.INODO_ARCHIVO)
+		case "d":
+			filters.fileType = int(Models.INODO_DIRECTORIO)
+		default:
+			return nil, fmt.Errorf("valor de -type debe ser: f o d")
+		}
+	}
+
+	if sizeStr, ok := params["size"]; ok {
+		size, err := parseNumericFilter(sizeStr)
+		if err != nil {
+			return nil, fmt.Errorf("valor de -size invalido: use +N, -N o N (bytes)")
+		}
+		filters.size = size
+	}
+
+	if username, ok := params["user"]; ok {
+		uid, found := findIDByName(ctx.owners, username)
+		if !found {
+			return nil, fmt.Errorf("ERROR: El usuario '%s' no existe", username)
+		}
+		filters.uid = uid
+	}
+
+	if groupname, ok := params["group"]; ok {
+		gid, found := findIDByName(ctx.groups, groupname)
+		if !found {
+			return nil, fmt.Errorf("ERROR: El grupo '%s' no existe", groupname)
+		}
+		filters.gid = gid
+	}
+
+	if permStr, ok := params["perm"]; ok {
+		if len(permStr) != 3 || strings.Trim(permStr, "01234567") != "" {
+			return nil, fmt.Errorf("valor de -perm debe ser tres dígitos octales (ej: 664)")
+		}
+		perm, _ := strconv.Atoi(permStr)
+		filters.perm = int32(perm)
+	}
+
+	for _, key := range []string{"mtime", "ctime"} {
+		value, ok := params[key]
+		if !ok {
+			continue
+		}
+		filter, err := parseTimeFilter(value)
+		if err != nil {
+			return nil, fmt.Errorf("valor de -%s invalido: use +N, -N o N con unidad s, m, h o d", key)
+		}
+		if key == "mtime" {
+			filters.mtime = filter
+		} else {
+			filters.ctime = filter
+		}
+	}
+
+	if depthStr, ok := params["maxdepth"]; ok {
+		depth, err := strconv.Atoi(depthStr)
+		if err != nil || depth < 0 {
+			return nil, fmt.Errorf("ERROR: -maxdepth debe ser un entero mayor o igual a 0")
+		}
+		filters.maxDepth = depth
+	}
+
+	return filters, nil
+}
+
+// parseNumericFilter interpreta +N, -N o N
+func parseNumericFilter(value string) (*numericFilter, error) {
+	filter := &numericFilter{}
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		filter.sign = value[0]
+		value = value[1:]
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || number < 0 {
+		return nil, fmt.Errorf("numero invalido")
+	}
+	filter.value = number
+	return filter, nil
 }
 
-func searchRecursive(fileManager *System.EXT2FileManager, currentPath string, currentInodeNum int32, pattern *regexp.Regexp, userID, groupID int, results *[]FindResult, level int) {
-	currentInodo, err := readInode(fileManager, currentInodeNum)
+// timeUnits son las unidades aceptadas por -mtime y -ctime, en segundos
+var timeUnits = map[byte]float64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400}
+
+// parseTimeFilter interpreta [+|-]N[s|m|h|d] (días si no se indica unidad)
+func parseTimeFilter(value string) (*timeFilter, error) {
+	unit := timeUnits['d']
+	if value != "" {
+		if seconds, ok := timeUnits[value[len(value)-1]]; ok {
+			unit = seconds
+			value = value[:len(value)-1]
+		}
+	}
+	filter, err := parseNumericFilter(value)
 	if err != nil {
-		return
+		return nil, err
+	}
+	return &timeFilter{numericFilter: *filter, unit: unit}, nil
+}
+
+// findIDByName busca el ID de un nombre en el mapa de usuarios o grupos
+func findIDByName(names map[int32]string, name string) (int32, bool) {
+	for id, candidate := range names {
+		if candidate == name {
+			return id, true
+		}
+	}
+	return -1, false
+}
+
+// matches indica si la entrada cumple todos los filtros
+func (filters *findFilters) matches(name string, inodo *Models.Inodo, now float64) bool {
+	if !filters.pattern.MatchString(name) {
+		return false
+	}
+	if filters.fileType >= 0 && int(inodo.I_type) != filters.fileType {
+		return false
+	}
+	if filters.size != nil && !filters.size.match(int64(inodo.I_s)) {
+		return false
+	}
+	if filters.uid >= 0 && inodo.I_uid != filters.uid {
+		return false
+	}
+	if filters.gid >= 0 && inodo.I_gid != filters.gid {
+		return false
+	}
+	if filters.perm >= 0 && Models.GetPermissions(inodo.I_perm) != filters.perm {
+		return false
+	}
+	if filters.mtime != nil && !filters.mtime.matchAge(inodo.I_mtime, now) {
+		return false
+	}
+	if filters.ctime != nil && !filters.ctime.matchAge(inodo.I_ctime, now) {
+		return false
 	}
+	return true
+}
 
-	hasReadPermission := System.ValidateFileReadPermission(
-		currentInodo.I_uid,
-		currentInodo.I_gid,
-		currentInodo.I_perm,
-		userID,
-		groupID,
-	)
+// matchAge compara la antigüedad del timestamp, en unidades completas, contra el filtro
+func (f *timeFilter) matchAge(timestamp float64, now float64) bool {
+	return f.match(int64(math.Floor((now - timestamp) / f.unit)))
+}
 
-	if !hasReadPermission {
+func searchRecursive(ctx *inspectContext, currentPath string, currentInodeNum int32, filters *findFilters, results *[]FindResult, level int) {
+	if filters.maxDepth >= 0 && level > filters.maxDepth {
 		return
 	}
 
-	for i := 0; i < 12; i++ {
-		if currentInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
+	currentInodo, err := readInode(ctx.fileManager, currentInodeNum)
+	if err != nil {
+		return
+	}
+
+	if !ctx.canRead(currentInodo) {
+		return
+	}
 
-		dirBlock, err := readDirectoryBlock(fileManager, currentInodo.I_block[i])
+	now := float64(time.Now().Unix())
+	for _, block := range ctx.fileManager.DirectoryBlocks(currentInodo) {
+		dirBlock, err := readDirectoryBlock(ctx.fileManager, block)
 		if err != nil {
 			continue
 		}
@@ -99,7 +317,7 @@ func searchRecursive(fileManager *System.EXT2FileManager, currentPath string, cu
 				continue
 			}
 
-			entryInodo, err := readInode(fileManager, entry.B_inodo)
+			entryInodo, err := readInode(ctx.fileManager, entry.B_inodo)
 			if err != nil {
 				continue
 			}
@@ -108,34 +326,35 @@ func searchRecursive(fileManager *System.EXT2FileManager, currentPath string, cu
 				entryInodo.I_uid,
 				entryInodo.I_gid,
 				entryInodo.I_perm,
-				userID,
-				groupID,
+				ctx.session.UserID,
+				ctx.session.GroupIDs,
 			)
 
 			if !hasEntryReadPermission {
 				continue
 			}
 
-			var entryPath string
-			if currentPath == "/" {
-				entryPath = "/" + entryName
-			} else {
-				entryPath = currentPath + "/" + entryName
-			}
+			entryPath := joinPath(currentPath, entryName)
 
-			if pattern.MatchString(entryName) {
+			if filters.matches(entryName, entryInodo, now) {
 				result := FindResult{
 					Path:        entryPath,
 					Name:        entryName,
+					Type:        inodeTypeName(entryInodo),
 					IsDirectory: entryInodo.I_type == Models.INODO_DIRECTORIO,
+					Size:        entryInodo.I_s,
 					Permissions: Models.GetPermissions(entryInodo.I_perm),
+					Owner:       ctx.ownerName(entryInodo.I_uid),
+					Group:       ctx.groupName(entryInodo.I_gid),
+					MTime:       formatInodeTime(entryInodo.I_mtime),
+					CTime:       formatInodeTime(entryInodo.I_ctime),
 					Level:       level,
 				}
 				*results = append(*results, result)
 			}
 
 			if entryInodo.I_type == Models.INODO_DIRECTORIO {
-				searchRecursive(fileManager, entryPath, entry.B_inodo, pattern, userID, groupID, results, level+1)
+				searchRecursive(ctx, entryPath, entry.B_inodo, filters, results, level+1)
 			}
 		}
 	}
@@ -159,16 +378,42 @@ func printFindResults(results []FindResult, basePath string) {
 	}
 
 	tree := make(map[string][]FindResult)
+	added := make(map[string]bool)
+
+	for _, result := range results {
+		added[result.Path] = true
+	}
 
 	for _, result := range results {
+		// Las carpetas intermedias que no coinciden se agregan sin permisos para mantener el árbol conectado
+		addFindAncestors(tree, added, result.Path, basePath)
 		parentPath := getParentPath(result.Path)
 		tree[parentPath] = append(tree[parentPath], result)
 	}
 
-	fmt.Println(basePath)
+	Output.Println(basePath)
 	printTreeLevel(basePath, tree, "", true)
 }
 
+// addFindAncestors agrega al árbol las carpetas entre basePath y la ruta que no son coincidencias
+func addFindAncestors(tree map[string][]FindResult, added map[string]bool, path string, basePath string) {
+	parentPath := getParentPath(path)
+	if parentPath == basePath || parentPath == "" || added[parentPath] {
+		return
+	}
+	added[parentPath] = true
+	addFindAncestors(tree, added, parentPath, basePath)
+
+	grandParent := getParentPath(parentPath)
+	_, name := splitPath(parentPath)
+	tree[grandParent] = append(tree[grandParent], FindResult{
+		Path:        parentPath,
+		Name:        name,
+		IsDirectory: true,
+		Permissions: -1,
+	})
+}
+
 func printTreeLevel(currentPath string, tree map[string][]FindResult, prefix string, isRoot bool) {
 	children, exists := tree[currentPath]
 	if !exists {
@@ -187,7 +432,11 @@ func printTreeLevel(currentPath string, tree map[string][]FindResult, prefix str
 			linePrefix = prefix + "   |_ "
 		}
 
-		fmt.Printf("%s%s\t#%d\n", linePrefix, child.Name, child.Permissions)
+		if child.Permissions < 0 {
+			Output.Printf("%s%s\n", linePrefix, child.Name)
+		} else {
+			Output.Printf("%s%s\t#%d\n", linePrefix, child.Name, child.Permissions)
+		}
 
 		if child.IsDirectory {
 			var newPrefix string
@@ -214,3 +463,33 @@ func getParentPath(path string) string {
 
 	return path[:lastSlash]
 }
+
+// FindExecCommand sustituye {} por la ruta de la coincidencia en el comando de -exec.
+// Fuera de comillas la ruta se pone entre comillas dobles para que un nombre con espacios
+// siga siendo un solo parámetro; el separador de comandos no tiene escape para comillas,
+// así que una ruta que las contiene es un error.
+func FindExecCommand(command string, result FindResult) (string, error) {
+	if strings.Contains(result.Path, "\"") {
+		return "", fmt.Errorf("la ruta '%s' contiene comillas dobles y no se puede pasar a -exec", result.Path)
+	}
+
+	var builder strings.Builder
+	inQuotes := false
+	for i := 0; i < len(command); i++ {
+		switch {
+		case command[i] == '"':
+			inQuotes = !inQuotes
+			builder.WriteByte('"')
+		case strings.HasPrefix(command[i:], "{}"):
+			if inQuotes {
+				builder.WriteString(result.Path)
+			} else {
+				builder.WriteString("\"" + result.Path + "\"")
+			}
+			i++
+		default:
+			builder.WriteByte(command[i])
+		}
+	}
+	return builder.String(), nil
+}
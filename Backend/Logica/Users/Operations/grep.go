@@ -0,0 +1,131 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Models"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GrepMatch es una línea que coincide con el patrón de grep
+type GrepMatch struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Grep - Función exportada para comando grep
+// Busca -pattern en el contenido de -path: -r recorre subcarpetas, -i ignora mayúsculas,
+// -n muestra el número de línea y -regex interpreta el patrón como expresión regular.
+func Grep(params map[string]string) error {
+	_, showLineNumbers := params["n"]
+
+	matches, err := GrepMatches(params)
+	if err != nil {
+		return err
+	}
+
+	if len(matches) == 0 {
+		Output.Println("No se encontraron coincidencias")
+		return nil
+	}
+
+	for _, match := range matches {
+		if showLineNumbers {
+			Output.Printf("%s:%d:%s\n", match.Path, match.Line, match.Text)
+		} else {
+			Output.Printf("%s:%s\n", match.Path, match.Text)
+		}
+	}
+	return nil
+}
+
+// GrepMatches ejecuta la búsqueda de grep y retorna las líneas que coinciden.
+// Los archivos y carpetas sin permiso de lectura se omiten.
+func GrepMatches(params map[string]string) ([]GrepMatch, error) {
+	pattern, hasPattern := params["pattern"]
+	if !hasPattern || pattern == "" {
+		return nil, fmt.Errorf("parametro -pattern requerido")
+	}
+	_, recursive := params["r"]
+	_, ignoreCase := params["i"]
+	_, isRegex := params["regex"]
+
+	if !isRegex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("ERROR: expresión regular invalida: %v", err)
+	}
+
+	ctx, err := newInspectContext()
+	if err != nil {
+		return nil, err
+	}
+
+	path := inspectPath(params)
+	inodeNum, inodo, err := ctx.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	if !ctx.canRead(inodo) {
+		return nil, fmt.Errorf("ERROR: Sin permisos de lectura sobre '%s'", path)
+	}
+
+	matches := []GrepMatch{}
+	if inodo.I_type != Models.INODO_DIRECTORIO {
+		grepFile(ctx, path, matcher, &matches)
+		return matches, nil
+	}
+	if !recursive {
+		return nil, fmt.Errorf("ERROR: '%s' es una carpeta, use -r para buscar en su contenido", path)
+	}
+
+	grepRecursive(ctx, path, inodeNum, matcher, &matches)
+	return matches, nil
+}
+
+// grepRecursive recorre la carpeta como searchRecursive y busca en cada archivo legible
+func grepRecursive(ctx *inspectContext, currentPath string, currentInodeNum int32, matcher *regexp.Regexp, matches *[]GrepMatch) {
+	currentInodo, err := readInode(ctx.fileManager, currentInodeNum)
+	if err != nil || !ctx.canRead(currentInodo) {
+		return
+	}
+
+	for _, entry := range ctx.readEntries(currentPath, currentInodo) {
+		if entry.Name == "." || entry.Name == ".." {
+			continue
+		}
+
+		entryInodo, err := readInode(ctx.fileManager, entry.Inode)
+		if err != nil || !ctx.canRead(entryInodo) {
+			continue
+		}
+
+		if entryInodo.I_type == Models.INODO_DIRECTORIO {
+			grepRecursive(ctx, entry.Path, entry.Inode, matcher, matches)
+		} else {
+			grepFile(ctx, entry.Path, matcher, matches)
+		}
+	}
+}
+
+// grepFile lee el archivo con ReadFileContent y agrega las líneas que coinciden
+func grepFile(ctx *inspectContext, path string, matcher *regexp.Regexp, matches *[]GrepMatch) {
+	content, err := ctx.fileManager.ReadFileContent(path)
+	if err != nil {
+		return
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if matcher.MatchString(line) {
+			*matches = append(*matches, GrepMatch{Path: path, Line: i + 1, Text: line})
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Models"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ========== FUNCIONES AUXILIARES DE CONSULTA (ls, tree, stat, du) ==========
+
+// InspectEntry describe una entrada del árbol para la salida de ls, tree y stat
+type InspectEntry struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Inode       int32  `json:"inode"`
+	Type        string `json:"type"`
+	Size        int32  `json:"size"`
+	Permissions string `json:"permissions"`
+	Owner       string `json:"owner"`
+	Group       string `json:"group"`
+	MTime       string `json:"mtime"`
+}
+
+// inspectContext agrupa el gestor de archivos, la sesión y los nombres de users.txt
+type inspectContext struct {
+	fileManager *System.EXT2FileManager
+	session     *Users.Session
+	owners      map[int32]string
+	groups      map[int32]string
+}
+
+// newInspectContext prepara la consulta sobre la partición de la sesión activa
+func newInspectContext() (*inspectContext, error) {
+	fileManager, _, err := sessionFileManager()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &inspectContext{
+		fileManager: fileManager,
+		session:     Users.GetCurrentSession(),
+		owners:      make(map[int32]string),
+		groups:      make(map[int32]string),
+	}
+
+	// Los nombres son solo informativos: si users.txt no se puede leer se muestran los IDs
+	manager := fileManager.GetManager()
+	records, err := Users.NewUserManager(manager.GetDiskPath(), manager.GetPartitionInfo(), manager.GetSuperBlock()).ReadUsersFile()
+	if err == nil {
+		for _, record := range records {
+			if record.ID == 0 {
+				continue
+			}
+			if record.Type == "G" {
+				ctx.groups[int32(record.ID)] = record.Group
+			} else {
+				ctx.owners[int32(record.ID)] = record.Username
+			}
+		}
+	}
+
+	return ctx, nil
+}
+
+// canRead aplica ValidateFileReadPermission con los grupos de la sesión
+func (ctx *inspectContext) canRead(inodo *Models.Inodo) bool {
+	return System.ValidateFileReadPermission(inodo.I_uid, inodo.I_gid, inodo.I_perm, ctx.session.UserID, ctx.session.GroupIDs)
+}
+
+// ownerName retorna el nombre del usuario o su UID si ya no existe
+func (ctx *inspectContext) ownerName(uid int32) string {
+	if name, ok := ctx.owners[uid]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", uid)
+}
+
+// groupName retorna el nombre del grupo o su GID si ya no existe
+func (ctx *inspectContext) groupName(gid int32) string {
+	if name, ok := ctx.groups[gid]; ok {
+		return name
+	}
+	return fmt.Sprintf("%d", gid)
+}
+
+// lookup busca la ruta y retorna su número de inodo y el inodo
+func (ctx *inspectContext) lookup(path string) (int32, *Models.Inodo, error) {
+	inodeNum, err := findFileInode(ctx.fileManager, path)
+	if err != nil {
+		return -1, nil, fmt.Errorf("ERROR: No existe la ruta '%s'", path)
+	}
+	inodo, err := readInode(ctx.fileManager, inodeNum)
+	if err != nil {
+		return -1, nil, fmt.Errorf("ERROR: No se pudo leer el inodo de '%s'", path)
+	}
+	return inodeNum, inodo, nil
+}
+
+// newEntry construye la descripción de una entrada a partir de su inodo
+func (ctx *inspectContext) newEntry(name string, path string, inodeNum int32, inodo *Models.Inodo) InspectEntry {
+	return InspectEntry{
+		Name:        name,
+		Path:        path,
+		Inode:       inodeNum,
+		Type:        inodeTypeName(inodo),
+		Size:        inodo.I_s,
+		Permissions: fmt.Sprintf("%03d", Models.GetPermissions(inodo.I_perm)),
+		Owner:       ctx.ownerName(inodo.I_uid),
+		Group:       ctx.groupName(inodo.I_gid),
+		MTime:       formatInodeTime(inodo.I_mtime),
+	}
+}
+
+// readEntries lista las entradas de una carpeta (incluidas "." y "..") en el orden de sus bloques
+func (ctx *inspectContext) readEntries(dirPath string, dirInodo *Models.Inodo) []InspectEntry {
+	entries := []InspectEntry{}
+	for _, block := range ctx.fileManager.DirectoryBlocks(dirInodo) {
+		dirBlock, err := readDirectoryBlock(ctx.fileManager, block)
+		if err != nil {
+			continue
+		}
+
+		for _, content := range dirBlock.B_content {
+			if content.B_inodo == Models.FREE_INODE {
+				continue
+			}
+			name := strings.TrimRight(string(content.B_name[:]), "\x00")
+			if name == "" {
+				continue
+			}
+
+			inodo, err := readInode(ctx.fileManager, content.B_inodo)
+			if err != nil {
+				continue
+			}
+
+			entryPath := dirPath
+			if name != "." && name != ".." {
+				entryPath = joinPath(dirPath, name)
+			}
+			entries = append(entries, ctx.newEntry(name, entryPath, content.B_inodo, inodo))
+		}
+	}
+	return entries
+}
+
+// inspectPath resuelve -path contra el directorio de trabajo (por defecto el propio directorio)
+func inspectPath(params map[string]string) string {
+	path := params["path"]
+	if path == "" {
+		path = "."
+	}
+	return Users.ResolvePath(path)
+}
+
+// inodeTypeName retorna "dir" o "file" según I_type
+func inodeTypeName(inodo *Models.Inodo) string {
+	if inodo.I_type == Models.INODO_DIRECTORIO {
+		return "dir"
+	}
+	return "file"
+}
+
+// permissionString convierte el tipo y los permisos al formato drwxr-xr-x
+func permissionString(entry InspectEntry) string {
+	var sb strings.Builder
+	if entry.Type == "dir" {
+		sb.WriteByte('d')
+	} else {
+		sb.WriteByte('-')
+	}
+	for _, digit := range entry.Permissions {
+		value := digit - '0'
+		for i, flag := range "rwx" {
+			if value&(4>>i) != 0 {
+				sb.WriteRune(flag)
+			} else {
+				sb.WriteByte('-')
+			}
+		}
+	}
+	return sb.String()
+}
+
+// formatInodeTime convierte un timestamp Unix del inodo a texto
+func formatInodeTime(unixTime float64) string {
+	if unixTime <= 0 {
+		return "-"
+	}
+	return time.Unix(int64(unixTime), 0).Format("2006-01-02 15:04:05")
+}
+
+// parseOutputFormat interpreta -format (text por defecto o json)
+func parseOutputFormat(params map[string]string) (bool, error) {
+	switch strings.ToLower(params["format"]) {
+	case "", "text":
+		return false, nil
+	case "json":
+		return true, nil
+	default:
+		return false, fmt.Errorf("valor de -format debe ser: text o json")
+	}
+}
+
+// printJSON imprime el valor con sangría
+func printJSON(value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error generando JSON: %v", err)
+	}
+	Output.Println(string(data))
+	return nil
+}
@@ -0,0 +1,152 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Models"
+	"fmt"
+	"strings"
+)
+
+// LsListing es el contenido de una carpeta mostrado por ls
+type LsListing struct {
+	Path    string         `json:"path"`
+	Entries []InspectEntry `json:"entries"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// Ls - Función exportada para comando ls
+// Lista una carpeta de la partición de la sesión: -l formato largo, -R recursivo,
+// -a incluye "." , ".." y entradas ocultas, -format=json salida JSON.
+func Ls(params map[string]string) error {
+	asJSON, err := parseOutputFormat(params)
+	if err != nil {
+		return err
+	}
+	_, long := params["l"]
+	_, all := params["a"]
+	_, recursive := params["R"]
+	if _, lower := params["r"]; lower {
+		recursive = true
+	}
+
+	ctx, err := newInspectContext()
+	if err != nil {
+		return err
+	}
+
+	path := inspectPath(params)
+	inodeNum, inodo, err := ctx.lookup(path)
+	if err != nil {
+		return err
+	}
+
+	// Un archivo se lista a sí mismo
+	if inodo.I_type != Models.INODO_DIRECTORIO {
+		_, name := splitPath(path)
+		listing := LsListing{Path: path, Entries: []InspectEntry{ctx.newEntry(name, path, inodeNum, inodo)}}
+		if asJSON {
+			return printJSON(listing)
+		}
+		printLsEntries(listing.Entries, long)
+		return nil
+	}
+
+	if !ctx.canRead(inodo) {
+		return fmt.Errorf("ERROR: Sin permisos de lectura sobre '%s'", path)
+	}
+
+	listings := []LsListing{}
+	collectLsListings(ctx, path, inodo, all, recursive, &listings)
+
+	// Las carpetas listadas cuentan como accedidas
+	var listed []string
+	for _, listing := range listings {
+		if listing.Error == "" {
+			listed = append(listed, listing.Path)
+		}
+	}
+	Disk.TouchAccessTime(ctx.session.MountID, listed...)
+
+	if asJSON {
+		if recursive {
+			return printJSON(listings)
+		}
+		return printJSON(listings[0])
+	}
+
+	for i, listing := range listings {
+		if recursive {
+			if i > 0 {
+				Output.Println()
+			}
+			Output.Printf("%s:\n", listing.Path)
+		}
+		if listing.Error != "" {
+			Output.Println(listing.Error)
+			continue
+		}
+		printLsEntries(listing.Entries, long)
+	}
+	return nil
+}
+
+// collectLsListings agrega el contenido de la carpeta y, con -R, el de sus subcarpetas legibles
+func collectLsListings(ctx *inspectContext, dirPath string, dirInodo *Models.Inodo, all bool, recursive bool, listings *[]LsListing) {
+	listing := LsListing{Path: dirPath, Entries: []InspectEntry{}}
+	var subdirs []InspectEntry
+
+	for _, entry := range ctx.readEntries(dirPath, dirInodo) {
+		isSpecial := entry.Name == "." || entry.Name == ".."
+		if !all && strings.HasPrefix(entry.Name, ".") {
+			continue
+		}
+		listing.Entries = append(listing.Entries, entry)
+		if entry.Type == "dir" && !isSpecial {
+			subdirs = append(subdirs, entry)
+		}
+	}
+	*listings = append(*listings, listing)
+
+	if !recursive {
+		return
+	}
+
+	for _, subdir := range subdirs {
+		inodo, err := readInode(ctx.fileManager, subdir.Inode)
+		if err != nil {
+			continue
+		}
+		if !ctx.canRead(inodo) {
+			*listings = append(*listings, LsListing{
+				Path:    subdir.Path,
+				Entries: []InspectEntry{},
+				Error:   fmt.Sprintf("ERROR: Sin permisos de lectura sobre '%s'", subdir.Path),
+			})
+			continue
+		}
+		collectLsListings(ctx, subdir.Path, inodo, all, recursive, listings)
+	}
+}
+
+// printLsEntries imprime las entradas en formato corto o largo (-l)
+func printLsEntries(entries []InspectEntry, long bool) {
+	if !long {
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Name
+			if entry.Type == "dir" {
+				names[i] += "/"
+			}
+		}
+		if len(names) > 0 {
+			Output.Println(strings.Join(names, "  "))
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		Output.Printf("%s %-10s %-10s %8d %s %s\n",
+			permissionString(entry), entry.Owner, entry.Group, entry.Size, entry.MTime, entry.Name)
+	}
+}
@@ -2,10 +2,12 @@ package Operations
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Models"
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -38,7 +40,7 @@ func Move(params map[string]string) error {
 		sourceInodo.I_gid,
 		sourceInodo.I_perm,
 		session.UserID,
-		session.GroupID,
+		session.GroupIDs,
 	)
 
 	if !hasWritePermission {
@@ -61,7 +63,7 @@ func Move(params map[string]string) error {
 		destInodo.I_gid,
 		destInodo.I_perm,
 		session.UserID,
-		session.GroupID,
+		session.GroupIDs,
 	)
 
 	if !hasWritePermissionDest {
@@ -76,8 +78,11 @@ func Move(params map[string]string) error {
 		return nil
 	}
 
+	// Primero la entrada nueva: si no cabe, la original sigue en su lugar
+	if err := addEntryToDestinationDirectory(fileManager, destInodeNum, sourceName, sourceInodeNum); err != nil {
+		return fmt.Errorf("ERROR: No se pudo mover '%s': %v", sourcePath, err)
+	}
 	removeEntryFromParentDirectory(fileManager, sourceParentInodeNum, sourceInodeNum, sourceName)
-	addEntryToDestinationDirectory(fileManager, destInodeNum, sourceName, sourceInodeNum)
 
 	if sourceInodo.I_type == Models.INODO_DIRECTORIO {
 		updateParentReference(fileManager, sourceInodo, destInodeNum)
@@ -86,18 +91,15 @@ func Move(params map[string]string) error {
 	sourceInodo.I_mtime = float64(Models.GetCurrentUnixTime())
 	writeInode(fileManager, sourceInodeNum, sourceInodo)
 
+	Events.EmitFSMove(session.MountID, Events.ActionMoved, sourcePath, joinPath(destPath, sourceName))
 	return nil
 }
 
 func removeEntryFromParentDirectory(fileManager *System.EXT2FileManager, parentInodeNum int32, targetInodeNum int32, targetName string) {
 	parentInodo, _ := readInode(fileManager, parentInodeNum)
 
-	for i := 0; i < 12; i++ {
-		if parentInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, _ := readDirectoryBlock(fileManager, parentInodo.I_block[i])
+	for i, block := range fileManager.DirectoryBlocks(parentInodo) {
+		dirBlock, _ := readDirectoryBlock(fileManager, block)
 
 		for j := 0; j < len(dirBlock.B_content); j++ {
 			if dirBlock.B_content[j].B_inodo == targetInodeNum {
@@ -108,7 +110,8 @@ func removeEntryFromParentDirectory(fileManager *System.EXT2FileManager, parentI
 						dirBlock.B_content[j].B_name[k] = 0
 					}
 
-					writeDirectoryBlock(fileManager, parentInodo.I_block[i], dirBlock)
+					writeDirectoryBlock(fileManager, block, dirBlock)
+					fileManager.DirectoryEntryRemoved(parentInodeNum, parentInodo, i, dirBlock, targetName)
 					return
 				}
 			}
@@ -116,45 +119,8 @@ func removeEntryFromParentDirectory(fileManager *System.EXT2FileManager, parentI
 	}
 }
 
-func addEntryToDestinationDirectory(fileManager *System.EXT2FileManager, destInodeNum int32, fileName string, fileInodeNum int32) {
-	destInodo, _ := readInode(fileManager, destInodeNum)
-
-	for i := 0; i < 12; i++ {
-		if destInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, _ := readDirectoryBlock(fileManager, destInodo.I_block[i])
-
-		for j := 0; j < len(dirBlock.B_content); j++ {
-			if dirBlock.B_content[j].B_inodo == Models.FREE_INODE {
-				dirBlock.B_content[j].B_inodo = fileInodeNum
-				copy(dirBlock.B_content[j].B_name[:], fileName)
-				writeDirectoryBlock(fileManager, destInodo.I_block[i], dirBlock)
-				return
-			}
-		}
-	}
-
-	for i := 0; i < 12; i++ {
-		if destInodo.I_block[i] == Models.FREE_BLOCK {
-			newBlockNum, _ := findFreeBlock(fileManager)
-			destInodo.I_block[i] = newBlockNum
-
-			newDirBlock := &Models.BloqueCarpeta{}
-			for j := 0; j < len(newDirBlock.B_content); j++ {
-				newDirBlock.B_content[j].B_inodo = Models.FREE_INODE
-			}
-
-			newDirBlock.B_content[0].B_inodo = fileInodeNum
-			copy(newDirBlock.B_content[0].B_name[:], fileName)
-
-			writeDirectoryBlock(fileManager, newBlockNum, newDirBlock)
-			updateBlockBitmap(fileManager, newBlockNum, true)
-			writeInode(fileManager, destInodeNum, destInodo)
-			return
-		}
-	}
+func addEntryToDestinationDirectory(fileManager *System.EXT2FileManager, destInodeNum int32, fileName string, fileInodeNum int32) error {
+	return fileManager.AddDirectoryEntry(destInodeNum, fileName, fileInodeNum)
 }
 
 func updateParentReference(fileManager *System.EXT2FileManager, dirInodo *Models.Inodo, newParentInodeNum int32) {
@@ -0,0 +1,149 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Models"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// rootUserID es el UID que recibe las carpetas que no pueden eliminarse al purgar un usuario
+const rootUserID = 1
+
+// ReassignOwnedFiles cambia a newOwnerID el propietario de todo lo que pertenece a uid
+// en la partición de la sesión. Retorna las rutas modificadas.
+func ReassignOwnedFiles(uid int32, newOwnerID int32) ([]string, error) {
+	fileManager, mountID, err := sessionFileManager()
+	if err != nil {
+		return nil, err
+	}
+
+	owned, err := findOwnedPaths(fileManager, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range owned {
+		inodeNum, err := findFileInode(fileManager, path)
+		if err != nil {
+			continue
+		}
+		inodo, err := readInode(fileManager, inodeNum)
+		if err != nil {
+			continue
+		}
+		changeOwner(fileManager, inodeNum, inodo, newOwnerID)
+		Events.EmitFSChange(mountID, Events.ActionModified, path)
+	}
+
+	return owned, nil
+}
+
+// PurgeOwnedFiles elimina todo lo que pertenece a uid en la partición de la sesión.
+// Las carpetas que aún contienen entradas de otros usuarios se conservan y pasan a root.
+// Retorna las rutas eliminadas y las conservadas.
+func PurgeOwnedFiles(uid int32) ([]string, []string, error) {
+	fileManager, mountID, err := sessionFileManager()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	owned, err := findOwnedPaths(fileManager, uid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Procesar primero las rutas más profundas para vaciar las carpetas antes de eliminarlas
+	sort.SliceStable(owned, func(i, j int) bool {
+		return strings.Count(owned[i], "/") > strings.Count(owned[j], "/")
+	})
+
+	var removed, kept []string
+	for _, path := range owned {
+		inodeNum, err := findFileInode(fileManager, path)
+		if err != nil {
+			continue
+		}
+		inodo, err := readInode(fileManager, inodeNum)
+		if err != nil {
+			continue
+		}
+
+		if inodo.I_type == Models.INODO_DIRECTORIO && !isDirectoryEmpty(fileManager, inodo) {
+			changeOwner(fileManager, inodeNum, inodo, rootUserID)
+			kept = append(kept, path)
+			Events.EmitFSChange(mountID, Events.ActionModified, path)
+			continue
+		}
+
+		if inodo.I_type == Models.INODO_DIRECTORIO {
+			removeDirectory(fileManager, path, inodeNum, rootUserID, nil)
+		} else {
+			removeFile(fileManager, path, inodeNum, inodo)
+		}
+		removed = append(removed, path)
+		Events.EmitFSChange(mountID, Events.ActionRemoved, path)
+	}
+
+	return removed, kept, nil
+}
+
+// sessionFileManager crea el EXT2FileManager de la partición de la sesión activa
+func sessionFileManager() (*System.EXT2FileManager, string, error) {
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return nil, "", errors.New("ERROR: Debe iniciar sesión para usar este comando")
+	}
+
+	mountInfo, err := Disk.GetMountInfoByID(session.MountID)
+	if err != nil {
+		return nil, "", errors.New("ERROR: partición no encontrada")
+	}
+	partitionInfo, superBloque, err := Users.GetPartitionAndSuperBlock(mountInfo)
+	if err != nil {
+		return nil, "", errors.New("ERROR: error accediendo al sistema de archivos")
+	}
+
+	manager := &System.EXT2Manager{}
+	manager.SetPartitionInfo(partitionInfo)
+	manager.SetSuperBlock(superBloque)
+	manager.SetDiskPath(mountInfo.DiskPath)
+
+	return System.NewEXT2FileManager(manager), session.MountID, nil
+}
+
+// findOwnedPaths recorre el árbol desde la raíz y retorna las rutas cuyo propietario es uid
+func findOwnedPaths(fileManager *System.EXT2FileManager, uid int32) ([]string, error) {
+	var owned []string
+	err := System.NewEXT2DirectoryManager(fileManager.GetManager()).Walk("/", func(path string, entry System.DirectoryEntry) {
+		if entry.UID == uid {
+			owned = append(owned, path)
+		}
+	})
+	return owned, err
+}
+
+// isDirectoryEmpty indica si la carpeta solo contiene las entradas "." y ".."
+func isDirectoryEmpty(fileManager *System.EXT2FileManager, dirInodo *Models.Inodo) bool {
+	for _, block := range fileManager.DirectoryBlocks(dirInodo) {
+		dirBlock, err := readDirectoryBlock(fileManager, block)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range dirBlock.B_content {
+			if entry.B_inodo == Models.FREE_INODE {
+				continue
+			}
+			entryName := strings.TrimRight(string(entry.B_name[:]), "\x00")
+			if entryName != "." && entryName != ".." && entryName != "" {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -2,6 +2,8 @@ package Operations
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Models"
@@ -11,6 +13,7 @@ import (
 
 func Remove(params map[string]string) error {
 	path := params["path"]
+	_, force := params["force"]
 
 	session := Users.GetCurrentSession()
 	mountInfo, _ := Disk.GetMountInfoByID(session.MountID)
@@ -23,6 +26,9 @@ func Remove(params map[string]string) error {
 
 	fileManager := System.NewEXT2FileManager(manager)
 	path = normalizePath(path)
+	if path == "/" {
+		return errors.New("ERROR: No se puede eliminar la carpeta raíz")
+	}
 
 	inodeNum, err := findFileInode(fileManager, path)
 	if err != nil {
@@ -36,42 +42,56 @@ func Remove(params map[string]string) error {
 		inodo.I_gid,
 		inodo.I_perm,
 		session.UserID,
-		session.GroupID,
+		session.GroupIDs,
 	)
 
 	if !hasPermission {
 		return errors.New("ERROR: El archivo o carpeta no existe o no tiene permisos de escritura")
 	}
 
-	if inodo.I_type == Models.INODO_ARCHIVO {
-		removeFile(fileManager, path, inodeNum, inodo)
-		return nil
-	} else if inodo.I_type == Models.INODO_DIRECTORIO {
-		canDelete, _ := canDeleteDirectory(fileManager, path, session.UserID, session.GroupID)
+	if inodo.I_type == Models.INODO_DIRECTORIO {
+		canDelete, _ := canDeleteDirectory(fileManager, path, session.UserID, session.GroupIDs)
 
 		if !canDelete {
 			return errors.New("ERROR: El archivo o carpeta no existe o no tiene permisos de escritura")
 		}
+	}
+
+	// Sin -force la entrada va a la papelera; lo que ya está en ella se elimina definitivamente
+	if !force && !isTrashPath(path) {
+		id, err := moveToTrash(fileManager, session, path, inodeNum, inodo)
+		if err != nil {
+			return err
+		}
+		logJournalOperation(mountInfo, superBloque, "remove", path, "")
+		Events.EmitFSChange(session.MountID, Events.ActionRemoved, path)
+		Output.Printf("'%s' enviado a la papelera (id %d)\n", path, id)
+		return nil
+	}
 
-		removeDirectory(fileManager, path, inodeNum, session.UserID, session.GroupID)
+	if inodo.I_type == Models.INODO_ARCHIVO {
+		removeFile(fileManager, path, inodeNum, inodo)
+		logJournalOperation(mountInfo, superBloque, "remove", path, "")
+		Events.EmitFSChange(session.MountID, Events.ActionRemoved, path)
+		return nil
+	} else if inodo.I_type == Models.INODO_DIRECTORIO {
+		removeDirectory(fileManager, path, inodeNum, session.UserID, session.GroupIDs)
+		logJournalOperation(mountInfo, superBloque, "remove", path, "")
+		Events.EmitFSChange(session.MountID, Events.ActionRemoved, path)
 		return nil
 	}
 
 	return nil
 }
 
-func canDeleteDirectory(fileManager *System.EXT2FileManager, dirPath string, userID, groupID int) (bool, []string) {
+func canDeleteDirectory(fileManager *System.EXT2FileManager, dirPath string, userID int, groupIDs []int) (bool, []string) {
 	var failedItems []string
 
 	dirInodeNum, _ := findFileInode(fileManager, dirPath)
 	dirInodo, _ := readInode(fileManager, dirInodeNum)
 
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, _ := readDirectoryBlock(fileManager, dirInodo.I_block[i])
+	for _, block := range fileManager.DirectoryBlocks(dirInodo) {
+		dirBlock, _ := readDirectoryBlock(fileManager, block)
 
 		for _, entry := range dirBlock.B_content {
 			if entry.B_inodo == Models.FREE_INODE {
@@ -91,7 +111,7 @@ func canDeleteDirectory(fileManager *System.EXT2FileManager, dirPath string, use
 				entryInodo.I_gid,
 				entryInodo.I_perm,
 				userID,
-				groupID,
+				groupIDs,
 			)
 
 			if !hasPermission {
@@ -101,7 +121,7 @@ func canDeleteDirectory(fileManager *System.EXT2FileManager, dirPath string, use
 
 			if entryInodo.I_type == Models.INODO_DIRECTORIO {
 				subDirPath := dirPath + "/" + entryName
-				canDelete, subFailedItems := canDeleteDirectory(fileManager, subDirPath, userID, groupID)
+				canDelete, subFailedItems := canDeleteDirectory(fileManager, subDirPath, userID, groupIDs)
 				if !canDelete {
 					failedItems = append(failedItems, subFailedItems...)
 				}
@@ -112,15 +132,11 @@ func canDeleteDirectory(fileManager *System.EXT2FileManager, dirPath string, use
 	return len(failedItems) == 0, failedItems
 }
 
-func removeDirectory(fileManager *System.EXT2FileManager, dirPath string, dirInodeNum int32, userID, groupID int) {
+func removeDirectory(fileManager *System.EXT2FileManager, dirPath string, dirInodeNum int32, userID int, groupIDs []int) {
 	dirInodo, _ := readInode(fileManager, dirInodeNum)
 
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, _ := readDirectoryBlock(fileManager, dirInodo.I_block[i])
+	for _, block := range fileManager.DirectoryBlocks(dirInodo) {
+		dirBlock, _ := readDirectoryBlock(fileManager, block)
 
 		for _, entry := range dirBlock.B_content {
 			if entry.B_inodo == Models.FREE_INODE {
@@ -137,7 +153,7 @@ func removeDirectory(fileManager *System.EXT2FileManager, dirPath string, dirIno
 
 			if entryInodo.I_type == Models.INODO_DIRECTORIO {
 				subDirPath := dirPath + "/" + entryName
-				removeDirectory(fileManager, subDirPath, entry.B_inodo, userID, groupID)
+				removeDirectory(fileManager, subDirPath, entry.B_inodo, userID, groupIDs)
 			} else {
 				filePath := dirPath + "/" + entryName
 				removeFile(fileManager, filePath, entry.B_inodo, entryInodo)
@@ -145,22 +161,15 @@ func removeDirectory(fileManager *System.EXT2FileManager, dirPath string, dirIno
 		}
 	}
 
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] != Models.FREE_BLOCK {
-			updateBlockBitmap(fileManager, dirInodo.I_block[i], false)
-		}
-	}
+	fileManager.FreeInodeBlocks(dirInodo)
+	fileManager.FreeDirectoryIndex(dirInodo)
 
 	updateInodeBitmap(fileManager, dirInodeNum, false)
 	removeEntryFromParent(fileManager, dirPath, dirInodeNum)
 }
 
 func removeFile(fileManager *System.EXT2FileManager, filePath string, inodeNum int32, inodo *Models.Inodo) {
-	for i := 0; i < 12; i++ {
-		if inodo.I_block[i] != Models.FREE_BLOCK {
-			updateBlockBitmap(fileManager, inodo.I_block[i], false)
-		}
-	}
+	fileManager.FreeInodeBlocks(inodo)
 
 	updateInodeBitmap(fileManager, inodeNum, false)
 	removeEntryFromParent(fileManager, filePath, inodeNum)
@@ -172,12 +181,8 @@ func removeEntryFromParent(fileManager *System.EXT2FileManager, itemPath string,
 	parentInodeNum, _ := findFileInode(fileManager, parentPath)
 	parentInodo, _ := readInode(fileManager, parentInodeNum)
 
-	for i := 0; i < 12; i++ {
-		if parentInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, _ := readDirectoryBlock(fileManager, parentInodo.I_block[i])
+	for i, block := range fileManager.DirectoryBlocks(parentInodo) {
+		dirBlock, _ := readDirectoryBlock(fileManager, block)
 
 		for j := 0; j < len(dirBlock.B_content); j++ {
 			if dirBlock.B_content[j].B_inodo == inodeNum {
@@ -188,7 +193,8 @@ func removeEntryFromParent(fileManager *System.EXT2FileManager, itemPath string,
 						dirBlock.B_content[j].B_name[k] = 0
 					}
 
-					writeDirectoryBlock(fileManager, parentInodo.I_block[i], dirBlock)
+					writeDirectoryBlock(fileManager, block, dirBlock)
+					fileManager.DirectoryEntryRemoved(parentInodeNum, parentInodo, i, dirBlock, itemName)
 					return
 				}
 			}
@@ -2,6 +2,7 @@ package Operations
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Models"
@@ -41,7 +42,7 @@ func Rename(params map[string]string) error {
 		inodo.I_gid,
 		inodo.I_perm,
 		session.UserID,
-		session.GroupID,
+		session.GroupIDs,
 	)
 
 	if !hasWritePermission {
@@ -51,6 +52,9 @@ func Rename(params map[string]string) error {
 	parentPath, currentName := splitPath(path)
 	parentInodeNum, _ := findFileInode(fileManager, parentPath)
 	parentInodo, _ := readInode(fileManager, parentInodeNum)
+	if !System.ValidateFileWritePermission(parentInodo.I_uid, parentInodo.I_gid, parentInodo.I_perm, session.UserID, session.GroupIDs) {
+		return errors.New("ERROR: Sin permisos de escritura en directorio padre")
+	}
 
 	exists, _ := checkNameExistsInDirectory(fileManager, parentInodo, newName)
 
@@ -58,20 +62,17 @@ func Rename(params map[string]string) error {
 		return errors.New("ERROR: Ya existe un archivo con el mismo nombre")
 	}
 
-	renameEntryInDirectory(fileManager, parentInodo, inodeNum, currentName, newName)
+	renameEntryInDirectory(fileManager, parentInodeNum, parentInodo, inodeNum, currentName, newName)
 	inodo.I_mtime = float64(Models.GetCurrentUnixTime())
 	writeInode(fileManager, inodeNum, inodo)
 
+	Events.EmitFSMove(session.MountID, Events.ActionRenamed, path, joinPath(parentPath, newName))
 	return nil
 }
 
-func renameEntryInDirectory(fileManager *System.EXT2FileManager, dirInodo *Models.Inodo, inodeNum int32, oldName, newName string) error {
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, _ := readDirectoryBlock(fileManager, dirInodo.I_block[i])
+func renameEntryInDirectory(fileManager *System.EXT2FileManager, dirInodeNum int32, dirInodo *Models.Inodo, inodeNum int32, oldName, newName string) error {
+	for i, block := range fileManager.DirectoryBlocks(dirInodo) {
+		dirBlock, _ := readDirectoryBlock(fileManager, block)
 
 		for j := 0; j < len(dirBlock.B_content); j++ {
 			if dirBlock.B_content[j].B_inodo == inodeNum {
@@ -81,7 +82,9 @@ func renameEntryInDirectory(fileManager *System.EXT2FileManager, dirInodo *Model
 						dirBlock.B_content[j].B_name[k] = 0
 					}
 					copy(dirBlock.B_content[j].B_name[:], newName)
-					writeDirectoryBlock(fileManager, dirInodo.I_block[i], dirBlock)
+					writeDirectoryBlock(fileManager, block, dirBlock)
+					fileManager.DirectoryEntryRemoved(dirInodeNum, dirInodo, i, dirBlock, oldName)
+					fileManager.DirectoryEntryAdded(dirInodeNum, dirInodo, i, newName)
 					return nil
 				}
 			}
@@ -0,0 +1,114 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"fmt"
+	"strings"
+)
+
+// StatInfo contiene todos los campos del inodo de una ruta
+type StatInfo struct {
+	Path        string    `json:"path"`
+	Inode       int32     `json:"inode"`
+	Type        string    `json:"type"`
+	UID         int32     `json:"uid"`
+	Owner       string    `json:"owner"`
+	GID         int32     `json:"gid"`
+	Group       string    `json:"group"`
+	Size        int32     `json:"size"`
+	Permissions string    `json:"permissions"`
+	ATime       string    `json:"atime"`
+	CTime       string    `json:"ctime"`
+	MTime       string    `json:"mtime"`
+	Blocks      [15]int32 `json:"blocks"`
+	Indexed     bool      `json:"indexed"`
+}
+
+// Stat - Función exportada para comando stat
+// Muestra todos los campos del inodo de -path, incluida la lista de bloques.
+// Requiere permiso de lectura sobre la carpeta que contiene la ruta.
+func Stat(params map[string]string) error {
+	asJSON, err := parseOutputFormat(params)
+	if err != nil {
+		return err
+	}
+
+	path, hasPath := params["path"]
+	if !hasPath || path == "" {
+		return fmt.Errorf("parametro -path requerido")
+	}
+
+	ctx, err := newInspectContext()
+	if err != nil {
+		return err
+	}
+
+	path = normalizePath(path)
+	inodeNum, inodo, err := ctx.lookup(path)
+	if err != nil {
+		return err
+	}
+
+	// Los metadatos de una entrada son visibles para quien puede leer su carpeta
+	checkInodo := inodo
+	if path != "/" {
+		parentPath, _ := splitPath(path)
+		_, checkInodo, err = ctx.lookup(parentPath)
+		if err != nil {
+			return err
+		}
+	}
+	if !ctx.canRead(checkInodo) {
+		return fmt.Errorf("ERROR: Sin permisos de lectura sobre '%s'", path)
+	}
+
+	entry := ctx.newEntry(path, path, inodeNum, inodo)
+	info := StatInfo{
+		Path:        path,
+		Inode:       inodeNum,
+		Type:        entry.Type,
+		UID:         inodo.I_uid,
+		Owner:       entry.Owner,
+		GID:         inodo.I_gid,
+		Group:       entry.Group,
+		Size:        inodo.I_s,
+		Permissions: entry.Permissions,
+		ATime:       formatInodeTime(inodo.I_atime),
+		CTime:       formatInodeTime(inodo.I_ctime),
+		MTime:       formatInodeTime(inodo.I_mtime),
+		Blocks:      inodo.I_block,
+		Indexed:     System.HasDirectoryIndex(inodo),
+	}
+
+	if asJSON {
+		return printJSON(info)
+	}
+
+	Output.Printf("  Ruta: %s\n", info.Path)
+	Output.Printf(" Inodo: %-10d Tipo: %s\n", info.Inode, info.Type)
+	Output.Printf("Tamaño: %d bytes\n", info.Size)
+	Output.Printf("Acceso: (%s/%s)  Uid: (%d/%s)  Gid: (%d/%s)\n",
+		info.Permissions, permissionString(entry), info.UID, info.Owner, info.GID, info.Group)
+	Output.Printf("Último acceso:       %s\n", info.ATime)
+	Output.Printf("Creación:            %s\n", info.CTime)
+	Output.Printf("Última modificación: %s\n", info.MTime)
+	Output.Printf("Bloques directos:    %s\n", formatBlockList(info.Blocks[:12]))
+	Output.Printf("Indirecto simple:    %d\n", info.Blocks[12])
+	Output.Printf("Indirecto doble:     %d\n", info.Blocks[13])
+	if info.Indexed {
+		Output.Printf("Índice de carpeta:   %d\n", info.Blocks[14])
+	} else {
+		Output.Printf("Indirecto triple:    %d\n", info.Blocks[14])
+	}
+	return nil
+}
+
+// formatBlockList une los apuntadores de bloque (FREE_BLOCK = -1 indica libre)
+func formatBlockList(blocks []int32) string {
+	parts := make([]string, len(blocks))
+	for i, block := range blocks {
+		parts[i] = fmt.Sprintf("%d", block)
+	}
+	return strings.Join(parts, " ")
+}
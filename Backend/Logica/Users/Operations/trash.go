@@ -0,0 +1,440 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Models"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// La papelera de cada partición vive en /.trash. Cada entrada eliminada se guarda en
+// /.trash/<id>/<nombre original> junto a /.trash/<id>/.info con la ruta original,
+// el propietario, quién la eliminó y cuándo.
+const (
+	trashPath     = "/.trash"
+	trashInfoName = ".info"
+)
+
+// TrashEntry describe un elemento de la papelera
+type TrashEntry struct {
+	ID           int     `json:"id"`
+	OriginalPath string  `json:"originalPath"`
+	Type         string  `json:"type"`
+	OwnerUID     int32   `json:"ownerUid"`
+	Owner        string  `json:"owner"`
+	DeletedByUID int32   `json:"deletedByUid"`
+	DeletedBy    string  `json:"deletedBy"`
+	DeletedAt    float64 `json:"-"`
+	Date         string  `json:"date"`
+}
+
+// Trash - Función exportada para comando trash
+// -list muestra los elementos de la papelera visibles para el usuario (root ve todos) y
+// -empty los elimina definitivamente; con -older-than=N[s|m|h|d] solo los más antiguos.
+func Trash(params map[string]string) error {
+	_, list := params["list"]
+	_, empty := params["empty"]
+	if list == empty {
+		return errors.New("ERROR: Debe indicar -list o -empty")
+	}
+
+	ctx, err := newInspectContext()
+	if err != nil {
+		return err
+	}
+
+	if list {
+		asJSON, err := parseOutputFormat(params)
+		if err != nil {
+			return err
+		}
+		return listTrash(ctx, asJSON)
+	}
+
+	olderThan := 0.0
+	if value, ok := params["older-than"]; ok {
+		filter, err := parseTimeFilter(value)
+		if err != nil || filter.sign != 0 {
+			return errors.New("valor de -older-than invalido: use N con unidad s, m, h o d (ej: 7d)")
+		}
+		olderThan = float64(filter.value) * filter.unit
+	}
+	return emptyTrash(ctx, olderThan)
+}
+
+// Restore - Función exportada para comando restore
+// Devuelve a su ruta original el elemento más reciente de la papelera con esa ruta
+// (o el indicado con -id). La carpeta original debe existir y permitir escritura.
+func Restore(params map[string]string) error {
+	path, hasPath := params["path"]
+	if !hasPath || path == "" {
+		return fmt.Errorf("parametro -path requerido")
+	}
+	path = normalizePath(path)
+
+	ctx, err := newInspectContext()
+	if err != nil {
+		return err
+	}
+
+	entries := visibleTrashEntries(ctx)
+	var entry *TrashEntry
+	for i := range entries {
+		if entries[i].OriginalPath != path {
+			continue
+		}
+		if idStr, ok := params["id"]; ok && idStr != strconv.Itoa(entries[i].ID) {
+			continue
+		}
+		if entry == nil || entries[i].DeletedAt > entry.DeletedAt {
+			entry = &entries[i]
+		}
+	}
+	if entry == nil {
+		return fmt.Errorf("ERROR: '%s' no existe en la papelera", path)
+	}
+
+	parentPath, name := splitPath(path)
+	parentInodeNum, parentInodo, err := ctx.lookup(parentPath)
+	if err != nil || parentInodo.I_type != Models.INODO_DIRECTORIO {
+		return fmt.Errorf("ERROR: La carpeta original '%s' ya no existe", parentPath)
+	}
+	if !System.ValidateFileWritePermission(parentInodo.I_uid, parentInodo.I_gid, parentInodo.I_perm, ctx.session.UserID, ctx.session.GroupIDs) {
+		return errors.New("ERROR: Sin permisos de escritura en directorio padre")
+	}
+	if exists, _ := checkNameExistsInDirectory(ctx.fileManager, parentInodo, name); exists {
+		return fmt.Errorf("ERROR: Ya existe '%s'; renombre o elimine la entrada actual antes de restaurar", path)
+	}
+
+	itemDir := trashItemPath(entry.ID)
+	itemDirInodeNum, _, err := ctx.lookup(itemDir)
+	if err != nil {
+		return err
+	}
+	itemInodeNum, itemInodo, err := ctx.lookup(joinPath(itemDir, name))
+	if err != nil {
+		return err
+	}
+
+	if err := addEntryToDestinationDirectory(ctx.fileManager, parentInodeNum, name, itemInodeNum); err != nil {
+		return fmt.Errorf("ERROR: No se pudo restaurar '%s': %v", path, err)
+	}
+	removeEntryFromParentDirectory(ctx.fileManager, itemDirInodeNum, itemInodeNum, name)
+	if itemInodo.I_type == Models.INODO_DIRECTORIO {
+		updateParentReference(ctx.fileManager, itemInodo, parentInodeNum)
+	}
+
+	// Eliminar el contenedor vacío (solo queda .info)
+	removeDirectory(ctx.fileManager, itemDir, itemDirInodeNum, ctx.session.UserID, ctx.session.GroupIDs)
+
+	logTrashJournal(ctx, "restore", path, inodeTypeName(itemInodo))
+	Events.EmitFSChange(ctx.session.MountID, Events.ActionCreated, path)
+	Output.Printf("'%s' restaurado desde la papelera\n", path)
+	return nil
+}
+
+// moveToTrash mueve la entrada a /.trash/<id> y guarda sus metadatos. Retorna el id asignado.
+// La papelera es de root con permisos 755 para que nadie más agregue o renombre carpetas
+// en ella; la carpeta de cada elemento la crea este comando, no el usuario, y queda a
+// nombre de quien elimina con permisos 700.
+func moveToTrash(fileManager *System.EXT2FileManager, session *Users.Session, path string, inodeNum int32, inodo *Models.Inodo) (int, error) {
+	dirManager := System.NewEXT2DirectoryManager(fileManager.GetManager())
+
+	trashInodeNum, err := findFileInode(fileManager, trashPath)
+	if err != nil {
+		if err := dirManager.CreateDirectory(trashPath, 1, 1, 755); err != nil {
+			return 0, fmt.Errorf("ERROR: No se pudo crear la papelera: %v; use remove -force", err)
+		}
+		if trashInodeNum, err = findFileInode(fileManager, trashPath); err != nil {
+			return 0, err
+		}
+	}
+
+	trashInodo, err := readInode(fileManager, trashInodeNum)
+	if err != nil {
+		return 0, err
+	}
+	// Las papeleras creadas antes con 777 se restringen
+	if trashInodo.I_uid != 1 || Models.GetPermissions(trashInodo.I_perm) != 755 {
+		trashInodo.I_uid, trashInodo.I_gid = 1, 1
+		trashInodo.I_perm = Models.SetPermissions(755)
+		if err := writeInode(fileManager, trashInodeNum, trashInodo); err != nil {
+			return 0, err
+		}
+	}
+	id := nextTrashID(fileManager, trashInodo)
+
+	// Si no queda espacio se descartan los elementos más antiguos del usuario (root
+	// puede descartar cualquiera) hasta que quepa; cualquier otro error se retorna
+	itemDir := trashItemPath(id)
+	info := fmt.Sprintf("path=%s\ntype=%s\nuid=%d\ndeleted_by=%d\ndate=%d\n",
+		path, inodeTypeName(inodo), inodo.I_uid, session.UserID, Models.GetCurrentUnixTime())
+	for {
+		err := createTrashItem(fileManager, dirManager, itemDir, info, int32(session.UserID), int32(session.GroupID))
+		if err == nil {
+			break
+		}
+		if !System.IsOutOfSpace(err) {
+			return 0, fmt.Errorf("ERROR: No se pudo guardar en la papelera: %v; use remove -force", err)
+		}
+		evicted, ok := evictOldestTrashEntry(fileManager, trashInodeNum, session)
+		if !ok {
+			return 0, fmt.Errorf("ERROR: No se pudo guardar en la papelera: %v; use remove -force", err)
+		}
+		Output.Printf("Papelera llena: el elemento %d se eliminó definitivamente\n", evicted)
+	}
+
+	itemDirInodeNum, err := findFileInode(fileManager, itemDir)
+	if err != nil {
+		return 0, err
+	}
+
+	parentPath, name := splitPath(path)
+	parentInodeNum, err := findFileInode(fileManager, parentPath)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := addEntryToDestinationDirectory(fileManager, itemDirInodeNum, name, inodeNum); err != nil {
+		return 0, err
+	}
+	removeEntryFromParentDirectory(fileManager, parentInodeNum, inodeNum, name)
+	if inodo.I_type == Models.INODO_DIRECTORIO {
+		updateParentReference(fileManager, inodo, itemDirInodeNum)
+	}
+
+	return id, nil
+}
+
+// createTrashItem crea la carpeta del elemento con su archivo .info. Si el archivo no
+// cabe se elimina la carpeta, para que un nuevo intento empiece de cero.
+func createTrashItem(fileManager *System.EXT2FileManager, dirManager *System.EXT2DirectoryManager, itemDir string, info string, uid int32, gid int32) error {
+	if err := dirManager.CreateDirectory(itemDir, uid, gid, 700); err != nil {
+		return err
+	}
+	err := fileManager.WriteFileContent(joinPath(itemDir, trashInfoName), info, uid, gid, 600)
+	if err == nil {
+		return nil
+	}
+	if itemDirInodeNum, findErr := findFileInode(fileManager, itemDir); findErr == nil {
+		removeDirectory(fileManager, itemDir, itemDirInodeNum, 1, []int{1})
+	}
+	return err
+}
+
+// evictOldestTrashEntry elimina definitivamente el elemento de menor id que el usuario
+// puede eliminar (canManageTrashEntry); es el más antiguo porque los ids solo crecen.
+// Retorna false si no hay ninguno.
+func evictOldestTrashEntry(fileManager *System.EXT2FileManager, trashInodeNum int32, session *Users.Session) (int, bool) {
+	trashInodo, err := readInode(fileManager, trashInodeNum)
+	if err != nil {
+		return 0, false
+	}
+
+	oldest := 0
+	for _, name := range directoryNames(fileManager, trashInodo) {
+		id, err := strconv.Atoi(name)
+		if err != nil || (oldest != 0 && id >= oldest) {
+			continue
+		}
+		if entry, err := readTrashInfo(fileManager, id); err == nil && canManageTrashEntry(session, entry) {
+			oldest = id
+		}
+	}
+	if oldest == 0 {
+		return 0, false
+	}
+
+	itemDir := trashItemPath(oldest)
+	itemDirInodeNum, err := findFileInode(fileManager, itemDir)
+	if err != nil {
+		return 0, false
+	}
+	removeDirectory(fileManager, itemDir, itemDirInodeNum, 1, []int{1})
+	return oldest, true
+}
+
+// isTrashPath indica si la ruta es la papelera o está dentro de ella
+func isTrashPath(path string) bool {
+	return path == trashPath || strings.HasPrefix(path, trashPath+"/")
+}
+
+// trashItemPath retorna la carpeta contenedora de un elemento de la papelera
+func trashItemPath(id int) string {
+	return joinPath(trashPath, strconv.Itoa(id))
+}
+
+// nextTrashID retorna el siguiente id libre dentro de /.trash
+func nextTrashID(fileManager *System.EXT2FileManager, trashInodo *Models.Inodo) int {
+	maxID := 0
+	for _, name := range directoryNames(fileManager, trashInodo) {
+		if id, err := strconv.Atoi(name); err == nil && id > maxID {
+			maxID = id
+		}
+	}
+	return maxID + 1
+}
+
+// directoryNames retorna los nombres de una carpeta sin "." ni ".."
+func directoryNames(fileManager *System.EXT2FileManager, dirInodo *Models.Inodo) []string {
+	var names []string
+	for _, block := range fileManager.DirectoryBlocks(dirInodo) {
+		dirBlock, err := readDirectoryBlock(fileManager, block)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range dirBlock.B_content {
+			if entry.B_inodo == Models.FREE_INODE {
+				continue
+			}
+			name := strings.TrimRight(string(entry.B_name[:]), "\x00")
+			if name != "" && name != "." && name != ".." {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// readTrashEntries lee los metadatos de todos los elementos de la papelera
+func readTrashEntries(ctx *inspectContext) []TrashEntry {
+	entries := []TrashEntry{}
+
+	_, trashInodo, err := ctx.lookup(trashPath)
+	if err != nil {
+		return entries
+	}
+
+	for _, name := range directoryNames(ctx.fileManager, trashInodo) {
+		id, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		entry, err := readTrashInfo(ctx.fileManager, id)
+		if err != nil {
+			continue
+		}
+		entry.Owner = ctx.ownerName(entry.OwnerUID)
+		entry.DeletedBy = ctx.ownerName(entry.DeletedByUID)
+		entry.Date = formatInodeTime(entry.DeletedAt)
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// readTrashInfo lee los metadatos del elemento id desde su archivo .info
+func readTrashInfo(fileManager *System.EXT2FileManager, id int) (TrashEntry, error) {
+	entry := TrashEntry{ID: id}
+	content, err := fileManager.ReadFileContent(joinPath(trashItemPath(id), trashInfoName))
+	if err != nil {
+		return entry, err
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "path":
+			entry.OriginalPath = value
+		case "type":
+			entry.Type = value
+		case "uid":
+			uid, _ := strconv.Atoi(value)
+			entry.OwnerUID = int32(uid)
+		case "deleted_by":
+			uid, _ := strconv.Atoi(value)
+			entry.DeletedByUID = int32(uid)
+		case "date":
+			date, _ := strconv.ParseFloat(value, 64)
+			entry.DeletedAt = date
+		}
+	}
+	return entry, nil
+}
+
+// canManageTrashEntry indica si el usuario puede ver, restaurar o eliminar el elemento:
+// root todos, los demás los que les pertenecen o que ellos eliminaron
+func canManageTrashEntry(session *Users.Session, entry TrashEntry) bool {
+	uid := int32(session.UserID)
+	return session.UserID == 1 || entry.OwnerUID == uid || entry.DeletedByUID == uid
+}
+
+// visibleTrashEntries filtra los elementos que el usuario puede ver
+func visibleTrashEntries(ctx *inspectContext) []TrashEntry {
+	visible := []TrashEntry{}
+	for _, entry := range readTrashEntries(ctx) {
+		if canManageTrashEntry(ctx.session, entry) {
+			visible = append(visible, entry)
+		}
+	}
+	return visible
+}
+
+// listTrash muestra los elementos visibles de la papelera
+func listTrash(ctx *inspectContext, asJSON bool) error {
+	entries := visibleTrashEntries(ctx)
+	if asJSON {
+		return printJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		Output.Println("La papelera está vacía")
+		return nil
+	}
+
+	Output.Printf("%-4s %-19s %-4s %-10s %-10s %s\n", "ID", "FECHA", "TIPO", "DUEÑO", "ELIMINÓ", "RUTA ORIGINAL")
+	for _, entry := range entries {
+		Output.Printf("%-4d %-19s %-4s %-10s %-10s %s\n",
+			entry.ID, entry.Date, entry.Type, entry.Owner, entry.DeletedBy, entry.OriginalPath)
+	}
+	return nil
+}
+
+// emptyTrash elimina definitivamente los elementos visibles con más de olderThan segundos
+func emptyTrash(ctx *inspectContext, olderThan float64) error {
+	now := float64(Models.GetCurrentUnixTime())
+	removed := 0
+
+	for _, entry := range visibleTrashEntries(ctx) {
+		if now-entry.DeletedAt < olderThan {
+			continue
+		}
+
+		itemDir := trashItemPath(entry.ID)
+		itemDirInodeNum, err := findFileInode(ctx.fileManager, itemDir)
+		if err != nil {
+			continue
+		}
+		removeDirectory(ctx.fileManager, itemDir, itemDirInodeNum, ctx.session.UserID, ctx.session.GroupIDs)
+		removed++
+	}
+
+	if removed > 0 {
+		logTrashJournal(ctx, "remove", trashPath, "")
+		Events.EmitFSChange(ctx.session.MountID, Events.ActionModified, trashPath)
+	}
+	Output.Printf("Papelera: %d elemento(s) eliminado(s) definitivamente\n", removed)
+	return nil
+}
+
+// logTrashJournal registra la operación de papelera en el journal de EXT3. En restore el
+// contenido es el tipo del elemento (dir o file), que recovery necesita para recrearlo.
+func logTrashJournal(ctx *inspectContext, operation string, path string, content string) {
+	mountInfo, err := Disk.GetMountInfoByID(ctx.session.MountID)
+	if err != nil {
+		return
+	}
+	logJournalOperation(mountInfo, ctx.fileManager.GetManager().GetSuperBlock(), operation, path, content)
+}
@@ -0,0 +1,109 @@
+package Operations
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Models"
+	"fmt"
+	"strconv"
+)
+
+// TreeNode es un nodo del árbol mostrado por tree
+type TreeNode struct {
+	InspectEntry
+	Denied   bool       `json:"denied,omitempty"`
+	Children []TreeNode `json:"children,omitempty"`
+}
+
+// Tree - Función exportada para comando tree
+// Muestra el árbol de carpetas desde -path hasta -depth niveles (sin límite por defecto).
+func Tree(params map[string]string) error {
+	asJSON, err := parseOutputFormat(params)
+	if err != nil {
+		return err
+	}
+
+	maxDepth := -1
+	if depthStr, ok := params["depth"]; ok {
+		maxDepth, err = strconv.Atoi(depthStr)
+		if err != nil || maxDepth < 0 {
+			return fmt.Errorf("ERROR: -depth debe ser un entero mayor o igual a 0")
+		}
+	}
+
+	ctx, err := newInspectContext()
+	if err != nil {
+		return err
+	}
+
+	path := inspectPath(params)
+	inodeNum, inodo, err := ctx.lookup(path)
+	if err != nil {
+		return err
+	}
+	if inodo.I_type == Models.INODO_DIRECTORIO && !ctx.canRead(inodo) {
+		return fmt.Errorf("ERROR: Sin permisos de lectura sobre '%s'", path)
+	}
+
+	root := TreeNode{InspectEntry: ctx.newEntry(path, path, inodeNum, inodo)}
+	dirs, files := buildTree(ctx, &root, inodo, 0, maxDepth)
+
+	if asJSON {
+		return printJSON(root)
+	}
+
+	Output.Println(root.Path)
+	printTreeNodes(root.Children, "")
+	Output.Printf("\n%d carpetas, %d archivos\n", dirs, files)
+	return nil
+}
+
+// buildTree llena los hijos del nodo y retorna cuántas carpetas y archivos agregó
+func buildTree(ctx *inspectContext, node *TreeNode, inodo *Models.Inodo, depth int, maxDepth int) (int, int) {
+	if inodo.I_type != Models.INODO_DIRECTORIO || (maxDepth >= 0 && depth >= maxDepth) {
+		return 0, 0
+	}
+
+	dirs, files := 0, 0
+	for _, entry := range ctx.readEntries(node.Path, inodo) {
+		if entry.Name == "." || entry.Name == ".." {
+			continue
+		}
+
+		child := TreeNode{InspectEntry: entry}
+		if entry.Type == "dir" {
+			dirs++
+			childInodo, err := readInode(ctx.fileManager, entry.Inode)
+			if err != nil {
+				continue
+			}
+			if !ctx.canRead(childInodo) {
+				child.Denied = true
+			} else {
+				subDirs, subFiles := buildTree(ctx, &child, childInodo, depth+1, maxDepth)
+				dirs += subDirs
+				files += subFiles
+			}
+		} else {
+			files++
+		}
+		node.Children = append(node.Children, child)
+	}
+	return dirs, files
+}
+
+// printTreeNodes imprime los hijos con las ramas del árbol
+func printTreeNodes(nodes []TreeNode, prefix string) {
+	for i, node := range nodes {
+		branch, nextPrefix := "├── ", prefix+"│   "
+		if i == len(nodes)-1 {
+			branch, nextPrefix = "└── ", prefix+"    "
+		}
+
+		suffix := ""
+		if node.Denied {
+			suffix = " [sin permiso de lectura]"
+		}
+		Output.Printf("%s%s%s%s\n", prefix, branch, node.Name, suffix)
+		printTreeNodes(node.Children, nextPrefix)
+	}
+}
@@ -1,25 +1,24 @@
 package Operations
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Models"
 	"bytes"
 	"encoding/binary"
-	"errors"
-	"os"
 	"strings"
 )
 
 // ========== FUNCIONES AUXILIARES COMPARTIDAS ==========
 
+// normalizePath convierte la ruta en absoluta resolviendo las rutas relativas
+// contra el directorio de trabajo de la sesión
 func normalizePath(path string) string {
 	if path == "" {
 		return "/"
 	}
-	if !strings.HasPrefix(path, "/") {
-		path = "/" + path
-	}
-	return path
+	return Users.ResolvePath(path)
 }
 
 func splitPath(filePath string) (string, string) {
@@ -36,61 +35,22 @@ func splitPath(filePath string) (string, string) {
 	return parentPath, fileName
 }
 
-func findFileInode(fileManager *System.EXT2FileManager, filePath string) (int32, error) {
-	if filePath == "/" {
-		return Models.ROOT_INODE, nil
-	}
-
-	pathParts := strings.Split(strings.Trim(filePath, "/"), "/")
-	currentInode := Models.ROOT_INODE
-
-	for _, part := range pathParts {
-		if part == "" {
-			continue
-		}
-
-		inodo, err := readInode(fileManager, int32(currentInode))
-		if err != nil {
-			return -1, err
-		}
-
-		if inodo.I_type != Models.INODO_DIRECTORIO {
-			return -1, errors.New("no es un directorio")
-		}
-
-		nextInode, err := findInDirectory(fileManager, inodo, part)
-		if err != nil {
-			return -1, err
-		}
-
-		currentInode = int(nextInode)
+// joinPath une una carpeta y un nombre evitando dobles separadores
+func joinPath(dirPath string, name string) string {
+	if dirPath == "/" {
+		return "/" + name
 	}
+	return strings.TrimRight(dirPath, "/") + "/" + name
+}
 
-	return int32(currentInode), nil
+// findFileInode resuelve la ruta con la caché de rutas compartida de la partición
+func findFileInode(fileManager *System.EXT2FileManager, filePath string) (int32, error) {
+	return fileManager.LookupPath(filePath)
 }
 
+// findInDirectory busca una entrada en la carpeta (con su índice de hash si lo tiene)
 func findInDirectory(fileManager *System.EXT2FileManager, dirInodo *Models.Inodo, filename string) (int32, error) {
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, err := readDirectoryBlock(fileManager, dirInodo.I_block[i])
-		if err != nil {
-			return -1, err
-		}
-
-		for _, entry := range dirBlock.B_content {
-			if entry.B_inodo != Models.FREE_INODE {
-				entryName := strings.TrimRight(string(entry.B_name[:]), "\x00")
-				if entryName == filename {
-					return entry.B_inodo, nil
-				}
-			}
-		}
-	}
-
-	return -1, errors.New("archivo no encontrado")
+	return fileManager.FindInDirectory(dirInodo, filename)
 }
 
 func readInode(fileManager *System.EXT2FileManager, inodeNumber int32) (*Models.Inodo, error) {
@@ -99,7 +59,7 @@ func readInode(fileManager *System.EXT2FileManager, inodeNumber int32) (*Models.
 	partitionInfo := manager.GetPartitionInfo()
 	superBloque := manager.GetSuperBlock()
 
-	file, err := os.Open(diskPath)
+	file, err := Device.Open(diskPath)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +86,7 @@ func readDirectoryBlock(fileManager *System.EXT2FileManager, blockNumber int32)
 	partitionInfo := manager.GetPartitionInfo()
 	superBloque := manager.GetSuperBlock()
 
-	file, err := os.Open(diskPath)
+	file, err := Device.Open(diskPath)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +113,7 @@ func writeDirectoryBlock(fileManager *System.EXT2FileManager, blockNumber int32,
 	partitionInfo := manager.GetPartitionInfo()
 	superBloque := manager.GetSuperBlock()
 
-	file, err := os.OpenFile(diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(diskPath)
 	if err != nil {
 		return err
 	}
@@ -171,94 +131,20 @@ func writeDirectoryBlock(fileManager *System.EXT2FileManager, blockNumber int32,
 		return err
 	}
 
-	_, err = file.Write(buffer.Bytes())
-	return err
-}
-
-func updateInodeBitmap(fileManager *System.EXT2FileManager, inodeNumber int32, used bool) error {
-	manager := fileManager.GetManager()
-	diskPath := manager.GetDiskPath()
-	partitionInfo := manager.GetPartitionInfo()
-	superBloque := manager.GetSuperBlock()
-
-	file, err := os.OpenFile(diskPath, os.O_RDWR, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Leer bitmap
-	bitmapPos := partitionInfo.PartStart + int64(superBloque.S_bm_inode_start)
-	_, err = file.Seek(bitmapPos, 0)
-	if err != nil {
-		return err
-	}
-
-	bitmapSize := int(superBloque.S_inodes_count/8 + 1)
-	bitmap := make([]byte, bitmapSize)
-	_, err = file.Read(bitmap)
-	if err != nil {
-		return err
-	}
-
-	// Actualizar bit
-	if used {
-		Models.SetBitmapBit(bitmap, int(inodeNumber))
-	} else {
-		Models.ClearBitmapBit(bitmap, int(inodeNumber))
-	}
-
-	// Escribir bitmap
-	_, err = file.Seek(bitmapPos, 0)
-	if err != nil {
+	if _, err = file.Write(buffer.Bytes()); err != nil {
 		return err
 	}
+	return System.MarkPartitionModified(diskPath, partitionInfo.PartStart)
+}
 
-	_, err = file.Write(bitmap)
-	return err
+// updateInodeBitmap marca un inodo como usado o libre (contadores y pistas incluidos)
+func updateInodeBitmap(fileManager *System.EXT2FileManager, inodeNumber int32, used bool) error {
+	return fileManager.SetInodeUsed(inodeNumber, used)
 }
 
+// updateBlockBitmap marca un bloque como usado o libre (contadores y pistas incluidos)
 func updateBlockBitmap(fileManager *System.EXT2FileManager, blockNumber int32, used bool) error {
-	manager := fileManager.GetManager()
-	diskPath := manager.GetDiskPath()
-	partitionInfo := manager.GetPartitionInfo()
-	superBloque := manager.GetSuperBlock()
-
-	file, err := os.OpenFile(diskPath, os.O_RDWR, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// Leer bitmap
-	bitmapPos := partitionInfo.PartStart + int64(superBloque.S_bm_block_start)
-	_, err = file.Seek(bitmapPos, 0)
-	if err != nil {
-		return err
-	}
-
-	bitmapSize := int(superBloque.S_blocks_count/8 + 1)
-	bitmap := make([]byte, bitmapSize)
-	_, err = file.Read(bitmap)
-	if err != nil {
-		return err
-	}
-
-	// Actualizar bit
-	if used {
-		Models.SetBitmapBit(bitmap, int(blockNumber))
-	} else {
-		Models.ClearBitmapBit(bitmap, int(blockNumber))
-	}
-
-	// Escribir bitmap
-	_, err = file.Seek(bitmapPos, 0)
-	if err != nil {
-		return err
-	}
-
-	_, err = file.Write(bitmap)
-	return err
+	return fileManager.SetBlockUsed(blockNumber, used)
 }
 
 // writeInode escribe un inodo en el disco
@@ -268,7 +154,7 @@ func writeInode(fileManager *System.EXT2FileManager, inodeNumber int32, inodo *M
 	partitionInfo := manager.GetPartitionInfo()
 	superBloque := manager.GetSuperBlock()
 
-	file, err := os.OpenFile(diskPath, os.O_RDWR, 0644)
+	file, err := Device.OpenWrite(diskPath)
 	if err != nil {
 		return err
 	}
@@ -286,118 +172,26 @@ func writeInode(fileManager *System.EXT2FileManager, inodeNumber int32, inodo *M
 		return err
 	}
 
-	_, err = file.Write(buffer.Bytes())
-	return err
-}
-
-// findFreeBlock busca el primer bloque libre
-func findFreeBlock(fileManager *System.EXT2FileManager) (int32, error) {
-	manager := fileManager.GetManager()
-	diskPath := manager.GetDiskPath()
-	partitionInfo := manager.GetPartitionInfo()
-	superBloque := manager.GetSuperBlock()
-
-	file, err := os.Open(diskPath)
-	if err != nil {
-		return -1, err
-	}
-	defer file.Close()
-
-	bitmapPos := partitionInfo.PartStart + int64(superBloque.S_bm_block_start)
-	_, err = file.Seek(bitmapPos, 0)
-	if err != nil {
-		return -1, err
-	}
-
-	bitmapSize := int(superBloque.S_blocks_count/8 + 1)
-	bitmap := make([]byte, bitmapSize)
-	_, err = file.Read(bitmap)
-	if err != nil {
-		return -1, err
-	}
-
-	freeIndex := Models.FindFreeBitmapBit(bitmap)
-	if freeIndex == -1 {
-		return -1, errors.New("no hay bloques libres")
+	if _, err = file.Write(buffer.Bytes()); err != nil {
+		return err
 	}
-
-	return int32(freeIndex), nil
+	return System.MarkPartitionModified(diskPath, partitionInfo.PartStart)
 }
 
-// findFreeInode busca el primer inodo libre
-func findFreeInode(fileManager *System.EXT2FileManager) (int32, error) {
-	manager := fileManager.GetManager()
-	diskPath := manager.GetDiskPath()
-	partitionInfo := manager.GetPartitionInfo()
-	superBloque := manager.GetSuperBlock()
-
-	file, err := os.Open(diskPath)
-	if err != nil {
-		return -1, err
-	}
-	defer file.Close()
-
-	bitmapPos := partitionInfo.PartStart + int64(superBloque.S_bm_inode_start)
-	_, err = file.Seek(bitmapPos, 0)
-	if err != nil {
-		return -1, err
-	}
-
-	bitmapSize := int(superBloque.S_inodes_count/8 + 1)
-	bitmap := make([]byte, bitmapSize)
-	_, err = file.Read(bitmap)
-	if err != nil {
-		return -1, err
-	}
-
-	freeIndex := Models.FindFreeBitmapBit(bitmap)
-	if freeIndex == -1 {
-		return -1, errors.New("no hay inodos libres")
-	}
-
-	return int32(freeIndex), nil
+// findFreeBlock busca un bloque libre en el grupo del inodo que lo usará
+func findFreeBlock(fileManager *System.EXT2FileManager, ownerInode int32) (int32, error) {
+	return fileManager.FindFreeBlock(ownerInode)
 }
 
-// writeFileBlock escribe un bloque de archivo
-func writeFileBlock(fileManager *System.EXT2FileManager, blockNumber int32, content []byte) error {
-	manager := fileManager.GetManager()
-	diskPath := manager.GetDiskPath()
-	partitionInfo := manager.GetPartitionInfo()
-	superBloque := manager.GetSuperBlock()
-
-	file, err := os.OpenFile(diskPath, os.O_RDWR, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	blockPos := partitionInfo.PartStart + int64(superBloque.S_block_start) + int64(blockNumber*Models.BLOQUE_SIZE)
-	_, err = file.Seek(blockPos, 0)
-	if err != nil {
-		return err
-	}
-
-	fileBlock := Models.BloqueArchivos{}
-	fileBlock.SetContent(content)
-
-	buffer := new(bytes.Buffer)
-	err = binary.Write(buffer, binary.LittleEndian, &fileBlock)
-	if err != nil {
-		return err
-	}
-
-	_, err = file.Write(buffer.Bytes())
-	return err
+// findFreeInode busca un inodo libre en el grupo de la carpeta padre
+func findFreeInode(fileManager *System.EXT2FileManager, parentInode int32) (int32, error) {
+	return fileManager.FindFreeInode(parentInode)
 }
 
 // checkNameExistsInDirectory verifica si existe un archivo/carpeta con el nombre especificado
 func checkNameExistsInDirectory(fileManager *System.EXT2FileManager, dirInodo *Models.Inodo, name string) (bool, error) {
-	for i := 0; i < 12; i++ {
-		if dirInodo.I_block[i] == Models.FREE_BLOCK {
-			break
-		}
-
-		dirBlock, err := readDirectoryBlock(fileManager, dirInodo.I_block[i])
+	for _, block := range fileManager.DirectoryBlocks(dirInodo) {
+		dirBlock, err := readDirectoryBlock(fileManager, block)
 		if err != nil {
 			return false, err
 		}
@@ -2,8 +2,10 @@ package Root
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Logica/Users/Operations"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -170,6 +172,8 @@ func MkDir(params map[string]string) error {
 	if !hasPath {
 		return fmt.Errorf("parametro -path requerido")
 	}
+	// Resolver rutas relativas contra el directorio de trabajo
+	path = Users.ResolvePath(path)
 
 	_, hasP := params["p"]
 	createParents := hasP
@@ -183,6 +187,12 @@ func MkDir(params map[string]string) error {
 		return fmt.Errorf("ERROR: Debe iniciar sesión para usar este comando")
 	}
 
+	// Crear la carpeta exige escritura en la carpeta padre (con -p, en el ancestro
+	// existente más cercano)
+	if err := Operations.CheckParentWriteAccess(path); err != nil {
+		return err
+	}
+
 	// Obtener EXT2Manager para la sesión activa
 	mountInfo, err := Disk.GetMountInfoByID(session.MountID)
 	if err != nil {
@@ -246,6 +256,9 @@ func MkDir(params map[string]string) error {
 				if err != nil && !strings.Contains(err.Error(), "ya existe") {
 					return fmt.Errorf("error creando directorio '%s': %v", currentPath, err)
 				}
+				if err == nil {
+					Events.EmitFSChange(session.MountID, Events.ActionCreated, currentPath)
+				}
 
 				// Si es EXT3, registrar en el journal
 				if superBloque.S_filesystem_type == 3 {
@@ -266,6 +279,7 @@ func MkDir(params map[string]string) error {
 				ext3Manager.LogOperation("mkdir", path, "")
 			}
 		}
+		Events.EmitFSChange(session.MountID, Events.ActionCreated, path)
 	}
 
 	return nil
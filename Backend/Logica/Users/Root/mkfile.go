@@ -2,8 +2,11 @@ package Root
 
 import (
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Logica/Users/Operations"
 	"MIA_2S2025_P1_202105668/Models"
 	"errors"
 	"fmt"
@@ -177,6 +180,8 @@ func MkFile(params map[string]string) error {
 	if !hasPath {
 		return fmt.Errorf("parametro -path requerido")
 	}
+	// Resolver rutas relativas contra el directorio de trabajo
+	path = Users.ResolvePath(path)
 
 	rValue, hasR := params["r"]
 	recursive := hasR
@@ -208,6 +213,12 @@ func MkFile(params map[string]string) error {
 		return fmt.Errorf("ERROR: Debe iniciar sesión para usar este comando")
 	}
 
+	// Crear el archivo exige escritura en la carpeta padre (con -r, en el ancestro
+	// existente más cercano)
+	if err := Operations.CheckParentWriteAccess(path); err != nil {
+		return err
+	}
+
 	// Obtener EXT2Manager para la sesión activa
 	mountInfo, err := Disk.GetMountInfoByID(session.MountID)
 	if err != nil {
@@ -257,9 +268,10 @@ func MkFile(params map[string]string) error {
 
 	// Verificar si el archivo ya existe
 	_, err = fileManager.ReadFileContent(path)
-	if err == nil {
+	fileExisted := err == nil
+	if fileExisted {
 		// El archivo existe, preguntar si sobreescribir
-		fmt.Printf("El archivo '%s' ya existe. ¿Desea sobreescribirlo? (Esta implementación procederá automáticamente)\n", path)
+		Output.Printf("El archivo '%s' ya existe. ¿Desea sobreescribirlo? (Esta implementación procederá automáticamente)\n", path)
 	}
 
 	// Crear directorios padre si es necesario y se especifica -r
@@ -283,6 +295,9 @@ func MkFile(params map[string]string) error {
 				if err != nil && !strings.Contains(err.Error(), "ya existe") {
 					return fmt.Errorf("error creando directorio padre '%s': %v", currentPath, err)
 				}
+				if err == nil {
+					Events.EmitFSChange(session.MountID, Events.ActionCreated, currentPath)
+				}
 			}
 		}
 	}
@@ -306,6 +321,12 @@ func MkFile(params map[string]string) error {
 		}
 	}
 
-	fmt.Printf("Archivo '%s' creado exitosamente (tamaño: %d bytes)\n", path, len(fileContent))
+	if fileExisted {
+		Events.EmitFSChange(session.MountID, Events.ActionModified, path)
+	} else {
+		Events.EmitFSChange(session.MountID, Events.ActionCreated, path)
+	}
+
+	Output.Printf("Archivo '%s' creado exitosamente (tamaño: %d bytes)\n", path, len(fileContent))
 	return nil
 }
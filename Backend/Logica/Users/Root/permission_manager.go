@@ -40,8 +40,8 @@ func (pm *PermissionManager) GetUserCategory(fileOwnerID, fileGroupID int) UserC
 		return USER_OWNER
 	}
 
-	// Si pertenece al mismo grupo del archivo
-	if session.GroupID == fileGroupID {
+	// Si pertenece al grupo del archivo (principal o secundario)
+	if session.InGroup(fileGroupID) {
 		return GROUP_MEMBER
 	}
 
@@ -1,12 +1,14 @@
 package Users
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"os"
+	"sync"
 )
 
 // Session representa la sesion activa actual
@@ -15,11 +17,29 @@ type Session struct {
 	Username string
 	UserID   int
 	GroupID  int
+	GroupIDs []int // Grupo principal y grupos secundarios
 	MountID  string
+	Cwd      string // Directorio de trabajo actual (cd/pwd)
 }
 
-// LoginManager maneja las operaciones de login y logout
+// InGroup indica si alguno de los grupos de la sesion coincide con el grupo indicado
+func (s *Session) InGroup(groupID int) bool {
+	if s.GroupID == groupID {
+		return true
+	}
+	for _, id := range s.GroupIDs {
+		if id == groupID {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginManager maneja las operaciones de login y logout.
+// La sesión no se modifica una vez creada: login, logout y cd la reemplazan completa,
+// así las peticiones HTTP concurrentes siempre leen una sesión consistente.
 type LoginManager struct {
+	mu             sync.RWMutex
 	currentSession *Session
 }
 
@@ -34,14 +54,32 @@ func NewLoginManager() *LoginManager {
 
 // IsLoggedIn verifica si hay una sesion activa
 func (lm *LoginManager) IsLoggedIn() bool {
-	return lm.currentSession.IsActive
+	return lm.GetCurrentSession().IsActive
 }
 
 // GetCurrentSession obtiene la sesion actual
 func (lm *LoginManager) GetCurrentSession() *Session {
+	lm.mu.RLock()
+	defer lm.mu.RUnlock()
 	return lm.currentSession
 }
 
+// setSession reemplaza la sesión actual
+func (lm *LoginManager) setSession(session *Session) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	lm.currentSession = session
+}
+
+// setCwd reemplaza la sesión actual por una copia con otro directorio de trabajo
+func (lm *LoginManager) setCwd(cwd string) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+	session := *lm.currentSession
+	session.Cwd = cwd
+	lm.currentSession = &session
+}
+
 // ValidateMountID verifica si el ID de particion montada existe
 func (lm *LoginManager) ValidateMountID(mountID string) bool {
 	// Esta funcion deberia verificar contra la lista de particiones montadas
@@ -73,20 +111,22 @@ func (lm *LoginManager) Login(username, password, mountID string, userManager *U
 	}
 
 	// Validar contraseña (case sensitive)
-	if user.Password != password {
+	if !user.CheckPassword(password) {
 		return errors.New("ERROR: Contraseña incorrecta")
 	}
 
 	// Obtener grupo del usuario
 	group := userManager.FindGroupByName(records, user.Group)
 
-	lm.currentSession = &Session{
+	lm.setSession(&Session{
 		IsActive: true,
 		Username: username,
 		UserID:   user.ID,
 		GroupID:  group.ID,
+		GroupIDs: userManager.GetUserGroupIDs(records, user),
 		MountID:  mountID,
-	}
+		Cwd:      "/",
+	})
 
 	return nil
 }
@@ -98,9 +138,9 @@ func (lm *LoginManager) Logout() error {
 		return errors.New("ERROR: No hay ninguna sesión activa")
 	}
 
-	lm.currentSession = &Session{
+	lm.setSession(&Session{
 		IsActive: false,
-	}
+	})
 
 	return nil
 }
@@ -171,7 +211,7 @@ func Login(params map[string]string) error {
 	}
 
 	// Validar contraseña (case sensitive)
-	if user.Password != password {
+	if !user.CheckPassword(password) {
 		return errors.New("ERROR: Contraseña incorrecta")
 	}
 
@@ -182,30 +222,37 @@ func Login(params map[string]string) error {
 	}
 
 	// Crear sesión exitosa
-	loginManager.currentSession = &Session{
+	loginManager.setSession(&Session{
 		IsActive: true,
 		Username: username,
 		UserID:   user.ID,
 		GroupID:  group.ID,
+		GroupIDs: userManager.GetUserGroupIDs(records, user),
 		MountID:  mountID,
-	}
+		Cwd:      "/",
+	})
 
-	fmt.Printf("Login %s: id=%s\n", username, mountID)
+	Output.Printf("Login %s: id=%s\n", username, mountID)
 	return nil
 }
 
 // GetPartitionAndSuperBlock obtiene información de partición y SuperBloque (función exportada)
 func GetPartitionAndSuperBlock(mountInfo *Disk.MountInfo) (*Models.Partition, *Models.SuperBloque, error) {
-	file, err := os.Open(mountInfo.DiskPath)
+	file, err := Device.Open(mountInfo.DiskPath)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer file.Close()
 
+	return ReadPartitionAndSuperBlock(file, mountInfo)
+}
+
+// ReadPartitionAndSuperBlock es GetPartitionAndSuperBlock sobre un disco ya abierto
+func ReadPartitionAndSuperBlock(file *Device.Handle, mountInfo *Disk.MountInfo) (*Models.Partition, *Models.SuperBloque, error) {
 	// Leer MBR
 	var mbr Models.MBR
 	file.Seek(0, 0)
-	err = binary.Read(file, binary.LittleEndian, &mbr)
+	err := binary.Read(file, binary.LittleEndian, &mbr)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1,11 +1,11 @@
 package Users
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Models"
-	"bytes"
 	"encoding/binary"
 	"fmt"
-	"os"
 	"strings"
 )
 
@@ -25,15 +25,27 @@ func NewUserManager(diskPath string, partitionInfo *Models.Partition, superBloqu
 	}
 }
 
+// usersFileInode es el inodo de users.txt, creado por mkfs después de la raíz
+const usersFileInode = 1
+
+// fileManager retorna un gestor de archivos sobre la partición del UserManager
+func (um *UserManager) fileManager() *System.EXT2FileManager {
+	manager := &System.EXT2Manager{}
+	manager.SetDiskPath(um.diskPath)
+	manager.SetPartitionInfo(um.partitionInfo)
+	manager.SetSuperBlock(um.superBloque)
+	return System.NewEXT2FileManager(manager)
+}
+
 // ReadUsersFile lee y parsea el archivo users.txt del sistema
 func (um *UserManager) ReadUsersFile() ([]*Models.UserRecord, error) {
-	file, err := os.Open(um.diskPath)
+	file, err := Device.Open(um.diskPath)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	inodoPos := um.partitionInfo.PartStart + int64(um.superBloque.S_inode_start) + int64(1*Models.INODO_SIZE)
+	inodoPos := um.partitionInfo.PartStart + int64(um.superBloque.S_inode_start) + int64(usersFileInode*Models.INODO_SIZE)
 	_, err = file.Seek(inodoPos, 0)
 	if err != nil {
 		return nil, err
@@ -45,54 +57,12 @@ func (um *UserManager) ReadUsersFile() ([]*Models.UserRecord, error) {
 		return nil, err
 	}
 
-	blockPos := um.partitionInfo.PartStart + int64(um.superBloque.S_block_start) + int64(usersInodo.I_block[0]*int32(Models.BLOQUE_SIZE))
-	_, err = file.Seek(blockPos, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	var contentBlock Models.BloqueArchivos
-	err = binary.Read(file, binary.LittleEndian, &contentBlock)
+	// Leer contenido desde los bloques directos e indirectos de users.txt
+	content, err := um.fileManager().ReadInodeContent(&usersInodo)
 	if err != nil {
 		return nil, err
 	}
-
-	// Leer contenido usando múltiples bloques si es necesario
-	var allContent []byte
-	bytesRead := int32(0)
-
-	// Leer desde todos los bloques asignados al archivo users.txt
-	for i := 0; i < 12 && usersInodo.I_block[i] != -1; i++ {
-		if bytesRead >= usersInodo.I_s {
-			break
-		}
-
-		// Posicionarse en el bloque
-		blockPos := um.partitionInfo.PartStart + int64(um.superBloque.S_block_start) + int64(usersInodo.I_block[i]*int32(Models.BLOQUE_SIZE))
-		_, err = file.Seek(blockPos, 0)
-		if err != nil {
-			return nil, err
-		}
-
-		var blockData Models.BloqueArchivos
-		err = binary.Read(file, binary.LittleEndian, &blockData)
-		if err != nil {
-			return nil, err
-		}
-
-		// Calcular cuántos bytes tomar de este bloque
-		bytesRemaining := usersInodo.I_s - bytesRead
-		bytesToTake := int32(Models.BLOQUE_SIZE)
-		if bytesToTake > bytesRemaining {
-			bytesToTake = bytesRemaining
-		}
-
-		allContent = append(allContent, blockData.GetContent()[:bytesToTake]...)
-		bytesRead += bytesToTake
-	}
-
-	content := string(allContent)
-	return um.parseUsersContent(content)
+	return um.parseUsersContent(string(content))
 }
 
 // parseUsersContent convierte el contenido de users.txt en registros
@@ -119,142 +89,18 @@ func (um *UserManager) parseUsersContent(content string) ([]*Models.UserRecord,
 func (um *UserManager) WriteUsersFile(records []*Models.UserRecord) error {
 	var content strings.Builder
 	for _, record := range records {
-		content.WriteString(record.ToString())
-		content.WriteString("\n")
-	}
-
-	contentStr := content.String()
-
-	// El archivo users.txt ahora puede usar múltiples bloques, sin límite de 64 bytes
-
-	file, err := os.OpenFile(um.diskPath, os.O_RDWR, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	inodoPos := um.partitionInfo.PartStart + int64(um.superBloque.S_inode_start) + int64(1*Models.INODO_SIZE)
-	_, err = file.Seek(inodoPos, 0)
-	if err != nil {
-		return err
-	}
-
-	var usersInodo Models.Inodo
-	err = binary.Read(file, binary.LittleEndian, &usersInodo)
-	if err != nil {
-		return err
-	}
-
-	// Escribir contenido usando múltiples bloques
-	err = um.writeUsersContentMultiBlock(file, &usersInodo, contentStr)
-	if err != nil {
-		return err
-	}
-
-	// Actualizar metadatos del inodo
-	usersInodo.I_s = int32(len(contentStr))
-	usersInodo.I_mtime = float64(Models.GetCurrentUnixTime())
-
-	_, err = file.Seek(inodoPos, 0)
-	if err != nil {
-		return err
-	}
-
-	buffer := new(bytes.Buffer)
-	err = binary.Write(buffer, binary.LittleEndian, &usersInodo)
-	if err != nil {
-		return err
-	}
-	_, err = file.Write(buffer.Bytes())
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// writeUsersContentMultiBlock escribe contenido del archivo users.txt usando múltiples bloques
-func (um *UserManager) writeUsersContentMultiBlock(file *os.File, usersInodo *Models.Inodo, content string) error {
-	contentBytes := []byte(content)
-	totalBytes := len(contentBytes)
-	blocksNeeded := (totalBytes + Models.BLOQUE_SIZE - 1) / Models.BLOQUE_SIZE
-
-	// Limitar a 12 bloques directos
-	if blocksNeeded > 12 {
-		blocksNeeded = 12
-		contentBytes = contentBytes[:12*Models.BLOQUE_SIZE]
-	}
-
-	// Liberar bloques existentes (excepto el primero si ya existe)
-	for i := 1; i < 12; i++ {
-		if usersInodo.I_block[i] != -1 {
-			// Aquí deberíamos liberar el bloque en el bitmap, pero por simplicidad lo omitimos
-			usersInodo.I_block[i] = -1
-		}
-	}
-
-	// Escribir contenido en bloques
-	for i := 0; i < blocksNeeded; i++ {
-		// Calcular qué porción del contenido va en este bloque
-		start := i * Models.BLOQUE_SIZE
-		end := start + Models.BLOQUE_SIZE
-		if end > len(contentBytes) {
-			end = len(contentBytes)
-		}
-
-		blockContent := contentBytes[start:end]
-
-		// Si es el primer bloque, usar el bloque existente
-		var blockNum int32
-		if i == 0 && usersInodo.I_block[0] != -1 {
-			blockNum = usersInodo.I_block[0]
-		} else {
-			// Para bloques adicionales, usar bloques consecutivos seguros
-			// Asegurándonos de no sobrescribir otros archivos
-			if usersInodo.I_block[0] != -1 {
-				blockNum = usersInodo.I_block[0] + int32(i)
-			} else {
-				// Si no hay primer bloque, usar bloque alto como base
-				blockNum = 100 + int32(i)
+		// Migrar contraseñas en texto plano al formato con hash
+		if record.Type == "U" && !Models.IsPasswordHashed(record.Password) {
+			if err := record.SetPassword(record.Password); err != nil {
+				return err
 			}
-			usersInodo.I_block[i] = blockNum
-		}
-
-		// Limpiar el bloque antes de escribir para evitar basura
-		blockPos := um.partitionInfo.PartStart + int64(um.superBloque.S_block_start) + int64(blockNum*int32(Models.BLOQUE_SIZE))
-		_, err := file.Seek(blockPos, 0)
-		if err != nil {
-			return err
-		}
-
-		// Limpiar el bloque completo con ceros
-		emptyBlock := make([]byte, Models.BLOQUE_SIZE)
-		_, err = file.Write(emptyBlock)
-		if err != nil {
-			return err
-		}
-
-		// Reposicionarse para escribir el contenido
-		_, err = file.Seek(blockPos, 0)
-		if err != nil {
-			return err
-		}
-
-		contentBlock := Models.BloqueArchivos{}
-		contentBlock.SetContent(blockContent)
-
-		buffer := new(bytes.Buffer)
-		err = binary.Write(buffer, binary.LittleEndian, &contentBlock)
-		if err != nil {
-			return err
-		}
-		_, err = file.Write(buffer.Bytes())
-		if err != nil {
-			return err
 		}
+		content.WriteString(record.ToString())
+		content.WriteString("\n")
 	}
 
-	return nil
+	// Los bloques se reservan en el bitmap; pasados los 12 directos se usan los indirectos
+	return um.fileManager().OverwriteFileContent(usersFileInode, content.String())
 }
 
 // GetNextUserID obtiene el siguiente ID disponible para usuarios
@@ -299,10 +145,100 @@ func (um *UserManager) FindGroupByName(records []*Models.UserRecord, groupname s
 	return nil
 }
 
+// GetUserGroupIDs obtiene los IDs del grupo principal y de los grupos secundarios existentes del usuario
+func (um *UserManager) GetUserGroupIDs(records []*Models.UserRecord, user *Models.UserRecord) []int {
+	groupIDs := []int{}
+	for _, groupname := range append([]string{user.Group}, user.Groups...) {
+		group := um.FindGroupByName(records, groupname)
+		if group == nil {
+			continue
+		}
+		if !containsID(groupIDs, group.ID) {
+			groupIDs = append(groupIDs, group.ID)
+		}
+	}
+	return groupIDs
+}
+
+// AddUserToGroup agrega un grupo secundario al usuario
+func (um *UserManager) AddUserToGroup(username, groupname string) error {
+	records, err := um.ReadUsersFile()
+	if err != nil {
+		return err
+	}
+
+	user := um.FindUserByName(records, username)
+	if user == nil {
+		return fmt.Errorf("ERROR: El usuario '%s' no existe", username)
+	}
+	if um.FindGroupByName(records, groupname) == nil {
+		return fmt.Errorf("ERROR: El grupo '%s' no existe", groupname)
+	}
+	if user.Group == groupname {
+		return fmt.Errorf("ERROR: '%s' ya es el grupo principal de '%s'", groupname, username)
+	}
+	if user.HasGroup(groupname) {
+		return fmt.Errorf("ERROR: El usuario '%s' ya pertenece al grupo '%s'", username, groupname)
+	}
+
+	user.Groups = append(user.Groups, groupname)
+	return um.WriteUsersFile(records)
+}
+
+// RemoveUserFromGroup quita un grupo secundario del usuario
+func (um *UserManager) RemoveUserFromGroup(username, groupname string) error {
+	records, err := um.ReadUsersFile()
+	if err != nil {
+		return err
+	}
+
+	user := um.FindUserByName(records, username)
+	if user == nil {
+		return fmt.Errorf("ERROR: El usuario '%s' no existe", username)
+	}
+	if user.Group == groupname {
+		return fmt.Errorf("ERROR: No se puede quitar el grupo principal de '%s', use chgrp", username)
+	}
+	if !user.HasGroup(groupname) {
+		return fmt.Errorf("ERROR: El usuario '%s' no pertenece al grupo '%s'", username, groupname)
+	}
+
+	user.RemoveGroup(groupname)
+	return um.WriteUsersFile(records)
+}
+
+// containsID indica si el ID está en la lista
+func containsID(ids []int, id int) bool {
+	for _, current := range ids {
+		if current == id {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateUserCredentials valida credenciales de usuario
 func (um *UserManager) ValidateUserCredentials(records []*Models.UserRecord, username, password string) bool {
 	user := um.FindUserByName(records, username)
-	return user != nil && user.Password == password
+	return user != nil && user.CheckPassword(password)
+}
+
+// ChangePassword reemplaza la contraseña de un usuario guardándola como hash
+func (um *UserManager) ChangePassword(username, newPassword string) error {
+	records, err := um.ReadUsersFile()
+	if err != nil {
+		return err
+	}
+
+	user := um.FindUserByName(records, username)
+	if user == nil {
+		return fmt.Errorf("ERROR: El usuario '%s' no existe", username)
+	}
+
+	if err := user.SetPassword(newPassword); err != nil {
+		return err
+	}
+	return um.WriteUsersFile(records)
 }
 
 // CreateUser crea un nuevo usuario en el sistema
@@ -361,6 +297,76 @@ func (um *UserManager) CreateGroup(groupname string) error {
 	return um.WriteUsersFile(records)
 }
 
+// RenameUser cambia el nombre de un usuario conservando su ID
+func (um *UserManager) RenameUser(username, newName string) error {
+	records, err := um.ReadUsersFile()
+	if err != nil {
+		return err
+	}
+
+	user := um.FindUserByName(records, username)
+	if user == nil {
+		return fmt.Errorf("ERROR: El usuario '%s' no existe", username)
+	}
+	if um.FindUserByName(records, newName) != nil {
+		return fmt.Errorf("ERROR: El usuario '%s' ya existe", newName)
+	}
+
+	user.Username = newName
+	return um.WriteUsersFile(records)
+}
+
+// RenameGroup cambia el nombre de un grupo conservando su ID y actualiza a sus miembros
+func (um *UserManager) RenameGroup(groupname, newName string) error {
+	records, err := um.ReadUsersFile()
+	if err != nil {
+		return err
+	}
+
+	group := um.FindGroupByName(records, groupname)
+	if group == nil {
+		return fmt.Errorf("ERROR: El grupo '%s' no existe", groupname)
+	}
+	if um.FindGroupByName(records, newName) != nil {
+		return fmt.Errorf("ERROR: El grupo '%s' ya existe", newName)
+	}
+
+	group.Group = newName
+
+	// Los usuarios referencian a los grupos por nombre
+	for _, record := range records {
+		if record.Type != "U" || record.ID == 0 {
+			continue
+		}
+		if record.Group == groupname {
+			record.Group = newName
+		}
+		for i, secondary := range record.Groups {
+			if secondary == groupname {
+				record.Groups[i] = newName
+			}
+		}
+	}
+
+	return um.WriteUsersFile(records)
+}
+
+// GetGroupMembers obtiene los usuarios activos que pertenecen al grupo, como principal o secundario
+func (um *UserManager) GetGroupMembers(records []*Models.UserRecord, groupname string) []string {
+	members := []string{}
+	for _, record := range records {
+		if record.Type == "U" && record.ID != 0 && record.HasGroup(groupname) {
+			members = append(members, record.Username)
+		}
+	}
+	return members
+}
+
+// HomeDirectory retorna la carpeta personal de un usuario dentro de la partición
+func HomeDirectory(username string) string {
+	return "/home/" + username
+}
+
 // DeleteUser marca un usuario como eliminado (ID = 0)
 func (um *UserManager) DeleteUser(username string) error {
 	records, err := um.ReadUsersFile()
@@ -385,7 +391,17 @@ func (um *UserManager) DeleteGroup(groupname string) error {
 	}
 
 	group := um.FindGroupByName(records, groupname)
+	if group == nil {
+		return fmt.Errorf("ERROR: El grupo '%s' no existe", groupname)
+	}
 
 	group.ID = 0
+
+	// Quitar el grupo eliminado de los grupos secundarios de los usuarios
+	for _, record := range records {
+		if record.Type == "U" {
+			record.RemoveGroup(groupname)
+		}
+	}
 	return um.WriteUsersFile(records)
 }
@@ -0,0 +1,85 @@
+package Users
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Output"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ResolvePath convierte una ruta relativa en absoluta usando el directorio de trabajo
+// de la sesión activa (o "/" si no hay sesión). Los componentes "." y ".." se resuelven.
+func ResolvePath(p string) string {
+	if p == "" {
+		return p
+	}
+	if strings.HasPrefix(p, "/") {
+		return path.Clean(p)
+	}
+	return path.Join(CurrentDirectory(), p)
+}
+
+// CurrentDirectory retorna el directorio de trabajo de la sesión activa
+func CurrentDirectory() string {
+	session := GetCurrentSession()
+	if session == nil || !session.IsActive || session.Cwd == "" {
+		return "/"
+	}
+	return session.Cwd
+}
+
+// Cd - Función exportada para comando cd
+func Cd(params map[string]string) error {
+	if err := loginManager.RequireSession(); err != nil {
+		return err
+	}
+	session := GetCurrentSession()
+
+	// Sin -path se regresa a la raíz
+	target, hasPath := params["path"]
+	if !hasPath || target == "" {
+		target = "/"
+	}
+
+	mountInfo, err := Disk.GetMountInfoByID(session.MountID)
+	if err != nil {
+		return fmt.Errorf("ERROR: partición no encontrada: %v", err)
+	}
+	partitionInfo, superBloque, err := GetPartitionAndSuperBlock(mountInfo)
+	if err != nil {
+		return fmt.Errorf("ERROR: error accediendo al sistema de archivos: %v", err)
+	}
+
+	manager := &System.EXT2Manager{}
+	manager.SetPartitionInfo(partitionInfo)
+	manager.SetSuperBlock(superBloque)
+	manager.SetDiskPath(mountInfo.DiskPath)
+
+	newDir, err := System.NewEXT2DirectoryManager(manager).ChangeDirectory(CurrentDirectory(), target)
+	if err != nil {
+		return fmt.Errorf("ERROR: no se puede cambiar a '%s': %v", target, err)
+	}
+
+	loginManager.setCwd(newDir)
+	return nil
+}
+
+// Pwd - Función exportada para comando pwd
+func Pwd() error {
+	if err := loginManager.RequireSession(); err != nil {
+		return err
+	}
+	Output.Println(CurrentDirectory())
+	return nil
+}
+
+// Prompt retorna el prompt de la consola: usuario@id:cwd si hay sesión activa
+func Prompt() string {
+	session := GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return "MIA> "
+	}
+	return fmt.Sprintf("%s@%s:%s> ", session.Username, session.MountID, CurrentDirectory())
+}
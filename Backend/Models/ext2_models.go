@@ -1,6 +1,7 @@
 package Models
 
 import (
+	"hash/fnv"
 	"time"
 	"unsafe"
 )
@@ -25,6 +26,17 @@ type SuperBloque struct {
 	S_inode_start       int32   // Posicion de la tabla de inodos
 	S_block_start       int32   // Posicion del area de bloques
 	S_journal_start     int32   // Posicion del journal (solo EXT3)
+	S_groups_count      int32   // Grupos de bloques (0 o 1 = sin grupos)
+	S_dir_index         int32   // 1 = las carpetas nuevas llevan índice de hash
+	S_state             int32   // SB_STATE_CLEAN tras unmount, SB_STATE_DIRTY desde la primera escritura
+}
+
+// GetStateName retorna el estado de montaje como texto
+func (s *SuperBloque) GetStateName() string {
+	if s.S_state == SB_STATE_DIRTY {
+		return "sucio"
+	}
+	return "limpio"
 }
 
 // Inodo representa un archivo o directorio con metadatos y punteros a bloques
@@ -71,6 +83,13 @@ type BloqueApuntadores struct {
 	B_pointers [16]int32
 }
 
+// BloqueIndice es el índice de hash de una carpeta (apuntado por I_block[DIR_INDEX_BLOCK]).
+// Cada cubeta es un mapa de bits de los bloques de la carpeta (directos e indirectos) que
+// contienen algún nombre cuyo hash cae en esa cubeta; ver DirIndexBit.
+type BloqueIndice struct {
+	B_buckets [DIR_INDEX_BUCKETS]int32
+}
+
 const (
 	SUPERBLOQUE_SIZE = 1024
 	INODO_SIZE       = 128
@@ -82,12 +101,36 @@ const (
 
 	EXT2_MAGIC = 0xEF53
 
+	SB_STATE_CLEAN = 0 // Desmontada correctamente (o formateada antes de existir el campo)
+	SB_STATE_DIRTY = 1 // Montada con escrituras pendientes de un unmount
+
 	ROOT_INODE = 0
 
 	FREE_BLOCK = -1
 	FREE_INODE = -1
+
+	DIR_INDEX_BLOCK   = 14 // Posición de I_block con el índice de una carpeta
+	DIR_INDEX_BUCKETS = 16
+	DIR_INDEX_BITS    = 32 // Bits de cada cubeta
 )
 
+// DirIndexBit retorna el bit de las cubetas que representa el bloque en la posición slot
+// de la carpeta. Las posiciones se reparten módulo DIR_INDEX_BITS, así que un bit puede
+// cubrir varios bloques y buscar en él cuesta leerlos todos.
+func DirIndexBit(slot int) int32 {
+	return int32(1) << (slot % DIR_INDEX_BITS)
+}
+
+// DirIndexBucket retorna la cubeta del índice de carpeta para un nombre.
+// Se usa el nombre tal como se guarda en B_name (máximo 12 bytes).
+func DirIndexBucket(name string) int {
+	var stored [12]byte
+	length := copy(stored[:], name)
+	hash := fnv.New32a()
+	hash.Write(stored[:length])
+	return int(hash.Sum32() % DIR_INDEX_BUCKETS)
+}
+
 func GetSuperBloqueSize() int {
 	return int(unsafe.Sizeof(SuperBloque{}))
 }
@@ -120,7 +163,7 @@ func NewSuperBloque(inodesCount, blocksCount int32) SuperBloque {
 		S_magic:             EXT2_MAGIC,
 		S_inode_s:           INODO_SIZE,
 		S_block_s:           BLOQUE_SIZE,
-		S_firts_ino:         1,
+		S_firts_ino:         2, // 0 = raiz, 1 = users.txt
 		S_first_blo:         1,
 		S_bm_inode_start:    SUPERBLOQUE_SIZE,
 		S_bm_block_start:    SUPERBLOQUE_SIZE + inodeBitmapSize,
@@ -218,18 +261,54 @@ func IsBitmapBitSet(bitmap []byte, position int) bool {
 
 func FindFreeBitmapBit(bitmap []byte) int {
 	// Buscar el primer bit libre en el bitmap
-	for byteIndex, b := range bitmap {
-		if b != 0xFF { // Si no todos los bits estan ocupados
-			for bitIndex := 0; bitIndex < 8; bitIndex++ {
-				if (b & (1 << (7 - bitIndex))) == 0 {
-					return byteIndex*8 + bitIndex
-				}
-			}
+	return FindFreeBitmapBitFrom(bitmap, 0, len(bitmap)*8)
+}
+
+// FindFreeBitmapBitFrom busca el primer bit libre en [start, limit)
+func FindFreeBitmapBitFrom(bitmap []byte, start int, limit int) int {
+	if start < 0 {
+		start = 0
+	}
+	if limit > len(bitmap)*8 {
+		limit = len(bitmap) * 8
+	}
+
+	for position := start; position < limit; {
+		// Saltar bytes completos ocupados
+		if position%8 == 0 && bitmap[position/8] == 0xFF {
+			position += 8
+			continue
+		}
+		if !IsBitmapBitSet(bitmap, position) {
+			return position
 		}
+		position++
 	}
 	return -1 // No hay bits libres
 }
 
+// FindFreeBitmapRun busca count bits libres consecutivos en [start, limit) y retorna el primero
+func FindFreeBitmapRun(bitmap []byte, start int, limit int, count int) int {
+	runStart, runLength := -1, 0
+	for position := FindFreeBitmapBitFrom(bitmap, start, limit); position != -1 && position < limit; position++ {
+		if IsBitmapBitSet(bitmap, position) {
+			runLength = 0
+			position = FindFreeBitmapBitFrom(bitmap, position, limit)
+			if position == -1 {
+				break
+			}
+		}
+		if runLength == 0 {
+			runStart = position
+		}
+		runLength++
+		if runLength == count {
+			return runStart
+		}
+	}
+	return -1
+}
+
 func GetCurrentUnixTime() int64 {
 	return time.Now().Unix()
 }
@@ -327,3 +406,26 @@ func (i *Information) GetContent() string {
 	}
 	return string(i.I_content[:n])
 }
+
+// IntentHeader encabeza cada transacción del registro de intenciones, que ocupa el
+// espacio del journal que queda después de Journal
+type IntentHeader struct {
+	T_magic    int32  // INTENT_MAGIC si la cabecera es válida
+	T_state    int32  // INTENT_BEGIN mientras se escriben las imágenes, INTENT_COMMIT al confirmar
+	T_sequence int64  // Número de la transacción, cada una sigue a la anterior
+	T_count    int32  // Cantidad de imágenes
+	T_length   int32  // Bytes de las imágenes (con su IntentImage) que siguen a la cabecera
+	T_checksum uint32 // CRC32 de las imágenes
+}
+
+// IntentImage precede al contenido de cada rango modificado por la transacción
+type IntentImage struct {
+	T_offset int64 // Posición relativa al inicio de la partición
+	T_length int32 // Bytes de contenido que siguen
+}
+
+const (
+	INTENT_MAGIC  = 0x494E5431 // "INT1"
+	INTENT_BEGIN  = 1
+	INTENT_COMMIT = 2
+)
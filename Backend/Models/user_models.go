@@ -1,23 +1,54 @@
 package Models
 
 import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"fmt"
 	"strconv"
 	"strings"
 )
 
+// PasswordHashAlgorithm es el marcador de algoritmo de las contraseñas en users.txt.
+// Formato almacenado: pbkdf2$<sal>$<clave>, con sal y clave en base64 sin relleno. La
+// clave es PBKDF2-HMAC-SHA256 con passwordIterations iteraciones: el costo hace lento
+// probar contraseñas si alguien lee users.txt desde el disco, y el formato ocupa 38
+// caracteres para que users.txt crezca poco por usuario.
+const PasswordHashAlgorithm = "pbkdf2"
+
+// legacyPasswordHashAlgorithm es el formato anterior (sha256$<sal>$<hash>); esas
+// contraseñas se siguen aceptando al iniciar sesión
+const legacyPasswordHashAlgorithm = "sha256"
+
+const (
+	passwordSaltSize   = 6      // bytes de sal aleatoria (8 caracteres en base64)
+	passwordKeySize    = 16     // bytes de clave derivada (22 caracteres en base64)
+	passwordIterations = 100000 // iteraciones de PBKDF2 (unos 25 ms por contraseña)
+)
+
 // UserRecord representa un registro de usuario o grupo en users.txt
 type UserRecord struct {
 	ID       int
 	Type     string // "U" para Usuario, "G" para Grupo
 	Group    string
-	Username string // Solo para usuarios
-	Password string // Solo para usuarios
+	Username string   // Solo para usuarios
+	Password string   // Solo para usuarios
+	Groups   []string // Grupos secundarios, solo para usuarios
 }
 
+// SupplementaryGroupSeparator separa los grupos secundarios en el sexto campo de un usuario.
+// Formato: ID, U, grupo, usuario, contraseña[, grupo2;grupo3]
+const SupplementaryGroupSeparator = ";"
+
 // ToString convierte el registro a formato string para users.txt
 func (u *UserRecord) ToString() string {
 	if u.Type == "U" {
+		// El campo de grupos secundarios solo se escribe si existe, así la línea no cambia para el resto
+		if len(u.Groups) > 0 {
+			return fmt.Sprintf("%d, U, %s, %s, %s, %s", u.ID, u.Group, u.Username, u.Password, strings.Join(u.Groups, SupplementaryGroupSeparator))
+		}
 		return fmt.Sprintf("%d, U, %s, %s, %s", u.ID, u.Group, u.Username, u.Password)
 	}
 	return fmt.Sprintf("%d, G, %s", u.ID, u.Group)
@@ -49,12 +80,112 @@ func ParseUserRecord(line string) (*UserRecord, error) {
 		record.Group = parts[2]
 		record.Username = parts[3]
 		record.Password = parts[4]
+
+		// Sexto campo opcional con los grupos secundarios
+		if len(parts) > 5 {
+			for _, group := range strings.Split(parts[5], SupplementaryGroupSeparator) {
+				if group = strings.TrimSpace(group); group != "" {
+					record.Groups = append(record.Groups, group)
+				}
+			}
+		}
 	}
 
 	return record, nil
 }
 
-// CreateInitialUsersContent genera el contenido inicial de users.txt
-func CreateInitialUsersContent() string {
-	return "1, G, root\n1, U, root, root, 123\n"
+// HasGroup indica si el usuario pertenece al grupo, como principal o secundario
+func (u *UserRecord) HasGroup(groupname string) bool {
+	if u.Group == groupname {
+		return true
+	}
+	for _, group := range u.Groups {
+		if group == groupname {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveGroup quita un grupo de los grupos secundarios del usuario
+func (u *UserRecord) RemoveGroup(groupname string) {
+	var groups []string
+	for _, group := range u.Groups {
+		if group != groupname {
+			groups = append(groups, group)
+		}
+	}
+	u.Groups = groups
+}
+
+// CreateInitialUsersContent genera el contenido inicial de users.txt, con la
+// contraseña de root (123) ya cifrada
+func CreateInitialUsersContent() (string, error) {
+	root := &UserRecord{ID: 1, Type: "U", Group: "root", Username: "root"}
+	if err := root.SetPassword("123"); err != nil {
+		return "", err
+	}
+	return "1, G, root\n" + root.ToString() + "\n", nil
+}
+
+// HashPassword genera el hash con sal de una contraseña en el formato de users.txt
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generando sal: %v", err)
+	}
+	encodedSalt := base64.RawStdEncoding.EncodeToString(salt)
+	key, err := deriveKey(encodedSalt, password)
+	if err != nil {
+		return "", err
+	}
+	return PasswordHashAlgorithm + "$" + encodedSalt + "$" + key, nil
+}
+
+// deriveKey calcula la clave PBKDF2 de la contraseña con la sal codificada
+func deriveKey(salt string, password string) (string, error) {
+	key, err := pbkdf2.Key(sha256.New, password, []byte(salt), passwordIterations, passwordKeySize)
+	if err != nil {
+		return "", fmt.Errorf("error derivando clave: %v", err)
+	}
+	return base64.RawStdEncoding.EncodeToString(key), nil
+}
+
+// legacyHashWithSalt calcula el hash SHA-256 de la sal concatenada con la contraseña
+func legacyHashWithSalt(salt string, password string) string {
+	sum := sha256.Sum256([]byte(salt + password))
+	return base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// IsPasswordHashed indica si la contraseña almacenada ya tiene el formato con hash
+func IsPasswordHashed(stored string) bool {
+	parts := strings.Split(stored, "$")
+	return len(parts) == 3 && (parts[0] == PasswordHashAlgorithm || parts[0] == legacyPasswordHashAlgorithm)
+}
+
+// SetPassword guarda la contraseña del usuario como hash con sal
+func (u *UserRecord) SetPassword(password string) error {
+	hashed, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	u.Password = hashed
+	return nil
+}
+
+// CheckPassword compara una contraseña con la almacenada (hash o texto plano sin migrar)
+func (u *UserRecord) CheckPassword(password string) bool {
+	if !IsPasswordHashed(u.Password) {
+		return subtle.ConstantTimeCompare([]byte(u.Password), []byte(password)) == 1
+	}
+	parts := strings.Split(u.Password, "$")
+	if parts[0] == legacyPasswordHashAlgorithm {
+		expected := legacyHashWithSalt(parts[1], password)
+		return subtle.ConstantTimeCompare([]byte(parts[2]), []byte(expected)) == 1
+	}
+	expected, err := deriveKey(parts[1], password)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(parts[2]), []byte(expected)) == 1
 }
@@ -1,6 +1,7 @@
 package Utils
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Models"
 	"encoding/binary"
 	"fmt"
@@ -18,6 +19,8 @@ func GenerateImageFromDot(dotFile string, outputPath string) error {
 	format := "jpg"
 	if strings.HasSuffix(strings.ToLower(outputPath), ".png") {
 		format = "png"
+	} else if strings.HasSuffix(strings.ToLower(outputPath), ".svg") {
+		format = "svg"
 	}
 
 	cmd := exec.Command("dot", "-T"+format, "-Gdpi=1500", "-Gmargin=0", "-Gpad=0", dotFile, "-o", outputPath)
@@ -26,9 +29,80 @@ func GenerateImageFromDot(dotFile string, outputPath string) error {
 	return nil
 }
 
+// Modos de renderizado disponibles para los reportes
+const (
+	RendererAuto = "auto" // Usa Graphviz si está instalado, si no el renderizador SVG
+	RendererDot  = "dot"  // Fuerza el uso de Graphviz
+	RendererSVG  = "svg"  // Fuerza el renderizador SVG interno
+)
+
+// defaultRendererMode es el modo de los reportes que no indican uno (variable MIA_RENDERER).
+// Se lee una sola vez: el modo de cada reporte viaja como parámetro, así dos peticiones
+// con -renderer distinto no se pisan.
+var defaultRendererMode = initialRendererMode()
+
+func initialRendererMode() string {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("MIA_RENDERER")))
+	if mode == RendererDot || mode == RendererSVG {
+		return mode
+	}
+	return RendererAuto
+}
+
+// ParseRendererMode valida el modo de renderizado de un reporte; vacío es el modo por defecto
+func ParseRendererMode(mode string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "":
+		return defaultRendererMode, nil
+	case RendererAuto, RendererDot, RendererSVG:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("renderizador '%s' no valido (auto, dot o svg)", mode)
+	}
+}
+
+// DefaultRendererMode retorna el modo de renderizado por defecto
+func DefaultRendererMode() string {
+	return defaultRendererMode
+}
+
+// IsGraphvizAvailable verifica si el ejecutable dot está en el PATH
+func IsGraphvizAvailable() bool {
+	_, err := exec.LookPath("dot")
+	return err == nil
+}
+
+// ShouldUseSVGRenderer determina si el reporte debe generarse con el renderizador SVG interno.
+// renderer vacío es el modo por defecto.
+func ShouldUseSVGRenderer(renderer string, outputPath string) bool {
+	if renderer == "" {
+		renderer = defaultRendererMode
+	}
+	switch renderer {
+	case RendererSVG:
+		return true
+	case RendererDot:
+		return false
+	}
+	if strings.EqualFold(filepath.Ext(outputPath), ".svg") {
+		return true
+	}
+	return !IsGraphvizAvailable()
+}
+
+// GetSVGOutputPath cambia la extensión de la ruta de salida a .svg
+func GetSVGOutputPath(outputPath string) string {
+	ext := filepath.Ext(outputPath)
+	if strings.EqualFold(ext, ".svg") {
+		return outputPath
+	}
+	return strings.TrimSuffix(outputPath, ext) + ".svg"
+}
+
 // ReadLogicalPartitions lee todas las particiones lógicas desde una partición extendida
 func ReadLogicalPartitions(diskPath string, extendedStart int64) ([]Models.EBR, error) {
-	file, _ := os.Open(diskPath)
+	file, _ := Device.Open(diskPath)
 	defer file.Close()
 
 	var logicalPartitions []Models.EBR
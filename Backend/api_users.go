@@ -0,0 +1,152 @@
+package main
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Logica/Users/Comandos"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// renameRequest es el cuerpo de PATCH /api/users/{usuario} y /api/groups/{grupo}
+type renameRequest struct {
+	Name string `json:"name"`
+}
+
+// registerUserRoutes registra los endpoints de usuarios y grupos
+func registerUserRoutes() {
+	http.HandleFunc("/api/users", corsMiddleware(apiUsersHandler))
+	http.HandleFunc("/api/users/", corsMiddleware(apiUsersHandler))
+	http.HandleFunc("/api/groups", corsMiddleware(apiGroupsHandler))
+	http.HandleFunc("/api/groups/", corsMiddleware(apiGroupsHandler))
+}
+
+// apiUsersHandler maneja GET /api/users (lsusr), GET /api/users/{usuario} (usrinfo)
+// y PATCH /api/users/{usuario} (renusr)
+func apiUsersHandler(w http.ResponseWriter, r *http.Request) {
+	username := apiResourceName(r, "/api/users")
+
+	switch r.Method {
+	case "GET":
+		partitionID, ok := usersPartitionID(w, r)
+		if !ok {
+			return
+		}
+		if unlock, err := Disk.LockPartition(partitionID, false); err == nil {
+			defer unlock()
+		}
+		if username == "" {
+			users, err := Comandos.ListUsers(partitionID)
+			if err != nil {
+				writeAPIFailure(w, err, "")
+				return
+			}
+			writeAPIResponse(w, http.StatusOK, fmt.Sprintf("Usuarios de la partición %s", partitionID), "", users)
+			return
+		}
+		info, err := Comandos.GetUserInfo(partitionID, username)
+		if err != nil {
+			writeAPIFailure(w, err, "")
+			return
+		}
+		writeAPIResponse(w, http.StatusOK, fmt.Sprintf("Usuario %s", username), "", info)
+	case "PATCH":
+		if username == "" {
+			writeAPIError(w, http.StatusBadRequest, "Debe indicar el usuario: /api/users/{usuario}", "")
+			return
+		}
+		var req renameRequest
+		if !decodeAPIBody(w, r, &req) {
+			return
+		}
+		output, err := captureOutput(func() error {
+			return withSessionTransaction(func() error {
+				return Comandos.RenUsr(map[string]string{"user": username, "name": req.Name})
+			})
+		})
+		if err != nil {
+			writeAPIFailure(w, err, output)
+			return
+		}
+		writeAPIResponse(w, http.StatusOK, "Usuario renombrado", output, map[string]string{"username": req.Name})
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "Método no permitido", "")
+	}
+}
+
+// apiGroupsHandler maneja GET /api/groups (lsgrp) y PATCH /api/groups/{grupo} (rengrp)
+func apiGroupsHandler(w http.ResponseWriter, r *http.Request) {
+	groupname := apiResourceName(r, "/api/groups")
+
+	switch r.Method {
+	case "GET":
+		partitionID, ok := usersPartitionID(w, r)
+		if !ok {
+			return
+		}
+		if unlock, err := Disk.LockPartition(partitionID, false); err == nil {
+			defer unlock()
+		}
+		groups, err := Comandos.ListGroups(partitionID)
+		if err != nil {
+			writeAPIFailure(w, err, "")
+			return
+		}
+		if groupname != "" {
+			for _, group := range groups {
+				if group.Name == groupname {
+					writeAPIResponse(w, http.StatusOK, fmt.Sprintf("Grupo %s", groupname), "", group)
+					return
+				}
+			}
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("ERROR: El grupo '%s' no existe", groupname), "")
+			return
+		}
+		writeAPIResponse(w, http.StatusOK, fmt.Sprintf("Grupos de la partición %s", partitionID), "", groups)
+	case "PATCH":
+		if groupname == "" {
+			writeAPIError(w, http.StatusBadRequest, "Debe indicar el grupo: /api/groups/{grupo}", "")
+			return
+		}
+		var req renameRequest
+		if !decodeAPIBody(w, r, &req) {
+			return
+		}
+		output, err := captureOutput(func() error {
+			return withSessionTransaction(func() error {
+				return Comandos.RenGrp(map[string]string{"grp": groupname, "name": req.Name})
+			})
+		})
+		if err != nil {
+			writeAPIFailure(w, err, output)
+			return
+		}
+		writeAPIResponse(w, http.StatusOK, "Grupo renombrado", output, map[string]string{"name": req.Name})
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "Método no permitido", "")
+	}
+}
+
+// apiResourceName obtiene el nombre que sigue al recurso en la ruta (ej. /api/users/{usuario})
+func apiResourceName(r *http.Request, resource string) string {
+	name := strings.Trim(strings.TrimPrefix(r.URL.EscapedPath(), resource), "/")
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name
+}
+
+// usersPartitionID toma la partición de ?id= o, si no se indica, la de la sesión activa
+func usersPartitionID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if partitionID := r.URL.Query().Get("id"); partitionID != "" {
+		return partitionID, true
+	}
+	session := Users.GetCurrentSession()
+	if session != nil && session.IsActive {
+		return session.MountID, true
+	}
+	writeAPIError(w, http.StatusBadRequest, "parametro id requerido (o iniciar sesión)", "")
+	return "", false
+}
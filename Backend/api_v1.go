@@ -0,0 +1,677 @@
+package main
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"MIA_2S2025_P1_202105668/Logica/Users/Operations"
+	"MIA_2S2025_P1_202105668/Logica/Users/Root"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// === API REST v1 ===
+// Cada endpoint traduce el cuerpo JSON a los parámetros de los comandos existentes
+// (Disk, Root y Operations) y responde con errores estructurados.
+
+const apiV1Prefix = "/api/v1"
+
+// APIError describe un error de la API con su código HTTP
+type APIError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Output  string `json:"output,omitempty"`
+}
+
+// APIResponse es la respuesta de una operación exitosa
+type APIResponse struct {
+	Message string      `json:"message"`
+	Output  string      `json:"output,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Cuerpos aceptados por los endpoints
+type diskRequest struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	Unit string `json:"unit"`
+	Fit  string `json:"fit"`
+}
+
+type partitionRequest struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Unit string `json:"unit"`
+	Fit  string `json:"fit"`
+	Type string `json:"type"`
+	Add  int64  `json:"add"`
+	Mode string `json:"mode"`
+}
+
+type mountRequest struct {
+	Path     string `json:"path"`
+	Name     string `json:"name"`
+	ReadOnly bool   `json:"readOnly"`
+	NoAtime  bool   `json:"noatime"`
+}
+
+type formatRequest struct {
+	Type     string `json:"type"`
+	FS       string `json:"fs"`
+	Groups   int32  `json:"groups"`
+	DirIndex bool   `json:"dirIndex"`
+}
+
+type pathRequest struct {
+	Action      string `json:"action"`
+	Type        string `json:"type"`
+	Parents     bool   `json:"parents"`
+	Size        int    `json:"size"`
+	Content     string `json:"content"`
+	Name        string `json:"name"`
+	Destination string `json:"destination"`
+	UGO         string `json:"ugo"`
+	User        string `json:"user"`
+	Recursive   bool   `json:"recursive"`
+}
+
+// knownDisks recuerda los discos creados por la API para resolver /disks/{id}
+var (
+	knownDisks      = make(map[string]string)
+	knownDisksMutex sync.Mutex
+)
+
+// registerAPIV1Routes registra los endpoints de la API versionada
+func registerAPIV1Routes() {
+	http.HandleFunc(apiV1Prefix+"/disks", corsMiddleware(apiDisksHandler))
+	http.HandleFunc(apiV1Prefix+"/disks/", corsMiddleware(apiDiskPartitionsHandler))
+	http.HandleFunc(apiV1Prefix+"/mounts", corsMiddleware(apiMountsHandler))
+	http.HandleFunc(apiV1Prefix+"/mounts/", corsMiddleware(apiMountsHandler))
+	http.HandleFunc(apiV1Prefix+"/partitions/", corsMiddleware(apiFormatHandler))
+	http.HandleFunc(apiV1Prefix+"/fs/", corsMiddleware(apiFileSystemHandler))
+}
+
+// apiDisksHandler maneja POST /disks (mkdisk) y DELETE /disks (rmdisk)
+func apiDisksHandler(w http.ResponseWriter, r *http.Request) {
+	var req diskRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if req.Path == "" {
+		req.Path = r.URL.Query().Get("path")
+	}
+	if req.Path == "" {
+		writeAPIError(w, http.StatusBadRequest, "parametro path requerido", "")
+		return
+	}
+
+	switch r.Method {
+	case "POST":
+		if req.Size <= 0 {
+			writeAPIError(w, http.StatusBadRequest, "parametro size requerido", "")
+			return
+		}
+		if req.Unit == "" {
+			req.Unit = "M"
+		}
+		if req.Fit == "" {
+			req.Fit = "WF"
+		}
+		output, err := captureOutput(func() error {
+			return withDiskLock(req.Path, func() error {
+				return Disk.MkDisk(req.Size, req.Unit, req.Fit, req.Path)
+			})
+		})
+		if err != nil {
+			writeAPIFailure(w, err, output)
+			return
+		}
+		registerKnownDisk(req.Path)
+		writeAPIResponse(w, http.StatusCreated, "Disco creado", output, map[string]string{
+			"id":   diskID(req.Path),
+			"path": req.Path,
+		})
+	case "DELETE":
+		output, err := captureOutput(func() error {
+			return withDiskLock(req.Path, func() error {
+				return Disk.RmDisk(req.Path)
+			})
+		})
+		if err != nil {
+			writeAPIFailure(w, err, output)
+			return
+		}
+		knownDisksMutex.Lock()
+		delete(knownDisks, diskID(req.Path))
+		knownDisksMutex.Unlock()
+		writeAPIResponse(w, http.StatusOK, "Disco eliminado", output, nil)
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "Método no permitido", "")
+	}
+}
+
+// apiDiskPartitionsHandler maneja /disks/{id}/partitions (fdisk)
+func apiDiskPartitionsHandler(w http.ResponseWriter, r *http.Request) {
+	parts := apiPathParts(r, "/disks")
+	if len(parts) != 2 || parts[1] != "partitions" {
+		writeAPIError(w, http.StatusNotFound, "Ruta no encontrada", "")
+		return
+	}
+
+	diskPath, err := resolveDiskPath(parts[0])
+	if err != nil {
+		writeAPIFailure(w, err, "")
+		return
+	}
+
+	if r.Method == "GET" {
+		unlock := Disk.LockDisk(diskPath, false)
+		info, err := Disk.GetDiskInfoByPath(diskPath)
+		unlock()
+		if err != nil {
+			writeAPIFailure(w, err, "")
+			return
+		}
+		writeAPIResponse(w, http.StatusOK, "Particiones del disco", "", info)
+		return
+	}
+
+	var req partitionRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if req.Name == "" {
+		req.Name = r.URL.Query().Get("name")
+	}
+	if req.Name == "" {
+		writeAPIError(w, http.StatusBadRequest, "parametro name requerido", "")
+		return
+	}
+
+	var fdisk func() error
+	status := http.StatusOK
+	message := ""
+
+	switch r.Method {
+	case "POST":
+		if req.Size <= 0 {
+			writeAPIError(w, http.StatusBadRequest, "parametro size requerido", "")
+			return
+		}
+		if req.Unit == "" {
+			req.Unit = "K"
+		}
+		if req.Fit == "" {
+			req.Fit = "WF"
+		}
+		if req.Type == "" {
+			req.Type = "P"
+		}
+		fdisk = func() error {
+			return Disk.Fdisk(req.Size, req.Unit, req.Fit, diskPath, req.Type, req.Name, "", 0)
+		}
+		status = http.StatusCreated
+		message = "Partición creada"
+	case "PATCH":
+		if req.Add == 0 {
+			writeAPIError(w, http.StatusBadRequest, "parametro add requerido", "")
+			return
+		}
+		if req.Unit == "" {
+			req.Unit = "K"
+		}
+		fdisk = func() error {
+			return Disk.Fdisk(0, req.Unit, "", diskPath, "", req.Name, "", req.Add)
+		}
+		message = "Partición redimensionada"
+	case "DELETE":
+		if req.Mode == "" {
+			req.Mode = r.URL.Query().Get("mode")
+		}
+		if req.Mode == "" {
+			req.Mode = "fast"
+		}
+		fdisk = func() error {
+			return Disk.Fdisk(0, "", "", diskPath, "", req.Name, req.Mode, 0)
+		}
+		message = "Partición eliminada"
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "Método no permitido", "")
+		return
+	}
+
+	output, err := captureOutput(func() error {
+		return withDiskLock(diskPath, fdisk)
+	})
+	if err != nil {
+		writeAPIFailure(w, err, output)
+		return
+	}
+	writeAPIResponse(w, status, message, output, nil)
+}
+
+// apiMountsHandler maneja GET/POST /mounts y DELETE /mounts/{id}
+func apiMountsHandler(w http.ResponseWriter, r *http.Request) {
+	parts := apiPathParts(r, "/mounts")
+
+	switch r.Method {
+	case "GET":
+		type mountJSON struct {
+			ID        string `json:"id"`
+			DiskPath  string `json:"diskPath"`
+			Partition string `json:"partition"`
+			Options   string `json:"options"`
+		}
+		mounts := []mountJSON{}
+		for _, mount := range Disk.GetMountedPartitions() {
+			mounts = append(mounts, mountJSON{ID: mount.MountID, DiskPath: mount.DiskPath, Partition: mount.PartitionName, Options: mount.Options.String()})
+		}
+		writeAPIResponse(w, http.StatusOK, "Particiones montadas", "", mounts)
+	case "POST":
+		var req mountRequest
+		if !decodeAPIBody(w, r, &req) {
+			return
+		}
+		output, err := captureOutput(func() error {
+			return withDiskLock(req.Path, func() error {
+				return Disk.Mount(req.Path, req.Name, Disk.MountOptions{ReadOnly: req.ReadOnly, NoAtime: req.NoAtime})
+			})
+		})
+		if err != nil {
+			writeAPIFailure(w, err, output)
+			return
+		}
+		registerKnownDisk(req.Path)
+
+		data := map[string]string{"path": req.Path, "name": req.Name}
+		for _, mount := range Disk.GetMountedPartitions() {
+			if mount.DiskPath == req.Path && mount.PartitionName == req.Name {
+				data["id"] = mount.MountID
+			}
+		}
+		writeAPIResponse(w, http.StatusCreated, "Partición montada", output, data)
+	case "DELETE":
+		if len(parts) != 1 {
+			writeAPIError(w, http.StatusBadRequest, "Debe indicar el id: /mounts/{id}", "")
+			return
+		}
+		output, err := captureOutput(func() error {
+			return withMountedDiskLock(parts[0], func() error {
+				return Disk.UnmountPartition(parts[0])
+			})
+		})
+		if err != nil {
+			writeAPIFailure(w, err, output)
+			return
+		}
+		writeAPIResponse(w, http.StatusOK, "Partición desmontada", output, nil)
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "Método no permitido", "")
+	}
+}
+
+// apiFormatHandler maneja POST /partitions/{id}/format (mkfs)
+func apiFormatHandler(w http.ResponseWriter, r *http.Request) {
+	parts := apiPathParts(r, "/partitions")
+	if len(parts) != 2 || parts[1] != "format" {
+		writeAPIError(w, http.StatusNotFound, "Ruta no encontrada", "")
+		return
+	}
+	if r.Method != "POST" {
+		writeAPIError(w, http.StatusMethodNotAllowed, "Método no permitido", "")
+		return
+	}
+
+	var req formatRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if req.Type == "" {
+		req.Type = "full"
+	}
+	if req.FS == "" {
+		req.FS = "2fs"
+	}
+	if req.Groups < 1 {
+		req.Groups = 1
+	}
+
+	output, err := captureOutput(func() error {
+		return withPartitionLock(parts[0], true, func() error {
+			return Disk.Mkfs(parts[0], req.Type, req.FS, req.Groups, req.DirIndex)
+		})
+	})
+	if err != nil {
+		writeAPIFailure(w, err, output)
+		return
+	}
+	writeAPIResponse(w, http.StatusOK, "Partición formateada", output, nil)
+}
+
+// apiFileSystemHandler maneja /fs/{id}/paths/{ruta}
+//   - POST: mkdir, mkfile o copy (campo action)
+//   - PUT: edit
+//   - PATCH: rename, move, chmod o chown (campo action)
+//   - DELETE: remove
+func apiFileSystemHandler(w http.ResponseWriter, r *http.Request) {
+	parts := apiPathParts(r, "/fs")
+	if len(parts) < 2 || parts[1] != "paths" {
+		writeAPIError(w, http.StatusNotFound, "Ruta no encontrada", "")
+		return
+	}
+	partitionID := parts[0]
+	path := "/" + strings.Join(parts[2:], "/")
+
+	// Los comandos de archivos operan sobre la sesión activa
+	if status, err := checkPartitionSession(partitionID); err != nil {
+		writeAPIError(w, status, err.Error(), "")
+		return
+	}
+
+	var req pathRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+
+	params := map[string]string{"path": path}
+	var command func(map[string]string) error
+	status := http.StatusOK
+	message := ""
+
+	switch r.Method {
+	case "POST":
+		action := req.Action
+		if action == "" {
+			action = "mkdir"
+			if req.Type == "file" {
+				action = "mkfile"
+			}
+		}
+		switch action {
+		case "mkdir":
+			if req.Parents {
+				params["p"] = ""
+			}
+			command = Root.MkDir
+			message = "Carpeta creada"
+		case "mkfile":
+			if req.Parents {
+				params["r"] = ""
+			}
+			if req.Size > 0 {
+				params["size"] = strconv.Itoa(req.Size)
+			}
+			if req.Content != "" {
+				contentFile, err := writeTempContent(req.Content)
+				if err != nil {
+					writeAPIFailure(w, err, "")
+					return
+				}
+				defer os.Remove(contentFile)
+				params["cont"] = contentFile
+			}
+			command = Root.MkFile
+			message = "Archivo creado"
+		case "copy":
+			if req.Destination == "" {
+				writeAPIError(w, http.StatusBadRequest, "parametro destination requerido", "")
+				return
+			}
+			params["destino"] = req.Destination
+			command = Operations.Copy
+			message = "Copia realizada"
+		default:
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("Acción '%s' no válida para POST (mkdir, mkfile o copy)", action), "")
+			return
+		}
+		status = http.StatusCreated
+	case "PUT":
+		contentFile, err := writeTempContent(req.Content)
+		if err != nil {
+			writeAPIFailure(w, err, "")
+			return
+		}
+		defer os.Remove(contentFile)
+		params["contenido"] = contentFile
+		command = Operations.Edit
+		message = "Archivo editado"
+	case "PATCH":
+		switch req.Action {
+		case "rename":
+			if req.Name == "" {
+				writeAPIError(w, http.StatusBadRequest, "parametro name requerido", "")
+				return
+			}
+			params["name"] = req.Name
+			command = Operations.Rename
+			message = "Nombre actualizado"
+		case "move":
+			if req.Destination == "" {
+				writeAPIError(w, http.StatusBadRequest, "parametro destination requerido", "")
+				return
+			}
+			params["destino"] = req.Destination
+			command = Operations.Move
+			message = "Ruta movida"
+		case "chmod":
+			if len(req.UGO) != 3 {
+				writeAPIError(w, http.StatusBadRequest, "parametro ugo debe tener tres dígitos", "")
+				return
+			}
+			params["ugo"] = req.UGO
+			if req.Recursive {
+				params["r"] = ""
+			}
+			command = Operations.Chmod
+			message = "Permisos actualizados"
+		case "chown":
+			if req.User == "" {
+				writeAPIError(w, http.StatusBadRequest, "parametro user requerido", "")
+				return
+			}
+			params["usuario"] = req.User
+			if req.Recursive {
+				params["r"] = ""
+			}
+			command = Operations.Chown
+			message = "Propietario actualizado"
+		default:
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("Acción '%s' no válida para PATCH (rename, move, chmod o chown)", req.Action), "")
+			return
+		}
+	case "DELETE":
+		command = Operations.Remove
+		message = "Ruta eliminada"
+	default:
+		writeAPIError(w, http.StatusMethodNotAllowed, "Método no permitido", "")
+		return
+	}
+
+	output, err := captureOutput(func() error {
+		return withPartitionTransaction(partitionID, func() error {
+			return command(params)
+		})
+	})
+	if err != nil {
+		writeAPIFailure(w, err, output)
+		return
+	}
+	writeAPIResponse(w, status, message, output, map[string]string{"path": path})
+}
+
+// checkPartitionSession verifica que exista una sesión activa sobre la partición indicada
+func checkPartitionSession(partitionID string) (int, error) {
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return http.StatusUnauthorized, fmt.Errorf("Debe iniciar sesión para usar este endpoint")
+	}
+	if session.MountID != partitionID {
+		return http.StatusConflict, fmt.Errorf("La sesión activa pertenece a la partición '%s'", session.MountID)
+	}
+	return http.StatusOK, nil
+}
+
+// apiPathParts separa la ruta después del recurso indicado (ej. "/disks")
+func apiPathParts(r *http.Request, resource string) []string {
+	rest := strings.TrimPrefix(r.URL.EscapedPath(), apiV1Prefix+resource)
+	rest = strings.Trim(rest, "/")
+	if rest == "" {
+		return nil
+	}
+
+	var parts []string
+	for _, part := range strings.Split(rest, "/") {
+		if unescaped, err := url.PathUnescape(part); err == nil {
+			part = unescaped
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// decodeAPIBody lee el cuerpo JSON si existe; un cuerpo vacío es válido
+func decodeAPIBody(w http.ResponseWriter, r *http.Request, target interface{}) bool {
+	if r.Body == nil {
+		return true
+	}
+	err := json.NewDecoder(r.Body).Decode(target)
+	if err != nil && err != io.EOF {
+		writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("Error al decodificar JSON: %v", err), "")
+		return false
+	}
+	return true
+}
+
+// diskID identifica un disco por el nombre de su archivo sin extensión
+func diskID(diskPath string) string {
+	return strings.TrimSuffix(filepath.Base(diskPath), filepath.Ext(diskPath))
+}
+
+func registerKnownDisk(diskPath string) {
+	knownDisksMutex.Lock()
+	defer knownDisksMutex.Unlock()
+	knownDisks[diskID(diskPath)] = diskPath
+}
+
+// resolveDiskPath obtiene la ruta de un disco a partir de su id, su nombre de archivo o su ruta absoluta
+func resolveDiskPath(id string) (string, error) {
+	if filepath.IsAbs(id) {
+		return id, nil
+	}
+
+	id = strings.TrimSuffix(id, filepath.Ext(id))
+
+	knownDisksMutex.Lock()
+	diskPath, exists := knownDisks[id]
+	knownDisksMutex.Unlock()
+	if exists {
+		return diskPath, nil
+	}
+
+	// Los discos con particiones montadas también son conocidos
+	for _, mount := range Disk.GetMountedPartitions() {
+		if diskID(mount.DiskPath) == id {
+			return mount.DiskPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("disco '%s' no encontrado", id)
+}
+
+// writeTempContent guarda contenido en un archivo temporal para los comandos que leen desde el host
+func writeTempContent(content string) (string, error) {
+	file, err := os.CreateTemp("", "mia_content_*.txt")
+	if err != nil {
+		return "", fmt.Errorf("error creando archivo temporal: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("error escribiendo archivo temporal: %v", err)
+	}
+	return file.Name(), nil
+}
+
+// apiErrorStatus traduce los mensajes de error de los comandos a códigos HTTP
+func apiErrorStatus(err error) int {
+	message := strings.ToLower(err.Error())
+
+	contains := func(patterns ...string) bool {
+		for _, pattern := range patterns {
+			if strings.Contains(message, pattern) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case contains("panic"):
+		return http.StatusInternalServerError
+	case contains("iniciar sesión", "no hay sesión", "sesión requerida"):
+		return http.StatusUnauthorized
+	case contains("permiso", "solo el usuario root", "no pertenece"):
+		return http.StatusForbidden
+	case contains("ya existe", "ya montada", "duplicado", "existente", "solo lectura"):
+		return http.StatusConflict
+	case contains("no existe", "no encontrad", "no montada", "no está montada"):
+		return http.StatusNotFound
+	case contains("espacio insuficiente", "sin espacios", "libres", "límite"):
+		return http.StatusInsufficientStorage
+	case contains("error leyendo", "error escribiendo", "error abriendo", "error creando", "error actualizando"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// apiErrorCodes relaciona los códigos HTTP con un identificador estable para los clientes
+var apiErrorCodes = map[int]string{
+	http.StatusBadRequest:          "invalid_request",
+	http.StatusUnauthorized:        "unauthenticated",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusMethodNotAllowed:    "method_not_allowed",
+	http.StatusConflict:            "conflict",
+	http.StatusInsufficientStorage: "insufficient_storage",
+	http.StatusInternalServerError: "internal_error",
+}
+
+func writeAPIFailure(w http.ResponseWriter, err error, output string) {
+	writeAPIError(w, apiErrorStatus(err), err.Error(), output)
+}
+
+// writeAPIError responde con un error estructurado {"error": {...}}
+func writeAPIError(w http.ResponseWriter, status int, message string, output string) {
+	type ErrorResponse struct {
+		Error APIError `json:"error"`
+	}
+	code, exists := apiErrorCodes[status]
+	if !exists {
+		code = "error"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: APIError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+		Output:  output,
+	}})
+}
+
+func writeAPIResponse(w http.ResponseWriter, status int, message string, output string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(APIResponse{Message: message, Output: output, Data: data})
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"MIA_2S2025_P1_202105668/Logica/Users"
+	"errors"
+)
+
+// Bloqueos por comando.
+//
+// processCommand toma el bloqueo que indica commandLocks antes de ejecutar el comando,
+// tanto en la consola como en /execute. Los handlers HTTP que llaman directamente a las
+// funciones de los paquetes usan los helpers with*Lock. Los comandos que no
+// aparecen en la tabla (mounted, logout, pwd, sync) solo usan estado que ya tiene su
+// propia sincronización.
+//
+// Los comandos que modifican la partición de la sesión, y los handlers que hacen lo
+// mismo con with*Transaction, se ejecutan además dentro de una transacción: en EXT3 sus
+// escrituras pasan por el journal y solo se aplican si el comando termina sin error.
+//
+// En una partición montada con -ro el bloqueo de escritura falla con Disk.ErrReadOnly y
+// el comando no se ejecuta.
+
+// lockScope indica sobre qué se toma el bloqueo de un comando
+type lockScope int
+
+const (
+	scopeDisk        lockScope = iota // disco de -path
+	scopeMountedDisk                  // disco de la partición -id (unmount)
+	scopePartitionID                  // partición -id
+	scopeSession                      // partición de la sesión activa
+)
+
+type commandLock struct {
+	scope lockScope
+	write bool
+}
+
+var commandLocks = map[string]commandLock{
+	// Estructura del disco
+	"mkdisk":   {scopeDisk, true},
+	"rmdisk":   {scopeDisk, true},
+	"fdisk":    {scopeDisk, true},
+	"mount":    {scopeDisk, true},
+	"unmount":  {scopeMountedDisk, true},
+	"showdisk": {scopeDisk, false},
+
+	// Partición indicada con -id
+	"mkfs":       {scopePartitionID, true},
+	"recovery":   {scopePartitionID, true},
+	"loss":       {scopePartitionID, true},
+	"journaling": {scopePartitionID, false},
+	"login":      {scopePartitionID, false},
+	"rep":        {scopePartitionID, false},
+
+	// Partición de la sesión: usuarios y grupos
+	"mkgrp":   {scopeSession, true},
+	"rmgrp":   {scopeSession, true},
+	"mkusr":   {scopeSession, true},
+	"rmusr":   {scopeSession, true},
+	"chgrp":   {scopeSession, true},
+	"passwd":  {scopeSession, true},
+	"usermod": {scopeSession, true},
+	"renusr":  {scopeSession, true},
+	"rengrp":  {scopeSession, true},
+	"lsusr":   {scopeSession, false},
+	"lsgrp":   {scopeSession, false},
+	"usrinfo": {scopeSession, false},
+
+	// Partición de la sesión: archivos y carpetas
+	"mkdir":   {scopeSession, true},
+	"mkfile":  {scopeSession, true},
+	"remove":  {scopeSession, true},
+	"trash":   {scopeSession, true},
+	"restore": {scopeSession, true},
+	"edit":    {scopeSession, true},
+	"rename":  {scopeSession, true},
+	"copy":    {scopeSession, true},
+	"move":    {scopeSession, true},
+	"chown":   {scopeSession, true},
+	"chmod":   {scopeSession, true},
+	"cd":      {scopeSession, false},
+	"cat":     {scopeSession, false},
+	"find":    {scopeSession, false},
+	"grep":    {scopeSession, false},
+	"ls":      {scopeSession, false},
+	"tree":    {scopeSession, false},
+	"stat":    {scopeSession, false},
+	"du":      {scopeSession, false},
+	"df":      {scopeSession, false},
+}
+
+// lockCommand toma el bloqueo del comando y retorna la función que lo libera.
+// Si no se puede determinar el disco o la partición no se bloquea nada: el comando
+// fallará con su propio mensaje de error. Solo retorna error si la partición es de
+// solo lectura y el comando escribe.
+func lockCommand(command string, params map[string]string) (func(), error) {
+	// find -exec bloquea la búsqueda y cada comando ejecutado por separado
+	if _, hasExec := params["exec"]; command == "find" && hasExec {
+		return func() {}, nil
+	}
+
+	lock, ok := commandLocks[command]
+	if !ok {
+		return func() {}, nil
+	}
+
+	switch lock.scope {
+	case scopeDisk:
+		if path := params["path"]; path != "" {
+			return Disk.LockDisk(path, lock.write), nil
+		}
+	case scopeMountedDisk:
+		if unlock, err := Disk.LockMountedDisk(params["id"]); err == nil {
+			return unlock, nil
+		}
+	case scopePartitionID:
+		return lockPartitionOrSkip(params["id"], lock.write)
+	case scopeSession:
+		if session := Users.GetCurrentSession(); session != nil && session.IsActive {
+			return lockPartitionOrSkip(session.MountID, lock.write)
+		}
+	}
+	return func() {}, nil
+}
+
+// lockPartitionOrSkip toma el bloqueo de la partición; si no está montada retorna una
+// función vacía para que el comando reporte su propio error
+func lockPartitionOrSkip(mountID string, write bool) (func(), error) {
+	unlock, err := Disk.LockPartition(mountID, write)
+	if errors.Is(err, Disk.ErrReadOnly) {
+		return nil, err
+	}
+	if err != nil {
+		return func() {}, nil
+	}
+	return unlock, nil
+}
+
+// withDiskLock ejecuta fn con el bloqueo de escritura del disco
+func withDiskLock(path string, fn func() error) error {
+	unlock := Disk.LockDisk(path, true)
+	defer unlock()
+	return fn()
+}
+
+// withMountedDiskLock ejecuta fn con el bloqueo de escritura del disco de la partición montada
+func withMountedDiskLock(mountID string, fn func() error) error {
+	if unlock, err := Disk.LockMountedDisk(mountID); err == nil {
+		defer unlock()
+	}
+	return fn()
+}
+
+// withPartitionLock ejecuta fn con el bloqueo de la partición montada (de escritura si write).
+// Si la partición no está montada ejecuta fn sin bloqueo para que reporte su propio error;
+// si es de solo lectura y write no ejecuta fn.
+func withPartitionLock(mountID string, write bool, fn func() error) error {
+	unlock, err := lockPartitionOrSkip(mountID, write)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// withSessionLock ejecuta fn con el bloqueo de la partición de la sesión activa
+func withSessionLock(write bool, fn func() error) error {
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return fn()
+	}
+	return withPartitionLock(session.MountID, write, fn)
+}
+
+// withSessionTransaction ejecuta fn con el bloqueo de escritura de la partición de la
+// sesión activa y dentro de una transacción
+func withSessionTransaction(fn func() error) error {
+	session := Users.GetCurrentSession()
+	if session == nil || !session.IsActive {
+		return fn()
+	}
+	return withPartitionTransaction(session.MountID, fn)
+}
+
+// withPartitionTransaction ejecuta fn con el bloqueo de escritura de la partición y
+// dentro de una transacción
+func withPartitionTransaction(mountID string, fn func() error) error {
+	return withPartitionLock(mountID, true, func() error {
+		return withTransaction(mountID, fn)
+	})
+}
+
+// runCommandTransaction ejecuta el comando, dentro de una transacción si modifica la
+// partición de la sesión. El bloqueo ya lo tomó lockCommand.
+func runCommandTransaction(command string, params map[string]string) error {
+	lock, ok := commandLocks[command]
+	session := Users.GetCurrentSession()
+	if !ok || lock.scope != scopeSession || !lock.write || session == nil || !session.IsActive {
+		return runCommand(command, params)
+	}
+	return withTransaction(session.MountID, func() error {
+		return runCommand(command, params)
+	})
+}
+
+// withTransaction ejecuta fn dentro de una transacción de la partición montada, con su
+// bloqueo de escritura ya tomado. Si fn falla o entra en pánico las escrituras se
+// descartan. En particiones que no son EXT3 ejecuta fn sin transacción.
+func withTransaction(mountID string, fn func() error) error {
+	mountInfo, err := Disk.GetMountInfoByID(mountID)
+	if err != nil {
+		return fn()
+	}
+
+	transaction, err := System.BeginTransaction(&System.MountInfo{
+		DiskPath:      mountInfo.DiskPath,
+		PartitionName: mountInfo.PartitionName,
+		MountID:       mountInfo.MountID,
+		DiskLetter:    mountInfo.DiskLetter,
+		PartNumber:    mountInfo.PartNumber,
+	})
+	if err != nil {
+		return err
+	}
+	if transaction == nil {
+		return fn()
+	}
+	defer transaction.Abort()
+
+	if err := fn(); err != nil {
+		return err
+	}
+	return transaction.Commit()
+}
+
+// flushAccessTimes escribe las horas de acceso que anotaron las lecturas (Disk.TouchAccessTime).
+// Se llama después de liberar el bloqueo de lectura de la lectura: toma el de escritura y
+// una transacción, como cualquier comando que modifica la partición.
+func flushAccessTimes() {
+	for _, mountID := range Disk.PendingAccessTimeMounts() {
+		withPartitionTransaction(mountID, func() error {
+			return Disk.FlushAccessTimes(mountID)
+		})
+	}
+}
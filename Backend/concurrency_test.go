@@ -0,0 +1,100 @@
+package main
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Estas pruebas tienen sentido con el detector de carreras: go test -race .
+
+func TestBlockedCommandDoesNotStopOtherDisks(t *testing.T) {
+	busyPath := "mem://pruebas/ocupado.mia"
+	freePath := "mem://pruebas/libre.mia"
+	runCommands(t, "mkdisk -size=1 -unit=M -path="+busyPath, "mkdisk -size=1 -unit=M -path="+freePath)
+	defer runCommands(t, "rmdisk -path="+busyPath, "rmdisk -path="+freePath)
+
+	// Un comando sobre el disco ocupado espera su bloqueo dentro de captureOutput
+	unlock := Disk.LockDisk(busyPath, true)
+	blocked := make(chan string)
+	started := make(chan struct{})
+	go func() {
+		out, _ := captureOutput(func() error {
+			close(started)
+			return processCommand("showdisk -path=" + busyPath)
+		})
+		blocked <- out
+	}()
+	<-started
+
+	done := make(chan string)
+	go func() {
+		out, _ := captureOutput(func() error { return processCommand("showdisk -path=" + freePath) })
+		done <- out
+	}()
+	select {
+	case out := <-done:
+		if strings.Contains(out, busyPath) {
+			t.Errorf("la salida incluye la del otro comando:\n%s", out)
+		}
+	case <-time.After(10 * time.Second):
+		t.Error("un comando sobre otro disco esperó al comando bloqueado")
+	}
+
+	unlock()
+	if out := <-blocked; !strings.Contains(out, busyPath) || strings.Contains(out, freePath) {
+		t.Errorf("salida del comando bloqueado:\n%s", out)
+	}
+}
+
+func TestConcurrentCommandsCaptureOwnOutput(t *testing.T) {
+	diskPath := "mem://pruebas/concurrencia.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "logout", "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	const workers, files = 6, 8
+	for w := 0; w < workers; w++ {
+		runCommands(t, fmt.Sprintf("mkdir -path=/w%d", w))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*files*2)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < files; i++ {
+				path := fmt.Sprintf("/w%d/f%d", w, i)
+				size := w + 1
+				if out, err := captureOutput(func() error {
+					return processCommand(fmt.Sprintf("mkfile -path=%s -size=%d", path, size))
+				}); err != nil {
+					errs <- fmt.Errorf("mkfile %s: %v\n%s", path, err, out)
+					continue
+				}
+				// cat solo debe retornar el contenido de su archivo, nada de los otros comandos
+				out, err := captureOutput(func() error { return processCommand("cat -file1=" + path) })
+				if err != nil || out != "0123456789"[:size] {
+					errs <- fmt.Errorf("cat %s = %q, %v", path, out, err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for w := 0; w < workers; w++ {
+		out := runCommands(t, fmt.Sprintf("ls -path=/w%d", w))
+		for i := 0; i < files; i++ {
+			if !strings.Contains(out, fmt.Sprintf("f%d", i)) {
+				t.Errorf("/w%d no tiene f%d:\n%s", w, i, out)
+			}
+		}
+	}
+}
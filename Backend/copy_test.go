@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// fillInodes crea archivos vacíos en dir hasta que no quedan inodos y retorna cuántos creó
+func fillInodes(t *testing.T, dir string) int {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		if err := commandError(fmt.Sprintf("mkfile -path=%s/f%d -size=0", dir, i)); err != nil {
+			return i
+		}
+	}
+	t.Fatal("la partición no se llenó")
+	return 0
+}
+
+// Una copia que se queda sin inodos a la mitad falla y en EXT3 no deja nada. La partición
+// es de 200 KB para que el journal tenga espacio para el registro de intenciones.
+func TestCopyWithoutSpaceIsDiscarded(t *testing.T) {
+	diskPath := "mem://pruebas/copia-llena.mia"
+	id := formatDiskSize(t, diskPath, 200, "3fs")
+	defer runCommands(t, "logout", "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	runCommands(t, "mkdir -path=/src", "mkdir -path=/dst", "mkdir -path=/fill")
+	for i := 0; i < 5; i++ {
+		runCommands(t, fmt.Sprintf("mkfile -path=/src/a%d -size=100", i))
+	}
+	fillInodes(t, "/fill")
+	runCommands(t, "remove -force -path=/fill/f0", "remove -force -path=/fill/f1", "remove -force -path=/fill/f2")
+
+	// La carpeta y sus 5 archivos necesitan 6 inodos y quedan 3
+	if err := commandError("copy -path=/src -destino=/dst"); err == nil {
+		t.Fatal("copy sin inodos suficientes no retornó error")
+	}
+	if err := commandError("stat -path=/dst/src"); err == nil {
+		t.Error("la copia fallida dejó /dst/src")
+	}
+	if n := fillInodes(t, "/dst"); n != 3 {
+		t.Errorf("después de la copia fallida quedaban %d inodos libres, se esperaban 3", n)
+	}
+}
+
+func TestCopyDirectory(t *testing.T) {
+	diskPath := "mem://pruebas/copia.mia"
+	id := formatDisk(t, diskPath, "3fs")
+	defer runCommands(t, "logout", "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	runCommands(t,
+		"mkdir -p -path=/src/sub",
+		"mkfile -path=/src/a.txt -size=70",
+		"mkfile -path=/src/sub/b.txt -size=4",
+		"mkdir -path=/dst",
+		"copy -path=/src -destino=/dst",
+	)
+	if got := runCommands(t, "cat -file1=/dst/src/sub/b.txt"); got != "0123" {
+		t.Errorf("cat de la copia = %q", got)
+	}
+	if got := runCommands(t, "cat -file1=/src/a.txt"); len(got) != 70 {
+		t.Errorf("el original cambió: %d bytes", len(got))
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Models"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// enableDirIndex activa S_dir_index en el superbloque de una partición desmontada, como
+// si una imagen anterior sin índices se usara con la versión actual
+func enableDirIndex(t *testing.T, diskPath string, partStart int64) {
+	t.Helper()
+	file, err := Device.OpenWrite(diskPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	raw := make([]byte, binary.Size(Models.SuperBloque{}))
+	if _, err := file.ReadAt(raw, partStart); err != nil {
+		t.Fatal(err)
+	}
+	var sb Models.SuperBloque
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &sb); err != nil {
+		t.Fatal(err)
+	}
+	sb.S_dir_index = 1
+	buffer := new(bytes.Buffer)
+	binary.Write(buffer, binary.LittleEndian, &sb)
+	if _, err := file.WriteAt(buffer.Bytes(), partStart); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkFiles verifica con cat que existan los archivos f00..f<count-1> de dir
+func checkFiles(t *testing.T, dir string, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		if got := runCommands(t, fmt.Sprintf("cat -file1=%s/f%02d", dir, i)); got != "0" {
+			t.Fatalf("cat %s/f%02d = %q", dir, i, got)
+		}
+	}
+}
+
+func TestMkfsDirIndexValue(t *testing.T) {
+	diskPath := "mem://pruebas/dirindex-valor.mia"
+	runCommands(t,
+		"mkdisk -size=3 -unit=M -path="+diskPath,
+		"fdisk -size=1 -unit=M -path="+diskPath+" -name=datos",
+		"mount -path="+diskPath+" -name=datos",
+	)
+	id := mountedID(t, diskPath, "datos")
+	defer runCommands(t, "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	if err := commandError("mkfs -id=" + id + " -dirindex=quizas"); err == nil {
+		t.Error("mkfs aceptó -dirindex=quizas")
+	}
+
+	for _, option := range []string{"-dirindex=false", "-dirindex=true", "-dirindex"} {
+		runCommands(t, "mkfs -id="+id+" "+option, "login -user=root -pass=123 -id="+id, "mkdir -path=/d")
+		out := runCommands(t, "stat -path=/d", "logout")
+		indexed := strings.Contains(out, "Índice de carpeta")
+		if indexed != (option != "-dirindex=false") {
+			t.Errorf("mkfs %s: carpeta con índice = %v\n%s", option, indexed, out)
+		}
+	}
+}
+
+func TestDirIndexFallsBackOnUnindexedDirectories(t *testing.T) {
+	diskPath := "mem://pruebas/dirindex.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "rmdisk -path="+diskPath)
+	partStart := mountedFileManager(t, id).GetManager().GetPartitionInfo().PartStart
+
+	// Carpeta de 11 bloques creada sin índice
+	runCommands(t, "mkdir -path=/viejo")
+	for i := 0; i < 40; i++ {
+		runCommands(t, fmt.Sprintf("mkfile -path=/viejo/f%02d -size=1", i))
+	}
+	runCommands(t, "logout", "unmount -id="+id)
+
+	enableDirIndex(t, diskPath, partStart)
+	runCommands(t, "mount -path="+diskPath+" -name=datos")
+	id = mountedID(t, diskPath, "datos")
+	defer runCommands(t, "unmount -id="+id)
+	runCommands(t, "login -user=root -pass=123 -id="+id)
+	defer runCommands(t, "logout")
+
+	if out := runCommands(t, "stat -path=/viejo"); strings.Contains(out, "Índice de carpeta") {
+		t.Fatalf("la carpeta anterior recibió un índice:\n%s", out)
+	}
+	checkFiles(t, "/viejo", 40)
+	runCommands(t, "mkfile -path=/viejo/nuevo -size=1", "remove -path=/viejo/f00 -force")
+	if got := runCommands(t, "cat -file1=/viejo/nuevo"); got != "0" {
+		t.Errorf("cat /viejo/nuevo = %q", got)
+	}
+	if err := commandError("cat -file1=/viejo/f00"); err == nil {
+		t.Error("cat encontró un archivo eliminado de la carpeta sin índice")
+	}
+
+	// Las carpetas nuevas sí llevan índice y se buscan por él
+	runCommands(t, "mkdir -path=/nueva")
+	if out := runCommands(t, "stat -path=/nueva"); !strings.Contains(out, "Índice de carpeta") {
+		t.Fatalf("la carpeta nueva no recibió índice:\n%s", out)
+	}
+	for i := 0; i < 40; i++ {
+		runCommands(t, fmt.Sprintf("mkfile -path=/nueva/f%02d -size=1", i))
+	}
+	checkFiles(t, "/nueva", 40)
+	runCommands(t, "remove -path=/nueva/f07 -force")
+	if err := commandError("cat -file1=/nueva/f07"); err == nil {
+		t.Error("cat encontró un archivo eliminado de la carpeta con índice")
+	}
+	if err := commandError("cat -file1=/nueva/f40"); err == nil {
+		t.Error("cat encontró un archivo que nunca existió")
+	}
+	checkFiles(t, "/nueva", 7)
+}
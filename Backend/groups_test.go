@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSupplementaryGroupPermissions(t *testing.T) {
+	diskPath := "mem://pruebas/grupos.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	// /pub/qa pertenece a luis y al grupo qa, sin permisos para otros
+	runCommands(t,
+		"mkgrp -name=equipo",
+		"mkgrp -name=qa",
+		"mkusr -user=ana -pass=123 -grp=equipo",
+		"mkusr -user=luis -pass=123 -grp=qa",
+		"mkdir -path=/pub",
+		"chmod -path=/pub -ugo=777",
+		"logout",
+		"login -user=luis -pass=123 -id="+id,
+		"mkdir -path=/pub/qa",
+		"logout",
+		"login -user=root -pass=123 -id="+id,
+		"chmod -path=/pub/qa -ugo=770 -r",
+		"logout",
+	)
+
+	runCommands(t, "login -user=ana -pass=123 -id="+id)
+	if err := commandError("mkfile -path=/pub/qa/a.txt -size=1"); err == nil {
+		t.Fatal("ana escribió en /pub/qa sin pertenecer al grupo qa")
+	}
+	runCommands(t, "logout", "login -user=root -pass=123 -id="+id)
+	if err := commandError("usermod -user=ana -addgrp=inexistente"); err == nil {
+		t.Error("usermod agregó un grupo inexistente")
+	}
+	runCommands(t, "usermod -user=ana -addgrp=qa", "logout")
+
+	runCommands(t, "login -user=ana -pass=123 -id="+id, "mkfile -path=/pub/qa/a.txt -size=1")
+	if got := runCommands(t, "cat -file1=/pub/qa/a.txt"); got != "0" {
+		t.Errorf("cat /pub/qa/a.txt = %q", got)
+	}
+	runCommands(t, "logout", "login -user=root -pass=123 -id="+id, "usermod -user=ana -delgrp=qa", "logout")
+
+	runCommands(t, "login -user=ana -pass=123 -id="+id)
+	if err := commandError("mkfile -path=/pub/qa/b.txt -size=1"); err == nil {
+		t.Error("ana conservó los permisos de qa después de usermod -delgrp")
+	}
+	runCommands(t, "logout")
+
+	// rmgrp quita el grupo de los grupos secundarios de los usuarios
+	runCommands(t, "login -user=root -pass=123 -id="+id, "usermod -user=ana -addgrp=qa", "rmgrp -name=qa")
+	defer runCommands(t, "logout")
+	for _, line := range strings.Split(runCommands(t, "cat -file1=/users.txt"), "\n") {
+		if strings.Contains(line, ", ana, ") && strings.Count(line, ",") != 4 {
+			t.Errorf("ana sigue en el grupo eliminado: %s", line)
+		}
+	}
+}
+
+func TestRmgrpMissingGroup(t *testing.T) {
+	diskPath := "mem://pruebas/rmgrp.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "logout", "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	err := commandError("rmgrp -name=inexistente")
+	if err == nil || !strings.Contains(err.Error(), "El grupo 'inexistente' no existe") {
+		t.Errorf("rmgrp de un grupo inexistente = %v", err)
+	}
+}
@@ -1,22 +1,30 @@
 package main
 
 import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
 	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/Events"
+	"MIA_2S2025_P1_202105668/Logica/Output"
 	"MIA_2S2025_P1_202105668/Logica/Reportes"
 	"MIA_2S2025_P1_202105668/Logica/System"
 	"MIA_2S2025_P1_202105668/Logica/Users"
 	"MIA_2S2025_P1_202105668/Logica/Users/Comandos"
 	"MIA_2S2025_P1_202105668/Logica/Users/Operations"
 	"MIA_2S2025_P1_202105668/Logica/Users/Root"
+	"MIA_2S2025_P1_202105668/Utils"
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -26,11 +34,16 @@ func main() {
 		return
 	}
 
+	// Prueba de carga concurrente contra los endpoints (ver stress.go)
+	if len(os.Args) > 1 && os.Args[1] == "stress" {
+		os.Exit(runStress(os.Args[2:]))
+	}
+
 	// Modo consola tradicional
 	scanner := bufio.NewScanner(os.Stdin)
 
 	for {
-		fmt.Print("MIA> ")
+		fmt.Print(Users.Prompt())
 
 		if !scanner.Scan() {
 			break
@@ -70,10 +83,15 @@ func main() {
 			}
 		}()
 	}
+
+	// Escribir lo que quede en la caché de las particiones montadas
+	if err := Device.FlushAll(); err != nil {
+		fmt.Printf("error: escribiendo caché: %s\n", err.Error())
+	}
 }
 
 func processCommand(input string) error {
-	parts := strings.Fields(input)
+	parts := splitCommandLine(input)
 	if len(parts) == 0 {
 		return fmt.Errorf("comando vacio")
 	}
@@ -81,6 +99,20 @@ func processCommand(input string) error {
 	command := strings.ToLower(parts[0])
 	params := parseParameters(parts[1:])
 
+	// Se ejecuta después de unlock: las lecturas solo anotan las horas de acceso
+	defer flushAccessTimes()
+
+	unlock, err := lockCommand(command, params)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return runCommandTransaction(command, params)
+}
+
+// runCommand ejecuta el comando ya analizado; el bloqueo lo toma processCommand
+func runCommand(command string, params map[string]string) error {
 	switch command {
 	case "mkdisk":
 		return processMkdisk(params)
@@ -95,6 +127,8 @@ func processCommand(input string) error {
 	case "mounted":
 		Disk.Mounted()
 		return nil
+	case "sync":
+		return Disk.Sync(params)
 	case "mkfs":
 		return processMkfs(params)
 	case "cat":
@@ -105,6 +139,10 @@ func processCommand(input string) error {
 		return Users.Login(params)
 	case "logout":
 		return Users.Logout()
+	case "cd":
+		return Users.Cd(params)
+	case "pwd":
+		return Users.Pwd()
 	case "mkgrp":
 		return Comandos.MkGrp(params)
 	case "rmgrp":
@@ -115,12 +153,30 @@ func processCommand(input string) error {
 		return Comandos.RmUsr(params)
 	case "chgrp":
 		return Comandos.ChGrp(params)
+	case "passwd":
+		return Comandos.Passwd(params)
+	case "usermod":
+		return Comandos.UserMod(params)
+	case "lsusr":
+		return Comandos.LsUsr(params)
+	case "lsgrp":
+		return Comandos.LsGrp(params)
+	case "renusr":
+		return Comandos.RenUsr(params)
+	case "rengrp":
+		return Comandos.RenGrp(params)
+	case "usrinfo":
+		return Comandos.UsrInfo(params)
 	case "mkdir":
 		return Root.MkDir(params)
 	case "mkfile":
 		return Root.MkFile(params)
 	case "remove":
 		return Operations.Remove(params)
+	case "trash":
+		return Operations.Trash(params)
+	case "restore":
+		return Operations.Restore(params)
 	case "edit":
 		return Operations.Edit(params)
 	case "rename":
@@ -130,7 +186,19 @@ func processCommand(input string) error {
 	case "move":
 		return Operations.Move(params)
 	case "find":
-		return Operations.Find(params)
+		return processFind(params)
+	case "grep":
+		return Operations.Grep(params)
+	case "ls":
+		return Operations.Ls(params)
+	case "tree":
+		return Operations.Tree(params)
+	case "stat":
+		return Operations.Stat(params)
+	case "du":
+		return Operations.Du(params)
+	case "df":
+		return Operations.Df(params)
 	case "chown":
 		return Operations.Chown(params)
 	case "chmod":
@@ -316,8 +384,10 @@ func processFdisk(params map[string]string) error {
 func processMount(params map[string]string) error {
 	// Validar que solo se usen parámetros permitidos
 	validParams := map[string]bool{
-		"path": true,
-		"name": true,
+		"path":    true,
+		"name":    true,
+		"ro":      true,
+		"noatime": true,
 	}
 
 	for param := range params {
@@ -336,7 +406,11 @@ func processMount(params map[string]string) error {
 		return fmt.Errorf("parametro -name requerido")
 	}
 
-	return Disk.Mount(path, name)
+	// -ro y -noatime son banderas sin valor
+	_, readOnly := params["ro"]
+	_, noAtime := params["noatime"]
+
+	return Disk.Mount(path, name, Disk.MountOptions{ReadOnly: readOnly, NoAtime: noAtime})
 }
 
 func processUnmount(params map[string]string) error {
@@ -362,9 +436,11 @@ func processUnmount(params map[string]string) error {
 func processMkfs(params map[string]string) error {
 	// Validar que solo se usen parámetros permitidos
 	validParams := map[string]bool{
-		"id":   true,
-		"type": true,
-		"fs":   true,
+		"id":       true,
+		"type":     true,
+		"fs":       true,
+		"groups":   true,
+		"dirindex": true,
 	}
 
 	for param := range params {
@@ -390,7 +466,31 @@ func processMkfs(params map[string]string) error {
 		fs = "2fs"
 	}
 
-	return Disk.Mkfs(id, formatType, fs)
+	// Parámetro groups (opcional, default 1 = sin grupos de bloques)
+	groups := 1
+	if value, ok := params["groups"]; ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return fmt.Errorf("ERROR: -groups debe ser un entero mayor que 0")
+		}
+		groups = parsed
+	}
+
+	// Parámetro dirindex (opcional): carpetas con índice de hash. Sin valor o con
+	// -dirindex=true se activa; -dirindex=false lo deja desactivado.
+	dirIndex := false
+	if value, ok := params["dirindex"]; ok {
+		switch strings.ToLower(value) {
+		case "", "true":
+			dirIndex = true
+		case "false":
+			dirIndex = false
+		default:
+			return fmt.Errorf("ERROR: -dirindex debe ser true o false")
+		}
+	}
+
+	return Disk.Mkfs(id, formatType, fs, int32(groups), dirIndex)
 }
 
 func processRecovery(params map[string]string) error {
@@ -412,6 +512,11 @@ func processRecovery(params map[string]string) error {
 		PartNumber:    mountInfo.PartNumber,
 	}
 
+	// -sb reconstruye el superbloque principal desde sus copias de respaldo
+	if _, fromBackup := params["sb"]; fromBackup {
+		return System.RestoreSuperBlock(systemMountInfo)
+	}
+
 	ext2Manager := System.NewEXT2Manager(systemMountInfo)
 	if ext2Manager == nil {
 		return fmt.Errorf("error inicializando gestor de archivos")
@@ -480,6 +585,34 @@ func processJournaling(params map[string]string) error {
 	return journalingViewer.ShowJournal()
 }
 
+// splitCommandLine separa la línea por espacios respetando los valores entre comillas dobles,
+// por ejemplo -exec="chmod -ugo=640 -path={}"
+func splitCommandLine(input string) []string {
+	var parts []string
+	var current strings.Builder
+	inQuotes := false
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if current.Len() > 0 {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+
+	return parts
+}
+
 func parseParameters(args []string) map[string]string {
 	params := make(map[string]string)
 
@@ -502,6 +635,57 @@ func parseParameters(args []string) map[string]string {
 	return params
 }
 
+// findExecCommands son los comandos que find -exec puede ejecutar: solo los que trabajan
+// sobre archivos y carpetas de la partición, nunca los de discos, particiones o usuarios
+var findExecCommands = map[string]bool{
+	"cat": true, "mkdir": true, "mkfile": true, "remove": true, "trash": true, "restore": true,
+	"edit": true, "rename": true, "copy": true, "move": true, "grep": true, "ls": true,
+	"tree": true, "stat": true, "du": true, "chown": true, "chmod": true,
+}
+
+func processFind(params map[string]string) error {
+	command, hasExec := params["exec"]
+	if !hasExec {
+		return Operations.Find(params)
+	}
+	delete(params, "exec")
+
+	if strings.TrimSpace(command) == "" || command == "true" {
+		return fmt.Errorf("parametro -exec requiere un comando, ej: -exec=\"chmod -ugo=640 -path={}\"")
+	}
+	if name := strings.ToLower(strings.Fields(command)[0]); !findExecCommands[name] {
+		return fmt.Errorf("-exec solo puede ejecutar comandos de archivos y carpetas, no '%s'", name)
+	}
+
+	// La búsqueda se bloquea sola; cada comando de -exec toma su propio bloqueo
+	var results []Operations.FindResult
+	err := withSessionLock(false, func() error {
+		var err error
+		_, results, err = Operations.FindMatches(params)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Ejecutar el comando por cada coincidencia; un fallo no detiene las demás
+	failed := 0
+	for _, result := range results {
+		execCommand, err := Operations.FindExecCommand(command, result)
+		if err == nil {
+			Output.Printf("exec: %s\n", execCommand)
+			err = processCommand(execCommand)
+		}
+		if err != nil {
+			failed++
+			Output.Printf("error: %s\n", err.Error())
+		}
+	}
+
+	Output.Printf("find -exec: %d coincidencias, %d con error\n", len(results), failed)
+	return nil
+}
+
 func processCat(params map[string]string) error {
 	// Verificar sesión activa
 	session := Users.GetCurrentSession()
@@ -509,6 +693,13 @@ func processCat(params map[string]string) error {
 		return fmt.Errorf("ERROR: Debe iniciar sesión para usar este comando")
 	}
 
+	// Resolver las rutas relativas de -file1..-fileN contra el directorio de trabajo
+	for key, value := range params {
+		if strings.HasPrefix(key, "file") {
+			params[key] = Users.ResolvePath(value)
+		}
+	}
+
 	// Pasar la sesión al comando Cat
 	return Disk.CatWithSession(params, session.MountID)
 }
@@ -520,6 +711,8 @@ func processRep(params map[string]string) error {
 		"path":         true,
 		"id":           true,
 		"path_file_ls": true,
+		"renderer":     true,
+		"format":       true,
 	}
 
 	for param := range params {
@@ -544,6 +737,13 @@ func processRep(params map[string]string) error {
 		return fmt.Errorf("parametro -id requerido")
 	}
 
+	// path_file_ls relativo se resuelve contra el directorio de trabajo de la sesión
+	if session := Users.GetCurrentSession(); session != nil && session.IsActive && session.MountID == id {
+		if pathFileLS, ok := params["path_file_ls"]; ok {
+			params["path_file_ls"] = Users.ResolvePath(pathFileLS)
+		}
+	}
+
 	// Validar valores válidos para name
 	validNames := map[string]bool{
 		"mbr":   true,
@@ -551,16 +751,36 @@ func processRep(params map[string]string) error {
 		"ebr":   true,
 		"inode": true,
 		"sb":    true,
-		"file":  true,
-		"ls":    true,
+		"file":     true,
+		"ls":       true,
+		"bm_inode": true,
+		"bm_block": true,
+		"orphans":  true,
 	}
 
 	if !validNames[name] {
-		return fmt.Errorf("valor de -name debe ser: mbr, disk, ebr, inode, sb, file o ls")
+		return fmt.Errorf("valor de -name debe ser: mbr, disk, ebr, inode, sb, file, ls, bm_inode, bm_block u orphans")
+	}
+
+	// Exportación de las estructuras en JSON en lugar de imagen
+	if format, ok := params["format"]; ok {
+		switch strings.ToLower(format) {
+		case "json":
+			return Reportes.GenerateJSONReport(name, id, path, params["path_file_ls"])
+		case "image":
+		default:
+			return fmt.Errorf("valor de -format debe ser: json o image")
+		}
+	}
+
+	// Renderizador forzado solo para este reporte (auto, dot o svg)
+	renderer, err := Utils.ParseRendererMode(params["renderer"])
+	if err != nil {
+		return err
 	}
 
 	// Llamar al generador de reportes correspondiente
-	return Reportes.GenerateReport(name, id, path, params["path_file_ls"])
+	return Reportes.GenerateReport(name, id, path, params["path_file_ls"], renderer)
 }
 
 // === SERVIDOR WEB ===
@@ -576,7 +796,7 @@ type CommandResponse struct {
 
 func enableCors(w *http.ResponseWriter) {
 	(*w).Header().Set("Access-Control-Allow-Origin", "*")
-	(*w).Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+	(*w).Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
 	(*w).Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
 }
 
@@ -598,43 +818,20 @@ func executeCommandHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Capturar la salida estándar
-	oldStdout := os.Stdout
-	r_out, w_out, _ := os.Pipe()
-	os.Stdout = w_out
-
-	// Capturar errores
-	var cmdError error
-
-	// Ejecutar el comando en una goroutine para capturar la salida
-	done := make(chan bool)
-	var output []byte
-
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				cmdError = fmt.Errorf("PANIC: %v", r)
-			}
-			done <- true
-		}()
-
-		// Procesar el comando usando la función existente
-		cmdError = processCommand(strings.TrimSpace(req.Command))
-	}()
-
-	// Esperar a que termine y cerrar el pipe
-	go func() {
-		<-done
-		w_out.Close()
-	}()
-
-	// Leer la salida
-	output, _ = io.ReadAll(r_out)
-	os.Stdout = oldStdout
+	// Procesar el comando usando la función existente capturando la salida
+	command := strings.TrimSpace(req.Command)
+	Events.EmitCommand("start", command)
+	output, cmdError := captureOutput(func() error {
+		return processCommand(command)
+	})
+	if cmdError != nil {
+		Events.EmitOutput(fmt.Sprintf("error: %s", cmdError.Error()))
+	}
+	Events.EmitCommand("end", command)
 
 	// Preparar la respuesta
 	resp := CommandResponse{
-		Output: string(output),
+		Output: output,
 	}
 
 	if cmdError != nil {
@@ -647,6 +844,53 @@ func executeCommandHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// captureOutput ejecuta fn en la goroutine actual y retorna lo que imprimió. La salida
+// se captura por goroutine (Output.Capture), así las peticiones se ejecutan en paralelo
+// sin redirigir os.Stdout; el orden entre comandos lo dan los bloqueos de commandLocks.
+func captureOutput(fn func() error) (output string, cmdError error) {
+	var buffer strings.Builder
+	lines := &lineEmitter{out: &buffer}
+	defer func() {
+		if r := recover(); r != nil {
+			cmdError = fmt.Errorf("PANIC: %v", r)
+		}
+		lines.flush()
+		output = buffer.String()
+	}()
+
+	cmdError = Output.Capture(lines, fn)
+	return
+}
+
+// lineEmitter guarda la salida de un comando y publica cada línea completa a los
+// suscriptores de /events
+type lineEmitter struct {
+	out     *strings.Builder
+	pending []byte
+}
+
+func (l *lineEmitter) Write(p []byte) (int, error) {
+	l.out.Write(p)
+	l.pending = append(l.pending, p...)
+	for {
+		end := bytes.IndexByte(l.pending, '\n')
+		if end == -1 {
+			break
+		}
+		Events.EmitOutput(strings.TrimRight(string(l.pending[:end]), "\r"))
+		l.pending = l.pending[end+1:]
+	}
+	return len(p), nil
+}
+
+// flush publica la última línea si no terminó en salto de línea
+func (l *lineEmitter) flush() {
+	if len(l.pending) > 0 {
+		Events.EmitOutput(strings.TrimRight(string(l.pending), "\r"))
+		l.pending = nil
+	}
+}
+
 func getDisksHandler(w http.ResponseWriter, r *http.Request) {
 	enableCors(&w)
 
@@ -717,6 +961,15 @@ func getFileSystemContentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Leer la partición con su bloqueo de lectura; la hora de acceso se escribe al liberarlo
+	defer flushAccessTimes()
+	unlock, err := Disk.LockPartition(partitionID, false)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "Partición no montada o no encontrada")
+		return
+	}
+	defer unlock()
+
 	// Crear MountInfo para el sistema EXT2
 	systemMountInfo := &System.MountInfo{
 		DiskPath:      mountInfo.DiskPath,
@@ -761,6 +1014,7 @@ func getFileSystemContentHandler(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Error al listar directorio: %s", err.Error())})
 		return
 	}
+	Disk.TouchAccessTime(partitionID, path)
 
 	// Transformar las entradas al formato esperado por el frontend
 	type FileSystemEntry struct {
@@ -815,6 +1069,12 @@ func getFileContentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// PUT crea o sobrescribe el archivo con el cuerpo de la petición
+	if r.Method == "PUT" {
+		putFileContentHandler(w, r)
+		return
+	}
+
 	if r.Method != "GET" {
 		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
 		return
@@ -846,6 +1106,15 @@ func getFileContentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Leer la partición con su bloqueo de lectura; la hora de acceso se escribe al liberarlo
+	defer flushAccessTimes()
+	unlock, err := Disk.LockPartition(partitionID, false)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "Partición no montada o no encontrada")
+		return
+	}
+	defer unlock()
+
 	// Crear MountInfo para el sistema EXT2
 	systemMountInfo := &System.MountInfo{
 		DiskPath:      mountInfo.DiskPath,
@@ -890,6 +1159,7 @@ func getFileContentHandler(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Error al leer archivo: %s", err.Error())})
 		return
 	}
+	Disk.TouchAccessTime(partitionID, filePath)
 
 	// Respuesta con el contenido del archivo
 	type FileContentResponse struct {
@@ -908,12 +1178,551 @@ func getFileContentHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// maxUploadSize limita el tamaño de los archivos recibidos por HTTP
+const maxUploadSize = 10 << 20
+
+func putFileContentHandler(w http.ResponseWriter, r *http.Request) {
+	partitionID := r.URL.Query().Get("partition_id")
+	filePath := r.URL.Query().Get("path")
+
+	if partitionID == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro partition_id requerido")
+		return
+	}
+
+	if filePath == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro path requerido")
+		return
+	}
+
+	// Las escrituras usan la sesión activa para validar permisos UGO
+	if status, err := checkPartitionSession(partitionID); err != nil {
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	content, err := readUploadedContent(w, r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var exists bool
+	err = withPartitionLock(partitionID, false, func() error {
+		var err error
+		exists, err = Operations.CheckWriteAccess(filePath)
+		return err
+	})
+	if err != nil {
+		writeJSONError(w, apiErrorStatus(err), err.Error())
+		return
+	}
+
+	// Edit y MkFile leen el contenido desde un archivo del host
+	contentFile, err := writeTempContent(string(content))
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer os.Remove(contentFile)
+
+	output, err := captureOutput(func() error {
+		return withPartitionTransaction(partitionID, func() error {
+			if exists {
+				return Operations.Edit(map[string]string{"path": filePath, "contenido": contentFile})
+			}
+			params := map[string]string{"path": filePath, "cont": contentFile}
+			if r.URL.Query().Get("parents") == "true" {
+				params["r"] = ""
+			}
+			return Root.MkFile(params)
+		})
+	})
+	if err != nil {
+		writeJSONError(w, apiErrorStatus(err), err.Error())
+		return
+	}
+
+	type WriteResponse struct {
+		Path    string `json:"path"`
+		Size    int    `json:"size"`
+		Created bool   `json:"created"`
+		Output  string `json:"output,omitempty"`
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !exists {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(WriteResponse{Path: filePath, Size: len(content), Created: !exists, Output: output})
+}
+
+// readUploadedContent obtiene el contenido del cuerpo crudo o del primer archivo de un formulario multipart
+func readUploadedContent(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("Error leyendo contenido: %v", err)
+		}
+		return content, nil
+	}
+
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		return nil, fmt.Errorf("Error leyendo formulario: %v", err)
+	}
+
+	// Preferir el campo "file"; si no existe usar el primer archivo recibido
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		for _, headers := range r.MultipartForm.File {
+			if len(headers) > 0 {
+				file, err = headers[0].Open()
+				break
+			}
+		}
+	}
+	if err != nil || file == nil {
+		return nil, fmt.Errorf("El formulario no contiene ningún archivo")
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("Error leyendo archivo: %v", err)
+	}
+	return content, nil
+}
+
+func createDirectoryHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "POST" {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Aceptar los parámetros por query o en un cuerpo JSON
+	type DirectoryRequest struct {
+		PartitionID string `json:"partition_id"`
+		Path        string `json:"path"`
+		Parents     bool   `json:"parents"`
+	}
+	req := DirectoryRequest{
+		PartitionID: r.URL.Query().Get("partition_id"),
+		Path:        r.URL.Query().Get("path"),
+		Parents:     r.URL.Query().Get("parents") == "true",
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		writeJSONError(w, http.StatusBadRequest, "Error al decodificar JSON")
+		return
+	}
+
+	if req.PartitionID == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro partition_id requerido")
+		return
+	}
+
+	if req.Path == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro path requerido")
+		return
+	}
+
+	if status, err := checkPartitionSession(req.PartitionID); err != nil {
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	err := withPartitionLock(req.PartitionID, false, func() error {
+		return Operations.CheckParentWriteAccess(req.Path)
+	})
+	if err != nil {
+		writeJSONError(w, apiErrorStatus(err), err.Error())
+		return
+	}
+
+	output, err := captureOutput(func() error {
+		return withPartitionTransaction(req.PartitionID, func() error {
+			params := map[string]string{"path": req.Path}
+			if req.Parents {
+				params["p"] = ""
+			}
+			return Root.MkDir(params)
+		})
+	})
+	if err != nil {
+		writeJSONError(w, apiErrorStatus(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"path": req.Path, "output": output})
+}
+
+func deletePathHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "DELETE" {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	partitionID := r.URL.Query().Get("partition_id")
+	path := r.URL.Query().Get("path")
+
+	if partitionID == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro partition_id requerido")
+		return
+	}
+
+	if path == "" || path == "/" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro path requerido (no se puede eliminar la raíz)")
+		return
+	}
+
+	if status, err := checkPartitionSession(partitionID); err != nil {
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	// Remove valida el permiso de escritura de la ruta y de todo su contenido
+	output, err := captureOutput(func() error {
+		return withPartitionTransaction(partitionID, func() error {
+			return Operations.Remove(map[string]string{"path": path})
+		})
+	})
+	if err != nil {
+		writeJSONError(w, apiErrorStatus(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"path": path, "output": output})
+}
+
+// searchHandler busca texto en el contenido de los archivos (equivalente a grep -r)
+// GET /search?partition_id=681A&pattern=texto[&path=/home][&i=true][&regex=true][&recursive=false]
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	partitionID := query.Get("partition_id")
+	pattern := query.Get("pattern")
+	path := query.Get("path")
+	if path == "" {
+		path = "/"
+	}
+
+	if partitionID == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro partition_id requerido")
+		return
+	}
+
+	if pattern == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro pattern requerido")
+		return
+	}
+
+	if status, err := checkPartitionSession(partitionID); err != nil {
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	params := map[string]string{"path": path, "pattern": pattern}
+	if query.Get("recursive") != "false" {
+		params["r"] = "true"
+	}
+	if query.Get("i") == "true" {
+		params["i"] = "true"
+	}
+	if query.Get("regex") == "true" {
+		params["regex"] = "true"
+	}
+
+	var matches []Operations.GrepMatch
+	err := withPartitionLock(partitionID, false, func() error {
+		var err error
+		matches, err = Operations.GrepMatches(params)
+		return err
+	})
+	if err != nil {
+		writeJSONError(w, apiErrorStatus(err), err.Error())
+		return
+	}
+
+	type SearchResponse struct {
+		Path    string                 `json:"path"`
+		Pattern string                 `json:"pattern"`
+		Count   int                    `json:"count"`
+		Matches []Operations.GrepMatch `json:"matches"`
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SearchResponse{
+		Path:    path,
+		Pattern: pattern,
+		Count:   len(matches),
+		Matches: matches,
+	})
+}
+
+func getStructuresHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Aceptar tanto id como partition_id
+	partitionID := r.URL.Query().Get("id")
+	if partitionID == "" {
+		partitionID = r.URL.Query().Get("partition_id")
+	}
+	if partitionID == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro id requerido")
+		return
+	}
+
+	// Las estructuras exponen inodos y bloques de la partición: requieren sesión sobre ella
+	if status, err := checkPartitionSession(partitionID); err != nil {
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	// Ruta: /api/structures/{mbr|ebr|superblock|inode/{n}|block/{n}|bitmap}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/structures"), "/"), "/")
+
+	var data interface{}
+	var err error
+
+	// Si la partición no está montada los reportes retornan su propio error
+	if unlock, lockErr := Disk.LockPartition(partitionID, false); lockErr == nil {
+		defer unlock()
+	}
+
+	switch parts[0] {
+	case "mbr":
+		data, err = Reportes.GetMBRStructure(partitionID)
+	case "ebr":
+		data, err = Reportes.GetEBRStructures(partitionID)
+	case "superblock":
+		data, err = Reportes.GetSuperBlockStructure(partitionID)
+	case "inode", "block":
+		if len(parts) < 2 || parts[1] == "" {
+			if parts[0] == "inode" {
+				data, err = Reportes.GetUsedInodeStructures(partitionID)
+				break
+			}
+			writeJSONError(w, http.StatusBadRequest, "Debe indicar el número de bloque: /api/structures/block/{n}")
+			return
+		}
+		number, convErr := strconv.Atoi(parts[1])
+		if convErr != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Número de %s inválido: %s", parts[0], parts[1]))
+			return
+		}
+		if parts[0] == "inode" {
+			data, err = Reportes.GetInodeStructure(partitionID, number)
+		} else {
+			data, err = Reportes.GetBlockStructure(partitionID, number)
+		}
+	case "bitmap":
+		bitmapType := r.URL.Query().Get("type")
+		if len(parts) > 1 && parts[1] != "" {
+			bitmapType = parts[1]
+		}
+		if bitmapType == "" {
+			bitmapType = "inode"
+		}
+		data, err = Reportes.GetBitmapStructure(partitionID, bitmapType)
+	default:
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("Estructura '%s' no reconocida", parts[0]))
+		return
+	}
+
+	if err != nil {
+		status := http.StatusBadRequest
+		if strings.Contains(err.Error(), "no encontrada o no montada") {
+			status = http.StatusNotFound
+		}
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+func getReportHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Ruta: /reports/{mbr|disk|ebr|inode|sb|file|ls|bm_inode|bm_block|orphans}
+	reportType := strings.Trim(strings.TrimPrefix(r.URL.Path, "/reports"), "/")
+	if reportType == "" {
+		writeJSONError(w, http.StatusBadRequest, "Debe indicar el tipo de reporte: /reports/{tipo}")
+		return
+	}
+
+	partitionID := r.URL.Query().Get("id")
+	if partitionID == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro id requerido")
+		return
+	}
+
+	validReports := map[string]bool{
+		"mbr": true, "disk": true, "ebr": true, "inode": true, "sb": true,
+		"file": true, "ls": true, "bm_inode": true, "bm_block": true, "orphans": true,
+	}
+	if !validReports[reportType] {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("Reporte '%s' no reconocido", reportType))
+		return
+	}
+
+	pathFileLS := r.URL.Query().Get("path_file_ls")
+	if (reportType == "file" || reportType == "ls") && pathFileLS == "" {
+		writeJSONError(w, http.StatusBadRequest, "Parámetro path_file_ls requerido para este reporte")
+		return
+	}
+
+	// Los generadores imprimen mensajes de progreso; captureOutput los separa de la respuesta
+	var data []byte
+	var contentType string
+	_, err := captureOutput(func() error {
+		return withPartitionLock(partitionID, false, func() error {
+			var err error
+			data, contentType, err = Reportes.RenderReport(reportType, partitionID, pathFileLS, r.URL.Query().Get("format"))
+			return err
+		})
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "no encontrada o no montada") {
+			status = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "no soportado") {
+			status = http.StatusBadRequest
+		}
+		writeJSONError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
+
+// eventsHandler transmite los eventos del sistema como Server-Sent Events
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	enableCors(&w)
+
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "El servidor no soporta streaming")
+		return
+	}
+
+	// Filtro opcional por tipo: /events?types=output,fs
+	types := make(map[string]bool)
+	for _, t := range strings.Split(r.URL.Query().Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types[t] = true
+		}
+	}
+
+	events, unsubscribe := Events.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, ": conectado\n\n")
+	flusher.Flush()
+
+	// Comentario periódico para mantener viva la conexión a través de proxies
+	keepAlive := time.NewTicker(15 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			if len(types) > 0 && !types[event.Type] {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// writeJSONError responde con un error en formato JSON y el código de estado indicado
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	type ErrorResponse struct {
+		Error string `json:"error"`
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{Error: message})
+}
+
 // Middleware CORS para permitir peticiones desde S3
 func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Permitir peticiones desde cualquier origen (puedes restringir a tu bucket S3 específico)
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		// Manejar peticiones preflight (OPTIONS)
@@ -926,11 +1735,35 @@ func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-func startServer() {
+// registerRoutes registra los endpoints del servidor en http.DefaultServeMux
+func registerRoutes() {
 	http.HandleFunc("/execute", corsMiddleware(executeCommandHandler))
 	http.HandleFunc("/disks", corsMiddleware(getDisksHandler))
 	http.HandleFunc("/filesystem", corsMiddleware(getFileSystemContentHandler))
 	http.HandleFunc("/file-content", corsMiddleware(getFileContentHandler))
+	http.HandleFunc("/api/structures/", corsMiddleware(getStructuresHandler))
+	http.HandleFunc("/reports/", corsMiddleware(getReportHandler))
+	http.HandleFunc("/directory", corsMiddleware(createDirectoryHandler))
+	http.HandleFunc("/path", corsMiddleware(deletePathHandler))
+	http.HandleFunc("/events", corsMiddleware(eventsHandler))
+	http.HandleFunc("/search", corsMiddleware(searchHandler))
+	registerAPIV1Routes()
+	registerUserRoutes()
+}
+
+func startServer() {
+	registerRoutes()
+
+	// Con Ctrl+C o SIGTERM se escribe la caché antes de terminar
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		if err := Device.FlushAll(); err != nil {
+			log.Printf("error escribiendo caché: %v", err)
+		}
+		os.Exit(0)
+	}()
 
 	fmt.Println("Servidor iniciado en http://localhost:8080")
 	fmt.Println("Ctrl+C para detener")
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Device"
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Las pruebas ejecutan los comandos igual que la consola, sobre discos mem:// que no
+// dejan archivos. Cada prueba usa su propio disco porque los montajes y la sesión son
+// globales del proceso.
+
+// runCommands ejecuta los comandos en orden y retorna la salida de todos
+func runCommands(t *testing.T, commands ...string) string {
+	t.Helper()
+	var output strings.Builder
+	for _, command := range commands {
+		out, err := captureOutput(func() error { return processCommand(command) })
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", command, err, out)
+		}
+		output.WriteString(out)
+	}
+	return output.String()
+}
+
+// mountedID retorna el ID de montaje de la partición name del disco
+func mountedID(t *testing.T, diskPath string, name string) string {
+	t.Helper()
+	for _, mount := range Disk.GetMountedPartitions() {
+		if mount.DiskPath == diskPath && mount.PartitionName == name {
+			return mount.MountID
+		}
+	}
+	t.Fatalf("la partición %s de %s no está montada", name, diskPath)
+	return ""
+}
+
+// formatDisk crea el disco con una partición de 1 MB, la monta, la formatea con fs
+// e inicia sesión como root. Retorna el ID de montaje.
+func formatDisk(t *testing.T, diskPath string, fs string) string {
+	t.Helper()
+	return formatDiskSize(t, diskPath, 1024, fs)
+}
+
+// formatDiskSize es formatDisk con una partición de sizeKB kilobytes
+func formatDiskSize(t *testing.T, diskPath string, sizeKB int, fs string) string {
+	t.Helper()
+	runCommands(t,
+		"mkdisk -size=3 -unit=M -path="+diskPath,
+		fmt.Sprintf("fdisk -size=%d -unit=K -path=%s -name=datos", sizeKB, diskPath),
+		"mount -path="+diskPath+" -name=datos",
+	)
+	id := mountedID(t, diskPath, "datos")
+	runCommands(t,
+		"mkfs -id="+id+" -fs="+fs,
+		"login -user=root -pass=123 -id="+id,
+	)
+	return id
+}
+
+// commandError ejecuta el comando y retorna su error, o nil si terminó bien
+func commandError(command string) error {
+	_, err := captureOutput(func() error { return processCommand(command) })
+	return err
+}
+
+// crashCopy registra en copyPath una copia del dispositivo de diskPath tal como está,
+// sin escribir la caché: es lo que quedaría en el disco si el programa terminara ahora
+func crashCopy(t *testing.T, diskPath string, copyPath string) {
+	t.Helper()
+	device, ok := Device.Lookup(diskPath)
+	if !ok {
+		t.Fatalf("%s no está registrado", diskPath)
+	}
+	data := device.(*Device.MemoryDevice).Bytes()
+	copyDevice := Device.NewMemoryDevice(int64(len(data)))
+	copyDevice.WriteAt(data, 0)
+	Device.Register(copyPath, copyDevice)
+	t.Cleanup(func() { Device.Unregister(copyPath) })
+}
+
+func TestMemoryDiskFileCommands(t *testing.T) {
+	diskPath := "mem://pruebas/archivos.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "logout", "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	runCommands(t,
+		"mkdir -p -path=/home/docs",
+		"mkfile -path=/home/docs/a.txt -size=12",
+		"mkfile -path=\"/home/docs/a b.txt\" -size=3",
+	)
+
+	if out := runCommands(t, "cat -file1=/home/docs/a.txt"); out != "012345678901" {
+		t.Errorf("cat a.txt = %q", out)
+	}
+	if out := runCommands(t, "cat -file1=\"/home/docs/a b.txt\""); out != "012" {
+		t.Errorf("cat 'a b.txt' = %q", out)
+	}
+	if err := commandError("cat -file1=/home/docs/b.txt"); err == nil {
+		t.Error("cat de un archivo inexistente no retornó error")
+	}
+}
+
+func TestMountAfterCrashReplaysIntentLog(t *testing.T) {
+	diskPath := "mem://pruebas/journal.mia"
+	copyPath := "mem://pruebas/journal-copia.mia"
+	id := formatDisk(t, diskPath, "3fs")
+	defer runCommands(t, "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	// Las escrituras en su lugar quedan en la caché; el registro ya está en el disco
+	runCommands(t, "mkdir -p -path=/datos", "mkfile -path=/datos/f.txt -size=20", "logout")
+	crashCopy(t, diskPath, copyPath)
+
+	out := runCommands(t, "mount -path="+copyPath+" -name=datos")
+	copyID := mountedID(t, copyPath, "datos")
+	defer runCommands(t, "unmount -id="+copyID)
+
+	if !strings.Contains(out, "transacción(es) pendiente(s) aplicada(s)") {
+		t.Errorf("mount no aplicó el registro de intenciones:\n%s", out)
+	}
+	if !strings.Contains(out, "no se desmontó correctamente") {
+		t.Errorf("mount no detectó la partición sucia:\n%s", out)
+	}
+	if !strings.Contains(out, "sin inconsistencias") {
+		t.Errorf("la revisión encontró problemas después de aplicar el registro:\n%s", out)
+	}
+
+	runCommands(t, "login -user=root -pass=123 -id="+copyID)
+	defer runCommands(t, "logout")
+	if got := runCommands(t, "cat -file1=/datos/f.txt"); got != "01234567890123456789" {
+		t.Errorf("cat después de aplicar el registro = %q", got)
+	}
+}
+
+func TestCleanUnmountIsNotChecked(t *testing.T) {
+	diskPath := "mem://pruebas/limpio.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "rmdisk -path="+diskPath)
+
+	runCommands(t, "mkfile -path=/f.txt -size=5", "logout", "unmount -id="+id)
+
+	out := runCommands(t, "mount -path="+diskPath+" -name=datos")
+	defer runCommands(t, "unmount -id="+mountedID(t, diskPath, "datos"))
+	if strings.Contains(out, "no se desmontó correctamente") {
+		t.Errorf("mount revisó una partición desmontada correctamente:\n%s", out)
+	}
+}
+
+func TestRecoverySuperBlockFromBackup(t *testing.T) {
+	diskPath := "mem://pruebas/respaldo.mia"
+	id := formatDisk(t, diskPath, "3fs")
+	defer runCommands(t, "rmdisk -path="+diskPath)
+
+	mountInfo, err := Disk.GetMountInfoByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager := System.NewEXT2Manager(&System.MountInfo{
+		DiskPath:      mountInfo.DiskPath,
+		PartitionName: mountInfo.PartitionName,
+		MountID:       mountInfo.MountID,
+		DiskLetter:    mountInfo.DiskLetter,
+		PartNumber:    mountInfo.PartNumber,
+	})
+	partStart := manager.GetPartitionInfo().PartStart
+
+	runCommands(t, "mkfile -path=/f.txt -size=8", "logout", "unmount -id="+id)
+
+	// Borrar el superbloque principal
+	file, err := Device.OpenWrite(diskPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteAt(make([]byte, 1024), partStart)
+	file.Close()
+
+	runCommands(t, "mount -path="+diskPath+" -name=datos")
+	id = mountedID(t, diskPath, "datos")
+	defer runCommands(t, "unmount -id="+id)
+
+	out := runCommands(t, "recovery -id="+id+" -sb")
+	if !strings.Contains(out, "Superbloque principal reconstruido") {
+		t.Fatalf("recovery -sb no reconstruyó el superbloque:\n%s", out)
+	}
+
+	runCommands(t, "login -user=root -pass=123 -id="+id)
+	defer runCommands(t, "logout")
+	if got := runCommands(t, "cat -file1=/f.txt"); got != "01234567" {
+		t.Errorf("cat después de recovery -sb = %q", got)
+	}
+	if out := runCommands(t, "recovery -id="+id+" -sb"); !strings.Contains(out, "es válido, no se modificó") {
+		t.Errorf("recovery -sb modificó un superbloque válido:\n%s", out)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Reportes"
+	"strings"
+	"testing"
+)
+
+// renderReport genera el reporte por la misma ruta que GET /reports/{tipo} e indica si
+// se generó de nuevo o salió de la caché
+func renderReport(t *testing.T, reportType string, id string) bool {
+	t.Helper()
+	out, err := captureOutput(func() error {
+		_, _, err := Reportes.RenderReport(reportType, id, "", "svg")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("reporte %s: %v", reportType, err)
+	}
+	return strings.Contains(out, "Reporte generado")
+}
+
+func TestReportCacheFollowsSuperBlockMtime(t *testing.T) {
+	diskPath := "mem://pruebas/reportes.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "logout", "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	runCommands(t, "mkfile -path=/a.txt -size=5")
+	if !renderReport(t, "sb", id) {
+		t.Fatal("el primer reporte salió de la caché")
+	}
+
+	// Leer solo cambia I_atime: el reporte sigue en caché
+	runCommands(t, "cat -file1=/a.txt")
+	if renderReport(t, "sb", id) {
+		t.Error("una lectura invalidó el reporte en caché")
+	}
+
+	runCommands(t, "mkfile -path=/b.txt -size=5")
+	if !renderReport(t, "sb", id) {
+		t.Error("el reporte no se generó de nuevo después de mkfile")
+	}
+
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Prueba de carga concurrente: go run -race . stress [-workers=8] [-ops=40]
+//
+// Levanta los endpoints en un servidor de prueba sobre un disco en memoria y los llama
+// desde varias goroutines a la vez: mkfile por /execute, lecturas por /filesystem,
+// /file-content, /search, /api/structures, /reports y /disks, y montajes de otra
+// partición del mismo disco. Al terminar verifica que cada carpeta tenga todos sus
+// archivos y que los contadores libres del superbloque coincidan con los bitmaps.
+// Compilado con -race, el detector de carreras reporta además cualquier acceso sin
+// sincronizar y el proceso termina con código distinto de cero.
+
+const stressDisk = "mem://stress/disco.mia"
+
+// stressRun acumula los fallos de las goroutines de la prueba
+type stressRun struct {
+	client   *http.Client
+	baseURL  string
+	mountID  string
+	mu       sync.Mutex
+	failures []string
+}
+
+func runStress(args []string) int {
+	params := parseParameters(args)
+	workers, err := stressParam(params, "workers", 8)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	ops, err := stressParam(params, "ops", 40)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	mountID, err := stressSetup(workers)
+	if err != nil {
+		fmt.Printf("error preparando la prueba: %v\n", err)
+		return 1
+	}
+
+	registerRoutes()
+	server := httptest.NewServer(http.DefaultServeMux)
+	defer server.Close()
+
+	run := &stressRun{client: server.Client(), baseURL: server.URL, mountID: mountID}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			run.worker(worker, workers, ops)
+		}(w)
+	}
+	wg.Wait()
+
+	run.verify(workers, ops)
+
+	fmt.Printf("stress: %d goroutines x %d operaciones sobre %s\n", workers, ops, mountID)
+	if len(run.failures) > 0 {
+		for i, failure := range run.failures {
+			if i == 10 {
+				fmt.Printf("  ... %d fallos más\n", len(run.failures)-10)
+				break
+			}
+			fmt.Printf("  FALLO: %s\n", failure)
+		}
+		return 1
+	}
+	fmt.Println("stress: sin errores")
+	return 0
+}
+
+// stressSetup crea el disco, formatea la partición de datos e inicia sesión como root
+func stressSetup(workers int) (string, error) {
+	setup := []string{
+		"mkdisk -size=3 -unit=M -path=" + stressDisk,
+		"fdisk -size=2 -unit=M -path=" + stressDisk + " -name=datos",
+		"fdisk -size=512 -unit=K -path=" + stressDisk + " -name=extra",
+		"mount -path=" + stressDisk + " -name=datos",
+	}
+	for _, command := range setup {
+		if _, err := captureOutput(func() error { return processCommand(command) }); err != nil {
+			return "", fmt.Errorf("%s: %v", command, err)
+		}
+	}
+
+	mountID := ""
+	for _, mount := range Disk.GetMountedPartitions() {
+		if mount.DiskPath == stressDisk && mount.PartitionName == "datos" {
+			mountID = mount.MountID
+		}
+	}
+
+	setup = []string{
+		"mkfs -id=" + mountID + " -fs=3fs",
+		"login -user=root -pass=123 -id=" + mountID,
+	}
+	for w := 0; w < workers; w++ {
+		setup = append(setup, fmt.Sprintf("mkdir -path=/w%d", w))
+	}
+	for _, command := range setup {
+		if _, err := captureOutput(func() error { return processCommand(command) }); err != nil {
+			return "", fmt.Errorf("%s: %v", command, err)
+		}
+	}
+	return mountID, nil
+}
+
+// worker mezcla escrituras y lecturas sobre su carpeta; el worker 0 además monta y
+// desmonta la otra partición del disco
+func (s *stressRun) worker(worker int, workers int, ops int) {
+	dir := fmt.Sprintf("/w%d", worker)
+	id := url.QueryEscape(s.mountID)
+
+	for i := 0; i < ops; i++ {
+		switch i % 6 {
+		case 0, 3:
+			s.execute(fmt.Sprintf("mkfile -path=%s/f%d.txt -size=%d", dir, i, stressFileSize(worker, i)))
+		case 1:
+			var file struct {
+				Size int `json:"size"`
+			}
+			path := fmt.Sprintf("%s/f%d.txt", dir, i-1)
+			if s.getJSON(fmt.Sprintf("/file-content?partition_id=%s&path=%s", id, path), &file) && file.Size != stressFileSize(worker, i-1) {
+				s.fail("%s tiene %d bytes, se esperaban %d", path, file.Size, stressFileSize(worker, i-1))
+			}
+		case 2:
+			// Los archivos de otras carpetas se leen mientras su dueño sigue escribiendo
+			s.checkDirectory((worker + 1) % workers)
+		case 4:
+			reads := []string{
+				"/api/structures/superblock?id=" + id,
+				"/reports/sb?id=" + id,
+				"/disks",
+				fmt.Sprintf("/search?partition_id=%s&pattern=0123&path=%s", id, dir),
+			}
+			s.get(reads[(worker+i/6)%len(reads)])
+		case 5:
+			if worker != 0 {
+				s.get("/api/v1/mounts")
+				continue
+			}
+			s.execute("mount -path=" + stressDisk + " -name=extra")
+			for _, mount := range Disk.GetMountedPartitions() {
+				if mount.DiskPath == stressDisk && mount.PartitionName == "extra" {
+					s.execute("unmount -id=" + mount.MountID)
+				}
+			}
+		}
+	}
+}
+
+// verify comprueba la cantidad de archivos por carpeta y los contadores del superbloque
+func (s *stressRun) verify(workers int, ops int) {
+	expected := 0
+	for i := 0; i < ops; i++ {
+		if i%6 == 0 || i%6 == 3 {
+			expected++
+		}
+	}
+
+	id := url.QueryEscape(s.mountID)
+	for w := 0; w < workers; w++ {
+		if count := s.checkDirectory(w); count != expected {
+			s.fail("/w%d tiene %d archivos, se esperaban %d", w, count, expected)
+		}
+	}
+
+	var superBlock struct {
+		FreeInodesCount int `json:"freeInodesCount"`
+		FreeBlocksCount int `json:"freeBlocksCount"`
+	}
+	var inodes, blocks struct {
+		Free int `json:"free"`
+	}
+	if !s.getJSON("/api/structures/superblock?id="+id, &superBlock) ||
+		!s.getJSON("/api/structures/bitmap/inode?id="+id, &inodes) ||
+		!s.getJSON("/api/structures/bitmap/block?id="+id, &blocks) {
+		return
+	}
+	if superBlock.FreeInodesCount != inodes.Free {
+		s.fail("inodos libres: superbloque %d, bitmap %d", superBlock.FreeInodesCount, inodes.Free)
+	}
+	if superBlock.FreeBlocksCount != blocks.Free {
+		s.fail("bloques libres: superbloque %d, bitmap %d", superBlock.FreeBlocksCount, blocks.Free)
+	}
+}
+
+// checkDirectory lista la carpeta de un worker, verifica el tamaño de cada archivo
+// según su nombre y retorna cuántos tiene
+func (s *stressRun) checkDirectory(worker int) int {
+	var entries []struct {
+		Name string `json:"name"`
+		Size int    `json:"size"`
+	}
+	path := fmt.Sprintf("/filesystem?partition_id=%s&path=/w%d", url.QueryEscape(s.mountID), worker)
+	if !s.getJSON(path, &entries) {
+		return -1
+	}
+
+	for _, entry := range entries {
+		var op int
+		if _, err := fmt.Sscanf(entry.Name, "f%d.txt", &op); err != nil {
+			s.fail("/w%d contiene una entrada inesperada %q", worker, entry.Name)
+			continue
+		}
+		if entry.Size != stressFileSize(worker, op) {
+			s.fail("/w%d/%s tiene %d bytes, se esperaban %d", worker, entry.Name, entry.Size, stressFileSize(worker, op))
+		}
+	}
+	return len(entries)
+}
+
+// stressFileSize es el tamaño del archivo que crea la operación op del worker;
+// llega a varios bloques para que la escritura no sea atómica
+func stressFileSize(worker int, op int) int {
+	return 20 + worker*37 + op*11
+}
+
+// execute envía un comando a /execute y registra un fallo si retorna error
+func (s *stressRun) execute(command string) {
+	body, _ := json.Marshal(CommandRequest{Command: command})
+	resp, err := s.client.Post(s.baseURL+"/execute", "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		s.fail("%s: %v", command, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	var result CommandResponse
+	json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode != http.StatusOK || result.Error != "" {
+		s.fail("%s: %d %s", command, resp.StatusCode, result.Error)
+	}
+}
+
+// get hace una petición GET y registra un fallo si no responde 200
+func (s *stressRun) get(path string) {
+	s.getJSON(path, nil)
+}
+
+// getJSON hace una petición GET y decodifica la respuesta en target si no es nil
+func (s *stressRun) getJSON(path string, target interface{}) bool {
+	resp, err := s.client.Get(s.baseURL + path)
+	if err != nil {
+		s.fail("GET %s: %v", path, err)
+		return false
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		s.fail("GET %s: %d %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+		return false
+	}
+	if target != nil {
+		if err := json.Unmarshal(body, target); err != nil {
+			s.fail("GET %s: respuesta inválida: %v", path, err)
+			return false
+		}
+	}
+	return true
+}
+
+func (s *stressRun) fail(format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, fmt.Sprintf(format, args...))
+}
+
+// stressParam lee un parámetro entero positivo con valor por defecto
+func stressParam(params map[string]string, name string, defaultValue int) (int, error) {
+	value, ok := params[name]
+	if !ok {
+		return defaultValue, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		return 0, fmt.Errorf("ERROR: -%s debe ser un entero mayor que 0", name)
+	}
+	return number, nil
+}
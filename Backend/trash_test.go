@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// trashUsers crea el grupo y los usuarios de las pruebas de papelera y la carpeta
+// /pub donde todos pueden escribir. La sesión queda con root.
+func trashUsers(t *testing.T) {
+	t.Helper()
+	runCommands(t,
+		"mkgrp -name=equipo",
+		"mkusr -user=ana -pass=123 -grp=equipo",
+		"mkusr -user=luis -pass=123 -grp=equipo",
+		"mkdir -path=/pub",
+		"chmod -path=/pub -ugo=777",
+	)
+}
+
+func TestTrashRemoveAndRestore(t *testing.T) {
+	diskPath := "mem://pruebas/papelera.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "logout", "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	runCommands(t, "mkdir -path=/docs", "mkfile -path=/docs/nota.txt -size=6", "remove -path=/docs/nota.txt")
+	if err := commandError("cat -file1=/docs/nota.txt"); err == nil {
+		t.Fatal("el archivo sigue en su carpeta después de remove")
+	}
+	if out := runCommands(t, "trash -list"); !strings.Contains(out, "/docs/nota.txt") {
+		t.Fatalf("trash -list no muestra el archivo:\n%s", out)
+	}
+
+	runCommands(t, "restore -path=/docs/nota.txt")
+	if got := runCommands(t, "cat -file1=/docs/nota.txt"); got != "012345" {
+		t.Errorf("cat después de restore = %q", got)
+	}
+	if out := runCommands(t, "trash -list"); !strings.Contains(out, "vacía") {
+		t.Errorf("la papelera no quedó vacía después de restore:\n%s", out)
+	}
+	if err := commandError("restore -path=/docs/nota.txt"); err == nil {
+		t.Error("restore de un elemento que ya no está en la papelera no retornó error")
+	}
+}
+
+func TestTrashDirectoryIsNotWritableByUsers(t *testing.T) {
+	diskPath := "mem://pruebas/papelera-permisos.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	trashUsers(t)
+	runCommands(t, "logout", "login -user=ana -pass=123 -id="+id)
+	defer runCommands(t, "logout")
+
+	runCommands(t, "mkfile -path=/pub/a.txt -size=3", "remove -path=/pub/a.txt")
+	if err := commandError("mkdir -path=/.trash/99"); err == nil {
+		t.Error("un usuario pudo crear una carpeta en /.trash")
+	}
+	if err := commandError("rename -path=/.trash/1 -name=50"); err == nil {
+		t.Error("un usuario pudo renombrar un elemento de /.trash")
+	}
+	runCommands(t, "restore -path=/pub/a.txt")
+}
+
+// Con la partición sin inodos, remove descarta el elemento más antiguo del propio
+// usuario y nunca los de otros
+func TestTrashEvictsOnlyOwnEntriesWhenFull(t *testing.T) {
+	diskPath := "mem://pruebas/papelera-llena.mia"
+	id := formatDiskSize(t, diskPath, 40, "2fs")
+	defer runCommands(t, "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	trashUsers(t)
+	runCommands(t, "mkfile -path=/pub/root.txt -size=3", "remove -path=/pub/root.txt", "logout")
+
+	runCommands(t, "login -user=luis -pass=123 -id="+id,
+		"mkfile -path=/pub/luis.txt -size=3", "remove -path=/pub/luis.txt", "logout")
+
+	runCommands(t, "login -user=ana -pass=123 -id="+id)
+	for i := 0; ; i++ {
+		if i == 500 {
+			t.Fatal("la partición no se llenó")
+		}
+		if err := commandError(fmt.Sprintf("mkfile -path=/pub/f%d -size=0", i)); err != nil {
+			break
+		}
+	}
+
+	// Ana no tiene elementos propios: no se descarta nada
+	err := commandError("remove -path=/pub/f0")
+	if err == nil || !strings.Contains(err.Error(), "remove -force") {
+		t.Fatalf("remove con la papelera llena de otros usuarios = %v", err)
+	}
+	runCommands(t, "remove -path=/pub/f1 -force", "remove -path=/pub/f2 -force")
+
+	// Con un elemento propio en la papelera se descarta ese y no los de root o luis
+	runCommands(t, "remove -path=/pub/f3")
+	for i := 4; i < 8; i++ {
+		runCommands(t, fmt.Sprintf("remove -path=/pub/f%d", i))
+	}
+	runCommands(t, "logout", "login -user=root -pass=123 -id="+id)
+	defer runCommands(t, "logout")
+
+	out := runCommands(t, "trash -list")
+	for _, path := range []string{"/pub/root.txt", "/pub/luis.txt", "/pub/f7"} {
+		if !strings.Contains(out, path) {
+			t.Errorf("%s ya no está en la papelera:\n%s", path, out)
+		}
+	}
+	if strings.Contains(out, "/pub/f3\n") {
+		t.Errorf("no se descartó el elemento más antiguo de ana:\n%s", out)
+	}
+}
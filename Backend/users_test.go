@@ -0,0 +1,105 @@
+package main
+
+import (
+	"MIA_2S2025_P1_202105668/Logica/Disk"
+	"MIA_2S2025_P1_202105668/Logica/System"
+	"strings"
+	"testing"
+)
+
+// mountedFileManager retorna el manejador de archivos de la partición montada con id
+func mountedFileManager(t *testing.T, id string) *System.EXT2FileManager {
+	t.Helper()
+	mountInfo, err := Disk.GetMountInfoByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return System.NewEXT2FileManager(System.NewEXT2Manager(&System.MountInfo{
+		DiskPath:      mountInfo.DiskPath,
+		PartitionName: mountInfo.PartitionName,
+		MountID:       mountInfo.MountID,
+		DiskLetter:    mountInfo.DiskLetter,
+		PartNumber:    mountInfo.PartNumber,
+	}))
+}
+
+func TestInitialRootPasswordIsHashed(t *testing.T) {
+	for _, fs := range []string{"2fs", "3fs"} {
+		diskPath := "mem://pruebas/usuarios-" + fs + ".mia"
+		id := formatDiskSize(t, diskPath, 200, fs)
+
+		users := runCommands(t, "cat -file1=/users.txt")
+		if strings.Contains(users, "root, root, 123") || !strings.Contains(users, "root, root, pbkdf2$") {
+			t.Errorf("%s: users.txt inicial sin la contraseña cifrada:\n%s", fs, users)
+		}
+		if out := runCommands(t, "recovery -id="+id+" -sb"); !strings.Contains(out, "es válido, no se modificó") {
+			t.Errorf("%s: el superbloque no coincide con los bitmaps después de mkfs:\n%s", fs, out)
+		}
+		runCommands(t, "logout", "unmount -id="+id, "rmdisk -path="+diskPath)
+	}
+}
+
+func TestPasswdChangesPassword(t *testing.T) {
+	diskPath := "mem://pruebas/passwd.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "unmount -id="+id, "rmdisk -path="+diskPath)
+
+	runCommands(t,
+		"mkgrp -name=equipo",
+		"mkusr -user=ana -pass=123 -grp=equipo",
+		"mkusr -user=luis -pass=123 -grp=equipo",
+		"passwd -user=ana -new=nueva",
+		"logout",
+	)
+
+	if err := commandError("login -user=ana -pass=123 -id=" + id); err == nil {
+		t.Fatal("login aceptó la contraseña anterior")
+	}
+	runCommands(t, "login -user=ana -pass=nueva -id="+id)
+	if err := commandError("passwd -user=luis -new=otra"); err == nil {
+		t.Error("un usuario cambió la contraseña de otro")
+	}
+	if err := commandError("passwd -new=contraseña_larga"); err == nil {
+		t.Error("passwd aceptó una contraseña de más de 10 caracteres")
+	}
+	if err := commandError("passwd -old=123 -new=propia"); err == nil {
+		t.Error("passwd aceptó una contraseña actual incorrecta")
+	}
+	runCommands(t, "passwd -old=nueva -new=propia", "logout")
+
+	runCommands(t, "login -user=root -pass=123 -id="+id)
+	defer runCommands(t, "logout")
+	users := runCommands(t, "cat -file1=/users.txt")
+	for _, plain := range []string{"nueva", "propia"} {
+		if strings.Contains(users, plain) {
+			t.Errorf("users.txt guarda %q en texto plano:\n%s", plain, users)
+		}
+	}
+	runCommands(t, "logout", "login -user=ana -pass=propia -id="+id)
+}
+
+func TestPlainPasswordIsMigratedOnWrite(t *testing.T) {
+	diskPath := "mem://pruebas/migracion.mia"
+	id := formatDisk(t, diskPath, "2fs")
+	defer runCommands(t, "unmount -id="+id, "rmdisk -path="+diskPath)
+	runCommands(t, "logout")
+
+	// users.txt de una partición formateada antes de cifrar las contraseñas
+	legacy := "1, G, root\n1, U, root, root, 123\n2, G, equipo\n2, U, equipo, ana, abc\n"
+	if err := mountedFileManager(t, id).OverwriteFileContent(1, legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	runCommands(t, "login -user=ana -pass=abc -id="+id, "logout")
+	if err := commandError("login -user=ana -pass=123 -id=" + id); err == nil {
+		t.Fatal("login aceptó una contraseña incorrecta en texto plano")
+	}
+
+	runCommands(t, "login -user=root -pass=123 -id="+id, "mkgrp -name=otro")
+	defer runCommands(t, "logout")
+	users := runCommands(t, "cat -file1=/users.txt")
+	if strings.Contains(users, "root, 123") || strings.Contains(users, "ana, abc") {
+		t.Errorf("la escritura de users.txt no migró las contraseñas en texto plano:\n%s", users)
+	}
+	runCommands(t, "logout", "login -user=ana -pass=abc -id="+id)
+}
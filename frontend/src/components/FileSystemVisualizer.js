@@ -13,9 +13,13 @@ const FileSystemVisualizer = ({ isLoggedIn, sessionInfo }) => {
   const [fileContentModal, setFileContentModal] = useState({
     isOpen: false,
     fileName: '',
+    filePath: '',
     content: '',
     size: 0
   });
+  const [isEditing, setIsEditing] = useState(false);
+  const [draftContent, setDraftContent] = useState('');
+  const [saving, setSaving] = useState(false);
 
   // Emoji para identificar los discos
   const diskEmoji = '💿';
@@ -26,6 +30,34 @@ const FileSystemVisualizer = ({ isLoggedIn, sessionInfo }) => {
     }
   }, [isLoggedIn]);
 
+  // Actualizar en vivo con los eventos del servidor en lugar de hacer polling
+  useEffect(() => {
+    if (!isLoggedIn) return;
+
+    const source = new EventSource(`${API_URL}/events?types=fs,mount,unmount`);
+
+    const handleMountChange = () => loadDisks();
+    const handleFsChange = (e) => {
+      const event = JSON.parse(e.data);
+      if (!selectedPartition || event.mountId !== selectedPartition.id) return;
+
+      // Recargar solo si el cambio afecta a la carpeta que se está viendo
+      const affected = [event.path, event.target].filter(Boolean).some((path) => {
+        const parent = path.substring(0, path.lastIndexOf('/')) || '/';
+        return parent === currentPath;
+      });
+      if (affected) {
+        loadFileSystemContent(selectedPartition.id, currentPath);
+      }
+    };
+
+    source.addEventListener('mount', handleMountChange);
+    source.addEventListener('unmount', handleMountChange);
+    source.addEventListener('fs', handleFsChange);
+
+    return () => source.close();
+  }, [isLoggedIn, selectedPartition, currentPath]);
+
   const loadDisks = async () => {
     try {
       setLoading(true);
@@ -122,6 +154,7 @@ const FileSystemVisualizer = ({ isLoggedIn, sessionInfo }) => {
       setFileContentModal({
         isOpen: true,
         fileName: fileName,
+        filePath: filePath,
         content: data.content,
         size: fileSize
       });
@@ -135,9 +168,49 @@ const FileSystemVisualizer = ({ isLoggedIn, sessionInfo }) => {
     setFileContentModal({
       isOpen: false,
       fileName: '',
+      filePath: '',
       content: '',
       size: 0
     });
+    setIsEditing(false);
+  };
+
+  const startEditing = () => {
+    setDraftContent(fileContentModal.content);
+    setIsEditing(true);
+  };
+
+  // Guarda el contenido editado con PUT /file-content (usa los permisos de la sesión activa)
+  const saveFileContent = async () => {
+    setSaving(true);
+    try {
+      const response = await fetch(`${API_URL}/file-content?partition_id=${selectedPartition.id}&path=${encodeURIComponent(fileContentModal.filePath)}`, {
+        method: 'PUT',
+        headers: {
+          'Content-Type': 'text/plain',
+        },
+        body: draftContent
+      });
+
+      const data = await response.json();
+      if (!response.ok) {
+        alert(`Error al guardar archivo: ${data.error}`);
+        return;
+      }
+
+      setFileContentModal({
+        ...fileContentModal,
+        content: draftContent,
+        size: data.size
+      });
+      setIsEditing(false);
+      loadFileSystemContent(selectedPartition.id, currentPath);
+    } catch (err) {
+      console.error('Error al guardar contenido del archivo:', err);
+      alert(`Error al guardar el archivo: ${err.message}`);
+    } finally {
+      setSaving(false);
+    }
   };
 
   const goToParentFolder = () => {
@@ -380,10 +453,30 @@ const FileSystemVisualizer = ({ isLoggedIn, sessionInfo }) => {
               <button className="file-modal-close" onClick={closeFileModal}>×</button>
             </div>
             <div className="file-modal-body">
-              <pre className="file-content-display">{fileContentModal.content}</pre>
+              {isEditing ? (
+                <textarea
+                  className="file-content-display file-content-editor"
+                  value={draftContent}
+                  onChange={(e) => setDraftContent(e.target.value)}
+                />
+              ) : (
+                <pre className="file-content-display">{fileContentModal.content}</pre>
+              )}
             </div>
             <div className="file-modal-footer">
-              <button className="file-modal-btn-close" onClick={closeFileModal}>Cerrar</button>
+              {isEditing ? (
+                <>
+                  <button className="file-modal-btn-secondary" onClick={() => setIsEditing(false)} disabled={saving}>Cancelar</button>
+                  <button className="file-modal-btn-close" onClick={saveFileContent} disabled={saving}>
+                    {saving ? 'Guardando...' : 'Guardar'}
+                  </button>
+                </>
+              ) : (
+                <>
+                  <button className="file-modal-btn-secondary" onClick={startEditing}>Editar</button>
+                  <button className="file-modal-btn-close" onClick={closeFileModal}>Cerrar</button>
+                </>
+              )}
             </div>
           </div>
         </div>